@@ -0,0 +1,941 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// configFile은 -config로 지정하는 설정 파일의 내용입니다. 형식은 확장자(.yaml, .yml, .toml, .json)로 구분합니다.
+// 파일에 없는 항목은 플래그 값(또는 플래그 기본값)을 따르고, 명령줄에서 명시한 플래그는 파일보다 우선합니다.
+//
+//	devices:
+//	  - path: 'C:\'
+//	  - path: 'D:\Downloads'
+//	    filters: [.exe, .dll, .zip]
+//	  - path: '\\nas\share'
+//	    backend: poll
+//	polling:
+//	  interval: 30s
+//	  dirs_per_second: 200
+//	filters: [.exe, .dll]
+//	path_rules: ["-**/Temp/*.log", "+**/Temp/**"]
+//	exclude_dirs: [default, build]
+//	ops: [CREATE, REMOVE, RENAME]
+//	db: monitor.db
+//	interval: 10s
+//	rules: rules.yaml
+//	signatures: signatures.yaml
+//	quarantine:
+//	  dir: 'C:\ProgramData\iomonitor\quarantine'
+//	burst:
+//	  window: 10s
+//	  directory: 300
+//	sinks:
+//	  - type: jsonl
+//	    path: events.jsonl
+//	logging:
+//	  file: iomonitor.log
+type configFile struct {
+	Devices     []deviceConfig `yaml:"devices" toml:"devices"`
+	Filters     *[]string      `yaml:"filters" toml:"filters"`
+	Types       *[]string      `yaml:"types" toml:"types"`
+	PathRules   []string       `yaml:"path_rules" toml:"path_rules"` // "+패턴"은 포함, "-패턴"은 제외
+	ExcludeDirs *[]string      `yaml:"exclude_dirs" toml:"exclude_dirs"`
+	Ops         *[]string      `yaml:"ops" toml:"ops"`
+	DB          string         `yaml:"db" toml:"db"`
+	Interval    string         `yaml:"interval" toml:"interval"`
+	Hash        *[]string      `yaml:"hash" toml:"hash"`
+	HashMaxSize int64          `yaml:"hash_max_size" toml:"hash_max_size"` // MB
+	Reconcile   *bool          `yaml:"reconcile" toml:"reconcile"`
+	Sinks       []sinkConfig   `yaml:"sinks" toml:"sinks"`
+	Logging     loggingConfig  `yaml:"logging" toml:"logging"`
+	Polling     pollingConfig  `yaml:"polling" toml:"polling"`
+	Watcher     string         `yaml:"watcher" toml:"watcher"` // fsnotify(기본) 또는 fanotify
+	Burst       burstConfig    `yaml:"burst" toml:"burst"`
+	Rules       string         `yaml:"rules" toml:"rules"`           // 탐지 규칙 파일 (YAML)
+	Signatures  string         `yaml:"signatures" toml:"signatures"` // 바이트 시그니처 파일 (YAML)
+
+	// quarantine: true인 시그니처나 규칙과 일치한 파일의 격리 설정
+	Quarantine quarantineConfig `yaml:"quarantine" toml:"quarantine"`
+}
+
+// notify 장치에 사용할 변경 알림 방식입니다.
+const (
+	watcherFsnotify = "fsnotify"
+	watcherFanotify = "fanotify" // Linux 전용, 프로세스 정보와 실행(EXEC) 기록
+)
+
+// deviceConfig는 모니터링할 장치와 그 장치에만 적용할 필터입니다.
+// 필터 항목을 하나라도 지정하면 해당 장치에는 장치 필터가 사용되며, 지정하지 않은 항목은 전역 값을 따릅니다.
+type deviceConfig struct {
+	Path      string    `yaml:"path" toml:"path"`
+	Filters   *[]string `yaml:"filters" toml:"filters"`
+	Types     *[]string `yaml:"types" toml:"types"`
+	PathRules []string  `yaml:"path_rules" toml:"path_rules"`
+	Backend   string    `yaml:"backend" toml:"backend"` // notify(기본) 또는 poll
+}
+
+// pollingConfig는 backend: poll 장치의 스캔 주기와 속도 제한입니다. 0이면 기본값, 음수이면 제한 없음입니다.
+type pollingConfig struct {
+	Interval       string `yaml:"interval" toml:"interval"`
+	DirsPerSecond  int    `yaml:"dirs_per_second" toml:"dirs_per_second"`
+	FilesPerSecond int    `yaml:"files_per_second" toml:"files_per_second"`
+}
+
+// burstConfig는 대량 파일 변경 감지 기준입니다. 생략한 항목은 플래그 값을 따르고, 임계값 0은 해당 범위의 감지를 끕니다.
+type burstConfig struct {
+	Window    string `yaml:"window" toml:"window"`
+	Global    *int   `yaml:"global" toml:"global"`
+	Directory *int   `yaml:"directory" toml:"directory"`
+	Extension *int   `yaml:"extension" toml:"extension"`
+}
+
+// quarantineConfig는 일치한 파일을 옮겨 둘 격리 디렉토리 설정입니다.
+type quarantineConfig struct {
+	Dir    string `yaml:"dir" toml:"dir"`
+	DryRun *bool  `yaml:"dry_run" toml:"dry_run"` // 파일을 옮기지 않고 격리 대상만 기록
+}
+
+// sinkConfig는 이벤트를 추가로 내보낼 대상입니다.
+type sinkConfig struct {
+	Type string `yaml:"type" toml:"type"` // console 또는 jsonl
+	Path string `yaml:"path" toml:"path"` // jsonl 파일 경로
+}
+
+// loggingConfig는 로그 출력 설정입니다.
+type loggingConfig struct {
+	File  string `yaml:"file" toml:"file"`   // 지정하면 표준 오류와 함께 이 파일에도 기록
+	Debug bool   `yaml:"debug" toml:"debug"` // DEBUG_MONITOR=true와 같음
+}
+
+// configError는 설정 파일의 오류 하나입니다. Line이 0이면 위치를 알 수 없는 오류입니다.
+type configError struct {
+	Line int
+	Path string // 항목 경로 (예: devices[1].filters[0])
+	Msg  string
+}
+
+// format은 "파일:줄: 항목: 메시지" 형식의 문자열을 반환합니다.
+func (e configError) format(file string) string {
+	var b strings.Builder
+	b.WriteString(file)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, ": %s", e.Path)
+	}
+	fmt.Fprintf(&b, ": %s", e.Msg)
+	return b.String()
+}
+
+// configErrors는 설정 파일에서 발견된 모든 오류입니다.
+type configErrors struct {
+	file   string
+	errors []configError
+}
+
+func (e *configErrors) Error() string {
+	lines := make([]string, len(e.errors))
+	for i, err := range e.errors {
+		lines[i] = err.format(e.file)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadConfigFile은 설정 파일을 읽고 검증합니다.
+// 문법 오류, 알 수 없는 항목, 잘못된 값이 있으면 줄 번호가 포함된 *configErrors를 반환합니다.
+func loadConfigFile(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cfg       configFile
+		positions positions
+		errs      []configError
+		decoded   bool
+	)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml", ".json":
+		// JSON은 YAML의 부분 집합이므로 같은 파서로 읽어 줄 번호를 얻음
+		errs, positions, decoded = decodeYAML(data, &cfg)
+	case ".toml":
+		errs, positions, decoded = decodeTOML(data, &cfg)
+	default:
+		return nil, fmt.Errorf("%s: 지원하지 않는 설정 파일 형식입니다: %q (.yaml, .yml, .toml, .json)", path, ext)
+	}
+
+	// 알 수 없는 항목만 있으면 나머지 값도 검증하여 오류를 한 번에 보고
+	if decoded {
+		for _, e := range cfg.validate() {
+			e.Line = positions.line(e.Path)
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) > 0 {
+		slices.SortStableFunc(errs, func(a, b configError) int { return a.Line - b.Line })
+		return nil, &configErrors{file: path, errors: errs}
+	}
+	return &cfg, nil
+}
+
+// parserErrorPattern은 파서 오류 메시지에서 줄 번호와 본문을 추출합니다.
+var parserErrorPattern = regexp.MustCompile(`^(?:yaml: |toml: )?line (\d+)(?: \(last key "([^"]*)"\))?: (.*)$`)
+
+// unknownFieldPattern은 YAML 디코더의 알 수 없는 항목 오류 메시지입니다.
+var unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type `)
+
+// parserError는 파서 오류 메시지를 configError로 변환합니다.
+func parserError(msg string) configError {
+	msg = strings.TrimSpace(msg)
+	m := parserErrorPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return configError{Msg: msg}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return configError{Line: line, Path: m[2], Msg: m[3]}
+}
+
+// decodeYAML은 YAML(JSON) 문서를 디코딩하고 항목별 줄 번호를 수집합니다.
+// decoded는 문법 오류 없이 값을 읽었는지(알 수 없는 항목이나 타입 오류는 있을 수 있음)를 나타냅니다.
+func decodeYAML(data []byte, cfg *configFile) (errs []configError, p positions, decoded bool) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []configError{parserError(err.Error())}, nil, false
+	}
+	positions := make(positions)
+	if len(root.Content) > 0 {
+		positions.addYAML(root.Content[0], "")
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			for _, msg := range typeErr.Errors {
+				e := parserError(msg)
+				if m := unknownFieldPattern.FindStringSubmatch(e.Msg); m != nil {
+					e.Path = positions.find(e.Line, m[1])
+					e.Msg = "알 수 없는 항목입니다"
+				} else if e.Path == "" {
+					// 타입 오류 메시지에는 줄 번호만 있으므로 그 줄의 항목을 찾음
+					e.Path = positions.at(e.Line)
+				}
+				errs = append(errs, e)
+			}
+			return errs, positions, true
+		}
+		// 빈 파일은 모든 항목을 생략한 것으로 처리
+		if err != io.EOF {
+			return []configError{parserError(err.Error())}, positions, false
+		}
+	}
+	return nil, positions, true
+}
+
+// decodeTOML은 TOML 문서를 디코딩하고 항목별 줄 번호를 수집합니다.
+// TOML 디코더는 타입 오류에서 멈추므로 이 경우 decoded는 false입니다.
+func decodeTOML(data []byte, cfg *configFile) (errs []configError, p positions, decoded bool) {
+	positions := tomlPositions(data)
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		if parseErr, ok := err.(toml.ParseError); ok {
+			return []configError{{Line: parseErr.Position.Line, Msg: parseErr.Message}}, positions, false
+		}
+		return []configError{parserError(err.Error())}, positions, false
+	}
+
+	for _, key := range md.Undecoded() {
+		path := key.String()
+		errs = append(errs, configError{Line: positions.line(path), Path: path, Msg: "알 수 없는 항목입니다"})
+	}
+	return errs, positions, true
+}
+
+// positions는 설정 항목 경로(예: devices[1].filters)별 줄 번호입니다.
+type positions map[string]int
+
+// line은 항목의 줄 번호를 반환합니다. 항목 자체의 위치를 모르면 가장 가까운 상위 항목의 위치를 사용합니다.
+func (p positions) line(path string) int {
+	for path != "" {
+		if line, ok := p[path]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return 0
+}
+
+// find는 지정한 줄에 있는 name 항목의 전체 경로를 찾습니다. 찾지 못하면 name을 반환합니다.
+func (p positions) find(line int, name string) string {
+	for path, l := range p {
+		if l == line && (path == name || strings.HasSuffix(path, "."+name)) {
+			return path
+		}
+	}
+	return name
+}
+
+// at은 지정한 줄에 있는 항목 중 가장 깊은 항목의 경로를 반환합니다.
+// 같은 깊이의 항목이 여러 개이면(예: filters: [a, b]) 그 항목들의 상위 항목을 반환합니다.
+func (p positions) at(line int) string {
+	var paths []string
+	for path, l := range p {
+		if l == line {
+			paths = append(paths, path)
+		}
+	}
+	// 다른 항목의 상위 항목인 경로는 제외
+	var leaves []string
+	for _, path := range paths {
+		parent := false
+		for _, other := range paths {
+			if other != path && (strings.HasPrefix(other, path+".") || strings.HasPrefix(other, path+"[")) {
+				parent = true
+				break
+			}
+		}
+		if !parent {
+			leaves = append(leaves, path)
+		}
+	}
+	switch len(leaves) {
+	case 0:
+		return ""
+	case 1:
+		return leaves[0]
+	}
+	path := leaves[0]
+	i := strings.LastIndexAny(path, ".[")
+	if i < 0 {
+		return ""
+	}
+	return path[:i]
+}
+
+// addYAML은 YAML 노드 트리를 따라가며 항목별 줄 번호를 기록합니다.
+func (p positions) addYAML(node *yaml.Node, prefix string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := joinPath(prefix, node.Content[i].Value)
+			p[key] = node.Content[i].Line
+			p.addYAML(node.Content[i+1], key)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			p[key] = item.Line
+			p.addYAML(item, key)
+		}
+	}
+}
+
+// tomlPositions는 TOML 문서의 테이블 헤더와 키가 있는 줄을 기록합니다.
+// 배열 테이블([[devices]])의 항목은 "devices[0].path"와 인덱스 없는 "devices.path"(첫 항목) 두 경로로 기록됩니다.
+func tomlPositions(data []byte) positions {
+	p := make(positions)
+	set := func(path string, line int) {
+		if _, ok := p[path]; !ok {
+			p[path] = line
+		}
+	}
+
+	counts := make(map[string]int)
+	var table, alias string
+	for i, text := range strings.Split(string(data), "\n") {
+		line := i + 1
+		text = strings.TrimSpace(text)
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, "[["):
+			name := strings.TrimSpace(strings.Trim(text, "[]"))
+			table = fmt.Sprintf("%s[%d]", name, counts[name])
+			alias = name
+			counts[name]++
+			set(table, line)
+			set(alias, line)
+		case strings.HasPrefix(text, "["):
+			table = strings.TrimSpace(strings.Trim(text, "[]"))
+			alias = table
+			set(table, line)
+		default:
+			key, _, ok := strings.Cut(text, "=")
+			if !ok {
+				continue
+			}
+			key = strings.Trim(strings.TrimSpace(key), `"'`)
+			set(joinPath(table, key), line)
+			set(joinPath(alias, key), line)
+		}
+	}
+	return p
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// validate는 값의 의미를 검증합니다. 반환된 오류에는 줄 번호 대신 항목 경로가 채워집니다.
+func (c *configFile) validate() []configError {
+	var errs []configError
+	add := func(path, format string, args ...any) {
+		errs = append(errs, configError{Path: path, Msg: fmt.Sprintf(format, args...)})
+	}
+	checkRules := func(prefix string, rules []string) {
+		for i, spec := range rules {
+			if _, err := parsePathRules([]string{spec}); err != nil {
+				add(fmt.Sprintf("%s[%d]", prefix, i), "%v", err)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, device := range c.Devices {
+		prefix := fmt.Sprintf("devices[%d]", i)
+		path := strings.TrimSpace(device.Path)
+		if path == "" {
+			add(prefix, "장치 경로(path)가 비어 있습니다")
+			continue
+		}
+		if seen[filepath.Clean(path)] {
+			add(prefix+".path", "중복된 장치입니다: %s", path)
+		}
+		seen[filepath.Clean(path)] = true
+		checkRules(prefix+".path_rules", device.PathRules)
+		switch device.Backend {
+		case "", monitor.BackendNotify, monitor.BackendPolling:
+		default:
+			add(prefix+".backend", "알 수 없는 감시 방식입니다: %q (%s, %s)", device.Backend, monitor.BackendNotify, monitor.BackendPolling)
+		}
+	}
+	switch c.Watcher {
+	case "", watcherFsnotify, watcherFanotify:
+	default:
+		add("watcher", "알 수 없는 변경 알림 방식입니다: %q (%s, %s)", c.Watcher, watcherFsnotify, watcherFanotify)
+	}
+	if c.Polling.Interval != "" {
+		if d, err := time.ParseDuration(c.Polling.Interval); err != nil || d <= 0 {
+			add("polling.interval", "잘못된 스캔 주기입니다: %q (예: 10s, 1m)", c.Polling.Interval)
+		}
+	}
+
+	checkRules("path_rules", c.PathRules)
+	if c.ExcludeDirs != nil {
+		for i, pattern := range *c.ExcludeDirs {
+			if err := (monitor.FilterConfig{ExcludeDirs: expandExcludeDirs([]string{pattern})}).Validate(); err != nil {
+				add(fmt.Sprintf("exclude_dirs[%d]", i), "%v", err)
+			}
+		}
+	}
+	if c.Ops != nil {
+		for i, op := range *c.Ops {
+			if err := (monitor.FilterConfig{Operations: []string{op}}).Validate(); err != nil {
+				add(fmt.Sprintf("ops[%d]", i), "%v", err)
+			}
+		}
+	}
+
+	if c.Burst.Window != "" {
+		if d, err := time.ParseDuration(c.Burst.Window); err != nil || d <= 0 {
+			add("burst.window", "잘못된 감지 윈도입니다: %q (예: 10s, 1m)", c.Burst.Window)
+		}
+	}
+	checkThreshold := func(name string, threshold *int) {
+		if threshold != nil && *threshold < 0 {
+			add("burst."+name, "0 이상이어야 합니다: %d", *threshold)
+		}
+	}
+	checkThreshold("global", c.Burst.Global)
+	checkThreshold("directory", c.Burst.Directory)
+	checkThreshold("extension", c.Burst.Extension)
+
+	if c.Quarantine.DryRun != nil && *c.Quarantine.DryRun && c.Quarantine.Dir == "" {
+		add("quarantine.dry_run", "dry_run을 사용하려면 격리 디렉토리(dir)가 필요합니다")
+	}
+
+	if c.Interval != "" {
+		if d, err := time.ParseDuration(c.Interval); err != nil || d <= 0 {
+			add("interval", "잘못된 저장 간격입니다: %q (예: 5s, 1m)", c.Interval)
+		}
+	}
+	if c.Hash != nil {
+		for i, name := range *c.Hash {
+			if err := validateHashAlgorithm(name); err != nil {
+				add(fmt.Sprintf("hash[%d]", i), "%v", err)
+			}
+		}
+	}
+	if c.HashMaxSize < 0 {
+		add("hash_max_size", "0 이상이어야 합니다: %d", c.HashMaxSize)
+	}
+
+	for i, sink := range c.Sinks {
+		prefix := fmt.Sprintf("sinks[%d]", i)
+		switch sink.Type {
+		case sinkConsole:
+		case sinkJSONL:
+			if sink.Path == "" {
+				add(prefix+".path", "jsonl 싱크에는 파일 경로(path)가 필요합니다")
+			}
+		case "":
+			add(prefix, "싱크 종류(type)가 비어 있습니다")
+		default:
+			add(prefix+".type", "알 수 없는 싱크 종류입니다: %q (console, jsonl)", sink.Type)
+		}
+	}
+	return errs
+}
+
+// validateHashAlgorithm은 해시 알고리즘 이름을 검증합니다.
+func validateHashAlgorithm(name string) error {
+	switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "") {
+	case monitor.HashMD5, monitor.HashSHA1, monitor.HashSHA256, "":
+		return nil
+	}
+	return fmt.Errorf("알 수 없는 해시 알고리즘: %s (가능한 값: md5, sha1, sha256)", name)
+}
+
+// cliFlags는 설정 파일과 합쳐지는 명령줄 플래그 값입니다.
+type cliFlags struct {
+	interval    *time.Duration
+	device      *string
+	filters     *string
+	types       *string
+	ops         *string
+	db          *string
+	excludeDirs *string
+	hash        *string
+	hashMaxSize *int64
+	reconcile   *bool
+	watcher     *string
+	burstWindow *time.Duration
+	burstGlobal *int
+	burstDir    *int
+	burstExt    *int
+	rules       *string
+	signatures  *string
+	quarantine  *string
+	dryRun      *bool
+	pathRule    *monitor.PathRule
+}
+
+// settings는 설정 파일과 플래그를 합친 최종 실행 설정입니다.
+type settings struct {
+	devices     []string
+	backends    map[string]string // 장치별 감시 방식 (notify가 아닌 장치만)
+	watcher     string            // notify 장치의 변경 알림 방식
+	polling     monitor.PollingOptions
+	filters     monitor.FilterConfig
+	dbPath      string
+	interval    time.Duration
+	hash        []string
+	hashMaxSize int64 // MB
+	reconcile   bool
+	burst       monitor.BurstThresholds
+	rules       string
+	signatures  string
+	quarantine  monitor.QuarantineOptions
+	sinks       []sinkConfig
+	logging     loggingConfig
+}
+
+// loadSettings는 설정 파일(있는 경우)과 플래그를 합쳐 실행 설정을 만듭니다.
+func loadSettings(configPath string, flags cliFlags) (*settings, error) {
+	file := &configFile{}
+	if configPath != "" {
+		var err error
+		if file, err = loadConfigFile(configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &settings{
+		dbPath:      *flags.db,
+		interval:    *flags.interval,
+		hashMaxSize: *flags.hashMaxSize,
+		reconcile:   *flags.reconcile,
+		watcher:     *flags.watcher,
+		rules:       *flags.rules,
+		signatures:  *flags.signatures,
+		sinks:       file.Sinks,
+		logging:     file.Logging,
+	}
+
+	// 장치 (플래그도 파일도 없으면 Windows 기본 장치)
+	if isFlagSet("device") || len(file.Devices) == 0 {
+		s.devices = splitList(*flags.device)
+	} else {
+		for _, device := range file.Devices {
+			s.devices = append(s.devices, strings.TrimSpace(device.Path))
+		}
+	}
+	s.backends = make(map[string]string)
+	for _, device := range file.Devices {
+		if device.Backend != "" && device.Backend != monitor.BackendNotify {
+			s.backends[strings.TrimSpace(device.Path)] = device.Backend
+		}
+	}
+	s.polling = monitor.PollingOptions{
+		DirsPerSecond:  file.Polling.DirsPerSecond,
+		FilesPerSecond: file.Polling.FilesPerSecond,
+	}
+	if file.Polling.Interval != "" {
+		s.polling.Interval, _ = time.ParseDuration(file.Polling.Interval)
+	}
+	if len(s.devices) == 0 {
+		s.devices = []string{"C:\\"}
+	}
+
+	filters, err := buildFilterConfig(file, flags)
+	if err != nil {
+		return nil, err
+	}
+	s.filters = filters
+
+	if !isFlagSet("watcher") && file.Watcher != "" {
+		s.watcher = file.Watcher
+	}
+	// fanotify는 파일 실행도 보고하므로 작업 종류를 지정하지 않았으면 EXEC도 기록
+	if s.watcher == watcherFanotify && !isFlagSet("ops") && file.Ops == nil {
+		s.filters.Operations = append(s.filters.Operations, monitor.OperationExec)
+	}
+
+	if !isFlagSet("db") && file.DB != "" {
+		s.dbPath = file.DB
+	}
+	if !isFlagSet("interval") && file.Interval != "" {
+		// 형식은 loadConfigFile에서 검증됨
+		s.interval, _ = time.ParseDuration(file.Interval)
+	}
+	s.hash = strings.Split(*flags.hash, ",")
+	if !isFlagSet("hash") && file.Hash != nil {
+		s.hash = *file.Hash
+	}
+	if !isFlagSet("hash-max-size") && file.HashMaxSize > 0 {
+		s.hashMaxSize = file.HashMaxSize
+	}
+	if !isFlagSet("reconcile") && file.Reconcile != nil {
+		s.reconcile = *file.Reconcile
+	}
+	if !isFlagSet("rules") && file.Rules != "" {
+		s.rules = file.Rules
+	}
+	if !isFlagSet("signatures") && file.Signatures != "" {
+		s.signatures = file.Signatures
+	}
+	s.quarantine = monitor.QuarantineOptions{Dir: *flags.quarantine, DryRun: *flags.dryRun}
+	if !isFlagSet("quarantine-dir") && file.Quarantine.Dir != "" {
+		s.quarantine.Dir = file.Quarantine.Dir
+	}
+	if !isFlagSet("quarantine-dry-run") && file.Quarantine.DryRun != nil {
+		s.quarantine.DryRun = *file.Quarantine.DryRun
+	}
+
+	// 대량 변경 감지 기준
+	s.burst = monitor.BurstThresholds{
+		Window:    *flags.burstWindow,
+		Global:    *flags.burstGlobal,
+		Directory: *flags.burstDir,
+		Extension: *flags.burstExt,
+	}
+	if !isFlagSet("burst-window") && file.Burst.Window != "" {
+		s.burst.Window, _ = time.ParseDuration(file.Burst.Window)
+	}
+	if !isFlagSet("burst-global") && file.Burst.Global != nil {
+		s.burst.Global = *file.Burst.Global
+	}
+	if !isFlagSet("burst-dir") && file.Burst.Directory != nil {
+		s.burst.Directory = *file.Burst.Directory
+	}
+	if !isFlagSet("burst-ext") && file.Burst.Extension != nil {
+		s.burst.Extension = *file.Burst.Extension
+	}
+	return s, nil
+}
+
+// buildFilterConfig는 설정 파일과 플래그를 합쳐 필터 설정을 만듭니다.
+// 장치별 필터에서 생략한 항목은 최종 전역 값으로 채워집니다.
+func buildFilterConfig(file *configFile, flags cliFlags) (monitor.FilterConfig, error) {
+	var cfg monitor.FilterConfig
+
+	// 파일 필터 (빈 값을 명시하면 모든 확장자 대상)
+	filters := splitList(*flags.filters)
+	if !isFlagSet("filters") && file.Filters != nil {
+		filters = *file.Filters
+	}
+	cfg.FileFilters = normalizeExtensions(filters)
+
+	// 콘텐츠 유형 필터
+	cfg.TypeFilters = splitList(*flags.types)
+	if !isFlagSet("types") && file.Types != nil {
+		cfg.TypeFilters = *file.Types
+	}
+
+	// 경로 포함/제외 규칙
+	cfg.PathRule = flags.pathRule
+	if flags.pathRule.Empty() && len(file.PathRules) > 0 {
+		rule, err := parsePathRules(file.PathRules)
+		if err != nil {
+			return monitor.FilterConfig{}, err
+		}
+		cfg.PathRule = rule
+	}
+	if cfg.PathRule.Empty() {
+		cfg.PathRule = nil
+	}
+
+	// 감시 제외 디렉토리
+	excludeDirs := strings.Split(*flags.excludeDirs, ",")
+	if !isFlagSet("exclude-dirs") && file.ExcludeDirs != nil {
+		excludeDirs = *file.ExcludeDirs
+	}
+	cfg.ExcludeDirs = expandExcludeDirs(excludeDirs)
+
+	// 기록할 작업 종류
+	cfg.Operations = splitList(*flags.ops)
+	if !isFlagSet("ops") && file.Ops != nil {
+		cfg.Operations = *file.Ops
+	}
+
+	// 장치별 필터
+	for _, device := range file.Devices {
+		if device.Filters == nil && device.Types == nil && len(device.PathRules) == 0 {
+			continue
+		}
+		df := monitor.DeviceFilter{
+			FileFilters: cfg.FileFilters,
+			TypeFilters: cfg.TypeFilters,
+			PathRule:    cfg.PathRule,
+		}
+		if device.Filters != nil {
+			df.FileFilters = normalizeExtensions(*device.Filters)
+		}
+		if device.Types != nil {
+			df.TypeFilters = *device.Types
+		}
+		if len(device.PathRules) > 0 {
+			rule, err := parsePathRules(device.PathRules)
+			if err != nil {
+				return monitor.FilterConfig{}, err
+			}
+			df.PathRule = rule
+		}
+		if cfg.Devices == nil {
+			cfg.Devices = make(map[string]monitor.DeviceFilter)
+		}
+		cfg.Devices[strings.TrimSpace(device.Path)] = df
+	}
+
+	return cfg, nil
+}
+
+// reloadOnSIGHUP은 SIGHUP을 받을 때마다 설정 파일을 다시 읽어 필터 설정, 장치 목록, 대량 변경 감지 기준을 교체합니다.
+// 파일에 오류가 있으면 기존 설정을 유지합니다. ctx가 취소되면 반환합니다.
+func reloadOnSIGHUP(ctx context.Context, mon *monitor.Monitor, configPath string, flags cliFlags, current *settings) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Printf("SIGHUP 수신, 설정 파일 다시 불러오기: %s", configPath)
+			next, err := loadSettings(configPath, flags)
+			if err != nil {
+				log.Printf("설정 파일 다시 불러오기 실패, 기존 설정 유지:\n%v", err)
+				continue
+			}
+			changes, err := mon.ApplyFilterConfig(next.filters)
+			if err != nil {
+				log.Printf("설정 적용 실패, 기존 설정 유지: %v", err)
+				continue
+			}
+			changes = append(changes, syncDevices(mon, next.devices, next.backends)...)
+			if next.burst != mon.GetBurstThresholds() {
+				if err := mon.SetBurstThresholds(next.burst); err != nil {
+					log.Printf("대량 변경 감지 설정 실패: %v", err)
+				} else {
+					changes = append(changes, "burst")
+				}
+			}
+			for _, name := range restartRequired(current, next) {
+				log.Printf("%s 변경은 다시 시작해야 적용됩니다", name)
+			}
+			// 다음 SIGHUP에서는 이번에 불러온 설정과 비교 (같은 변경을 다시 알리지 않음)
+			current = next
+			log.Printf("설정 다시 불러오기 완료 (변경 %d개)", len(changes))
+		}
+	}
+}
+
+// syncDevices는 모니터의 장치 목록을 devices와 같게 맞추고 변경 내용을 반환합니다.
+// 새로 추가되는 장치에는 backends의 감시 방식이 적용됩니다.
+func syncDevices(mon *monitor.Monitor, devices []string, backends map[string]string) []string {
+	var changes []string
+	wanted := make(map[string]bool)
+	for _, device := range devices {
+		wanted[filepath.Clean(device)] = true
+	}
+	current := mon.GetDevices()
+	for _, device := range current {
+		if !wanted[device] {
+			if err := mon.RemoveDevice(device); err == nil {
+				changes = append(changes, "device -"+device)
+			}
+		}
+	}
+	for device := range wanted {
+		if !slices.Contains(current, device) {
+			if err := mon.SetDeviceBackend(device, backendOf(backends, device)); err != nil {
+				log.Printf("장치 감시 방식 설정 실패: %v", err)
+				continue
+			}
+			if err := mon.AddDevice(device); err != nil {
+				log.Printf("장치 추가 실패: %v", err)
+				continue
+			}
+			changes = append(changes, "device +"+device)
+		}
+	}
+	return changes
+}
+
+// backendOf는 장치의 감시 방식을 반환합니다. 지정하지 않은 장치는 notify입니다.
+func backendOf(backends map[string]string, device string) string {
+	for path, backend := range backends {
+		if filepath.Clean(path) == filepath.Clean(device) {
+			return backend
+		}
+	}
+	return monitor.BackendNotify
+}
+
+// restartRequired는 실행 중에 바꿀 수 없는 설정 중 달라진 항목의 이름을 반환합니다.
+func restartRequired(prev, next *settings) []string {
+	var names []string
+	if prev.dbPath != next.dbPath {
+		names = append(names, "db")
+	}
+	if prev.interval != next.interval {
+		names = append(names, "interval")
+	}
+	if !slices.Equal(prev.hash, next.hash) || prev.hashMaxSize != next.hashMaxSize {
+		names = append(names, "hash")
+	}
+	if !slices.Equal(prev.sinks, next.sinks) {
+		names = append(names, "sinks")
+	}
+	if prev.watcher != next.watcher {
+		names = append(names, "watcher")
+	}
+	if prev.reconcile != next.reconcile {
+		names = append(names, "reconcile")
+	}
+	if prev.rules != next.rules {
+		names = append(names, "rules")
+	}
+	if prev.signatures != next.signatures {
+		names = append(names, "signatures")
+	}
+	if prev.quarantine != next.quarantine {
+		names = append(names, "quarantine")
+	}
+	if prev.logging != next.logging {
+		names = append(names, "logging")
+	}
+	if prev.polling != next.polling {
+		names = append(names, "polling")
+	}
+	return names
+}
+
+// runConfigCommand는 "iomonitor config <명령>" 하위 명령을 처리하고 종료 코드를 반환합니다.
+func runConfigCommand(args []string) int {
+	if len(args) != 2 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "사용법: iomonitor config validate <설정 파일>")
+		return 2
+	}
+
+	if _, err := loadConfigFile(args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%s: 설정 파일이 올바릅니다\n", args[1])
+	return 0
+}
+
+// parsePathRules는 "+패턴"(포함), "-패턴"(제외) 목록을 순서대로 PathRule로 변환합니다.
+func parsePathRules(specs []string) (*monitor.PathRule, error) {
+	rule := &monitor.PathRule{}
+	for _, spec := range specs {
+		var err error
+		switch {
+		case strings.HasPrefix(spec, "+"):
+			err = rule.Include(spec[1:])
+		case strings.HasPrefix(spec, "-"):
+			err = rule.Exclude(spec[1:])
+		default:
+			err = fmt.Errorf("경로 규칙은 + 또는 -로 시작해야 합니다: %q", spec)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
+
+// splitList는 쉼표로 구분된 값을 나눕니다. 빈 문자열이면 빈 목록을 반환합니다.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// normalizeExtensions는 확장자 앞에 "."이 없으면 붙입니다.
+func normalizeExtensions(filters []string) []string {
+	var normalized []string
+	for _, filter := range filters {
+		filter = strings.TrimSpace(filter)
+		if filter == "" {
+			continue
+		}
+		if !strings.HasPrefix(filter, ".") {
+			filter = "." + filter
+		}
+		normalized = append(normalized, filter)
+	}
+	return normalized
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// writeConfig는 임시 디렉토리에 설정 파일을 만들고 경로를 반환합니다.
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testFlags는 main과 같은 기본값의 플래그를 args로 파싱하고, isFlagSet이 이 플래그 집합을 보도록 바꿉니다.
+func testFlags(t *testing.T, args ...string) cliFlags {
+	t.Helper()
+	fs := flag.NewFlagSet("iomonitor", flag.ContinueOnError)
+	flags := cliFlags{
+		interval:    fs.Duration("interval", 5*time.Second, ""),
+		device:      fs.String("device", "", ""),
+		filters:     fs.String("filters", ".exe,.dll", ""),
+		types:       fs.String("types", "", ""),
+		ops:         fs.String("ops", "CREATE,REMOVE,RENAME", ""),
+		db:          fs.String("db", "monitor.db", ""),
+		excludeDirs: fs.String("exclude-dirs", "default", ""),
+		hash:        fs.String("hash", "sha256", ""),
+		hashMaxSize: fs.Int64("hash-max-size", 100, ""),
+		reconcile:   fs.Bool("reconcile", true, ""),
+		watcher:     fs.String("watcher", "fsnotify", ""),
+		burstWindow: fs.Duration("burst-window", 10*time.Second, ""),
+		burstGlobal: fs.Int("burst-global", 2000, ""),
+		burstDir:    fs.Int("burst-dir", 300, ""),
+		burstExt:    fs.Int("burst-ext", 500, ""),
+		rules:       fs.String("rules", "", ""),
+		signatures:  fs.String("signatures", "", ""),
+		quarantine:  fs.String("quarantine-dir", "", ""),
+		dryRun:      fs.Bool("quarantine-dry-run", false, ""),
+		pathRule:    &monitor.PathRule{},
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	prev := commandLine
+	commandLine = fs
+	t.Cleanup(func() { commandLine = prev })
+	return flags
+}
+
+// configErrorLines는 loadConfigFile 오류를 줄 단위로 나누고 파일 경로를 이름으로 바꿉니다.
+func configErrorLines(t *testing.T, path string) []string {
+	t.Helper()
+	_, err := loadConfigFile(path)
+	if err == nil {
+		t.Fatalf("Expected errors for %s", filepath.Base(path))
+	}
+	return strings.Split(strings.ReplaceAll(err.Error(), path, filepath.Base(path)), "\n")
+}
+
+func TestConfigValidateLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // 각 오류 줄의 "파일:줄: 항목:" 접두사
+	}{
+		{
+			"c.yaml",
+			"db: x.db\ncolour: red\nwatcher: bogus\ndevices:\n  - path: /data\n    backend: nfs\n",
+			[]string{"c.yaml:2: colour:", "c.yaml:3: watcher:", "c.yaml:6: devices[0].backend:"},
+		},
+		{
+			"c.json",
+			"{\n  \"db\": \"x.db\",\n  \"colour\": \"red\",\n  \"watcher\": \"bogus\",\n  \"devices\": [\n    {\"path\": \"/data\", \"backend\": \"nfs\"}\n  ]\n}\n",
+			[]string{"c.json:3: colour:", "c.json:4: watcher:", "c.json:6: devices[0].backend:"},
+		},
+		{
+			"c.toml",
+			"db = \"x.db\"\ncolour = \"red\"\nwatcher = \"bogus\"\n\n[[devices]]\npath = \"/data\"\nbackend = \"nfs\"\n",
+			[]string{"c.toml:2: colour:", "c.toml:3: watcher:", "c.toml:7: devices[0].backend:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := configErrorLines(t, writeConfig(t, tt.name, tt.content))
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Expected %d errors, got %q", len(tt.want), lines)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(lines[i], want+" ") {
+					t.Errorf("Expected error %d to start with %q, got %q", i, want, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigTypeErrorPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"t.yaml", "db: x.db\nhash_max_size: abc\n", []string{"t.yaml:2: hash_max_size:"}},
+		{"t.json", "{\n  \"db\": \"x.db\",\n  \"hash_max_size\": \"abc\"\n}\n", []string{"t.json:3: hash_max_size:"}},
+		{"t.toml", "db = \"x.db\"\nhash_max_size = \"abc\"\n", []string{"t.toml:2: hash_max_size:"}},
+		{"nested.yaml", "burst:\n  window: 5s\n  global: many\n", []string{"nested.yaml:3: burst.global:"}},
+		{"item.yaml", "devices:\n  - path: /data\n    filters: .exe\n", []string{"item.yaml:3: devices[0].filters:"}},
+		// 같은 줄의 여러 항목 중 어느 것인지 알 수 없으면 상위 항목을 보고
+		{"flow.yaml", "burst: {window: 5s, global: many}\n", []string{"flow.yaml:1: burst:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := configErrorLines(t, writeConfig(t, tt.name, tt.content))
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Expected %d errors, got %q", len(tt.want), lines)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(lines[i], want+" ") {
+					t.Errorf("Expected error %d to start with %q, got %q", i, want, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigValidateCommand(t *testing.T) {
+	valid := writeConfig(t, "ok.yaml", "devices:\n  - path: /data\nops: [CREATE, WRITE]\n")
+	if code := runConfigCommand([]string{"validate", valid}); code != 0 {
+		t.Errorf("Expected exit code 0 for a valid file, got %d", code)
+	}
+	invalid := writeConfig(t, "bad.yaml", "ops: [OPEN]\n")
+	if code := runConfigCommand([]string{"validate", invalid}); code != 1 {
+		t.Errorf("Expected exit code 1 for an invalid file, got %d", code)
+	}
+	if code := runConfigCommand([]string{"check", valid}); code != 2 {
+		t.Errorf("Expected exit code 2 for an unknown command, got %d", code)
+	}
+}
+
+func TestLoadSettingsFlagPrecedence(t *testing.T) {
+	path := writeConfig(t, "iomonitor.yaml", `
+devices:
+  - path: /data
+db: file.db
+interval: 30s
+hash: [md5]
+filters: [.zip]
+ops: [CREATE]
+burst:
+  global: 10
+`)
+
+	// 명령줄에서 명시한 플래그는 파일보다 우선하고, 명시하지 않은 플래그는 파일 값을 따름
+	flags := testFlags(t, "-db", "flag.db", "-filters", ".exe", "-burst-global", "0")
+	s, err := loadSettings(path, flags)
+	if err != nil {
+		t.Fatalf("loadSettings failed: %v", err)
+	}
+	if s.dbPath != "flag.db" {
+		t.Errorf("Expected -db to override the file, got %q", s.dbPath)
+	}
+	if !reflect.DeepEqual(s.filters.FileFilters, []string{".exe"}) {
+		t.Errorf("Expected -filters to override the file, got %v", s.filters.FileFilters)
+	}
+	if s.burst.Global != 0 {
+		t.Errorf("Expected -burst-global 0 to override the file, got %d", s.burst.Global)
+	}
+	if s.interval != 30*time.Second {
+		t.Errorf("Expected interval from the file, got %v", s.interval)
+	}
+	if !reflect.DeepEqual(s.hash, []string{"md5"}) {
+		t.Errorf("Expected hash from the file, got %v", s.hash)
+	}
+	if !reflect.DeepEqual(s.filters.Operations, []string{"CREATE"}) {
+		t.Errorf("Expected ops from the file, got %v", s.filters.Operations)
+	}
+	if !reflect.DeepEqual(s.devices, []string{"/data"}) {
+		t.Errorf("Expected devices from the file, got %v", s.devices)
+	}
+	// 파일에도 없는 항목은 플래그 기본값
+	if s.hashMaxSize != 100 || s.burst.Directory != 300 {
+		t.Errorf("Expected flag defaults, got hash_max_size %d, burst.directory %d", s.hashMaxSize, s.burst.Directory)
+	}
+
+	// -device는 파일의 장치 목록을 대체
+	flags = testFlags(t, "-device", "/a,/b")
+	if s, err = loadSettings(path, flags); err != nil {
+		t.Fatalf("loadSettings failed: %v", err)
+	}
+	if !reflect.DeepEqual(s.devices, []string{"/a", "/b"}) {
+		t.Errorf("Expected -device to override the file, got %v", s.devices)
+	}
+	if s.dbPath != "file.db" {
+		t.Errorf("Expected db from the file when -db is not set, got %q", s.dbPath)
+	}
+}
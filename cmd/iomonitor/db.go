@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// runDBCommand는 "iomonitor db <명령>" 하위 명령을 처리하고 종료 코드를 반환합니다.
+func runDBCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "사용법: iomonitor db migrate [-db 경로] [--dry-run]")
+		return 2
+	}
+
+	switch args[0] {
+	case "migrate":
+		return runDBMigrate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "알 수 없는 db 명령: %s\n", args[0])
+		return 2
+	}
+}
+
+// runDBMigrate는 데이터베이스 스키마를 최신 버전으로 올리거나, dry-run 모드에서는
+// 적용 대기 중인 마이그레이션 목록만 출력합니다.
+func runDBMigrate(args []string) int {
+	fs := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	dbPath := fs.String("db", "monitor.db", "데이터베이스 파일 경로")
+	dryRun := fs.Bool("dry-run", false, "변경 없이 적용 대기 중인 마이그레이션만 출력")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	current, pending, err := monitor.PendingMigrations(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "마이그레이션 확인 실패: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("데이터베이스: %s\n", *dbPath)
+	fmt.Printf("현재 스키마 버전: %d\n", current)
+	if len(pending) == 0 {
+		fmt.Println("적용할 마이그레이션이 없습니다.")
+		return 0
+	}
+
+	fmt.Printf("적용 대기 중인 마이그레이션 (%d개):\n", len(pending))
+	for _, m := range pending {
+		fmt.Printf("  %04d_%s\n", m.Version, m.Name)
+	}
+
+	if *dryRun {
+		fmt.Println("dry-run 모드: 변경 사항이 적용되지 않았습니다.")
+		return 0
+	}
+
+	db, err := monitor.NewDatabase(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "마이그레이션 실패: %v\n", err)
+		return 1
+	}
+	db.Close()
+
+	fmt.Printf("스키마 버전 %d로 마이그레이션 완료\n", pending[len(pending)-1].Version)
+	return 0
+}
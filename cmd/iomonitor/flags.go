@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// pathRuleFlag는 -include/-exclude 플래그를 하나의 PathRule에 명령줄 순서대로 추가합니다.
+// 두 플래그가 같은 규칙을 공유하므로 "-exclude A -include B"처럼 지정한 순서가 평가 순서가 됩니다.
+type pathRuleFlag struct {
+	rule    *monitor.PathRule
+	include bool
+}
+
+func (f *pathRuleFlag) String() string {
+	if f == nil || f.rule == nil {
+		return ""
+	}
+	return f.rule.String()
+}
+
+func (f *pathRuleFlag) Set(pattern string) error {
+	if f.include {
+		return f.rule.Include(pattern)
+	}
+	return f.rule.Exclude(pattern)
+}
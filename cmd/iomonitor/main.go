@@ -1,31 +1,66 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+	"github.com/yhj0901/windowsIOMonitoring/pkg/signature"
 )
 
 func main() {
-	// 디버그 모드 확인
-	debugMode := os.Getenv("DEBUG_MONITOR") == "true"
-	if debugMode {
-		log.Println("디버그 모드 활성화됨")
+	// 하위 명령 처리
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "db":
+			os.Exit(runDBCommand(os.Args[2:]))
+		case "config":
+			os.Exit(runConfigCommand(os.Args[2:]))
+		case "rules":
+			os.Exit(runRulesCommand(os.Args[2:]))
+		case "scan":
+			os.Exit(runScanCommand(os.Args[2:]))
+		case "quarantine":
+			os.Exit(runQuarantineCommand(os.Args[2:]))
+		}
 	}
 
 	// 명령줄 인자 파싱
 	intervalFlag := flag.Duration("interval", 5*time.Second, "모니터링 간격 (예: 5s, 1m)")
 	deviceFlag := flag.String("device", "", "모니터링할 장치 (쉼표로 구분)")
 	filtersFlag := flag.String("filters", ".exe,.dll", "모니터링할 파일 확장자 (쉼표로 구분)")
+	typesFlag := flag.String("types", "", "모니터링할 콘텐츠 유형 (매직 바이트 기준, 예: pe,elf,script)")
+	opsFlag := flag.String("ops", "CREATE,REMOVE,RENAME", "기록할 작업 종류 (CREATE,REMOVE,RENAME,WRITE,CHMOD,EXEC 중 쉼표로 구분, EXEC는 fanotify 전용)")
 	dbPathFlag := flag.String("db", "monitor.db", "데이터베이스 파일 경로")
+	pathRule := &monitor.PathRule{}
+	flag.Var(&pathRuleFlag{rule: pathRule, include: true}, "include", "포함할 경로 패턴 (글롭 또는 re:정규식, 반복 지정 가능)")
+	flag.Var(&pathRuleFlag{rule: pathRule, include: false}, "exclude", "제외할 경로 패턴 (글롭 또는 re:정규식, 반복 지정 가능)")
+	configFlag := flag.String("config", "", "설정 파일 경로 (YAML, TOML, JSON, SIGHUP을 받으면 필터와 장치를 다시 불러옴)")
+	excludeDirsFlag := flag.String("exclude-dirs", "default", "감시하지 않을 디렉토리 패턴 (쉼표로 구분, default는 플랫폼 기본 목록, 빈 값은 제외 없음)")
+	hashFlag := flag.String("hash", "sha256", "새 파일과 수정된 파일에 대해 계산할 해시 (md5,sha1,sha256 중 쉼표로 구분, 빈 값은 계산 안 함)")
+	hashMaxSizeFlag := flag.Int64("hash-max-size", 100, "해시를 계산할 최대 파일 크기 (MB)")
+	watcherFlag := flag.String("watcher", "fsnotify", "변경 알림 방식 (fsnotify, fanotify: Linux 전용, 프로세스 정보와 파일 실행 기록, root 권한 필요)")
+	burstWindowFlag := flag.Duration("burst-window", 10*time.Second, "대량 변경 감지 윈도 (이 시간 동안의 변경 수를 셈)")
+	burstGlobalFlag := flag.Int("burst-global", 2000, "윈도 안의 전체 변경 수가 이 값 이상이면 경고 (0은 사용 안 함)")
+	burstDirFlag := flag.Int("burst-dir", 300, "윈도 안의 디렉토리별 변경 수가 이 값 이상이면 경고 (0은 사용 안 함)")
+	burstExtFlag := flag.Int("burst-ext", 500, "윈도 안의 확장자별 변경 수가 이 값 이상이면 경고 (0은 사용 안 함)")
+	rulesFlag := flag.String("rules", "", "탐지 규칙 파일 (YAML, 파일이 바뀌면 자동으로 다시 불러옴)")
+	signaturesFlag := flag.String("signatures", "", "새 파일과 수정된 파일의 내용을 검사할 바이트 시그니처 파일 (YAML)")
+	quarantineFlag := flag.String("quarantine-dir", "", "quarantine: true인 시그니처나 규칙과 일치한 파일을 옮겨 둘 격리 디렉토리")
+	dryRunFlag := flag.Bool("quarantine-dry-run", false, "파일을 옮기지 않고 격리 대상만 기록")
+	reconcileFlag := flag.Bool("reconcile", true, "시작 시 중지된 동안의 변경을 인벤토리와 비교하여 기록")
 	testFlag := flag.Bool("test", false, "테스트 모드 (더미 파일 생성)")
 	versionFlag := flag.Bool("version", false, "버전 정보 출력")
 	flag.Parse()
@@ -41,69 +76,217 @@ func main() {
 	log.SetPrefix("[파일 모니터] ")
 	log.SetFlags(log.Ldate | log.Ltime)
 
+	// 설정 파일과 명령줄 플래그 합치기 (명시한 플래그가 우선)
+	flags := cliFlags{
+		interval:    intervalFlag,
+		device:      deviceFlag,
+		filters:     filtersFlag,
+		types:       typesFlag,
+		ops:         opsFlag,
+		db:          dbPathFlag,
+		excludeDirs: excludeDirsFlag,
+		hash:        hashFlag,
+		hashMaxSize: hashMaxSizeFlag,
+		reconcile:   reconcileFlag,
+		watcher:     watcherFlag,
+		burstWindow: burstWindowFlag,
+		burstGlobal: burstGlobalFlag,
+		burstDir:    burstDirFlag,
+		burstExt:    burstExtFlag,
+		rules:       rulesFlag,
+		signatures:  signaturesFlag,
+		quarantine:  quarantineFlag,
+		dryRun:      dryRunFlag,
+		pathRule:    pathRule,
+	}
+	cfg, err := loadSettings(*configFlag, flags)
+	if err != nil {
+		log.Fatalf("설정 파일 읽기 실패:\n%v", err)
+	}
+
+	// 로그 파일 설정
+	if cfg.logging.File != "" {
+		logFile, err := os.OpenFile(cfg.logging.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatalf("로그 파일 열기 실패: %v", err)
+		}
+		defer logFile.Close()
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+	}
+
+	// 디버그 모드 확인
+	debugMode := os.Getenv("DEBUG_MONITOR") == "true" || cfg.logging.Debug
+	if debugMode {
+		log.Println("디버그 모드 활성화됨")
+	}
+
 	// 모니터 인스턴스 생성
-	mon := monitor.NewMonitor(*intervalFlag)
+	mon := monitor.NewMonitor(cfg.interval)
 
 	// 데이터베이스 경로 설정
-	mon.SetDatabasePath(*dbPathFlag)
+	mon.SetDatabasePath(cfg.dbPath)
+	mon.SetReconcile(cfg.reconcile)
 
-	// 장치 추가
-	if *deviceFlag != "" {
-		devices := strings.Split(*deviceFlag, ",")
-		for _, device := range devices {
-			mon.AddDevice(strings.TrimSpace(device))
+	// 변경 알림 방식 설정
+	switch cfg.watcher {
+	case watcherFsnotify:
+	case watcherFanotify:
+		backend, err := monitor.NewFanotifyBackend()
+		if err != nil {
+			log.Fatalf("감시 백엔드 생성 실패: %v", err)
 		}
-	} else {
-		// 기본 장치 추가 (Windows 기준)
-		mon.AddDevice("C:\\")
-	}
-
-	// 파일 필터 설정
-	if *filtersFlag != "" {
-		filters := strings.Split(*filtersFlag, ",")
-		// 확장자 형식 확인 및 수정
-		for i, filter := range filters {
-			filter = strings.TrimSpace(filter)
-			if !strings.HasPrefix(filter, ".") {
-				filters[i] = "." + filter
-			} else {
-				filters[i] = filter
-			}
+		mon.SetBackend(backend)
+	default:
+		log.Fatalf("알 수 없는 변경 알림 방식: %s (%s, %s)", cfg.watcher, watcherFsnotify, watcherFanotify)
+	}
+
+	// 장치 추가 (폴링 장치는 감시 방식을 먼저 설정)
+	mon.SetPollingOptions(cfg.polling)
+	for _, device := range cfg.devices {
+		if err := mon.SetDeviceBackend(device, backendOf(cfg.backends, device)); err != nil {
+			log.Fatalf("장치 감시 방식 설정 실패: %v", err)
 		}
-		mon.SetFileFilters(filters)
+		mon.AddDevice(device)
 	}
 
-	// 테스트 모드
-	if *testFlag || debugMode {
-		go generateTestFiles()
+	// 필터 설정
+	if _, err := mon.ApplyFilterConfig(cfg.filters); err != nil {
+		log.Fatalf("필터 설정 실패: %v", err)
+	}
+
+	// 해시 계산 설정
+	if err := mon.SetHashAlgorithms(cfg.hash); err != nil {
+		log.Fatalf("해시 알고리즘 설정 실패: %v", err)
 	}
+	mon.SetHashMaxSize(cfg.hashMaxSize << 20)
 
-	// 모니터링 시작
-	err := mon.Start()
+	// 대량 변경 감지 설정
+	if err := mon.SetBurstThresholds(cfg.burst); err != nil {
+		log.Fatalf("대량 변경 감지 설정 실패: %v", err)
+	}
+
+	// 바이트 시그니처 검사 설정
+	if cfg.signatures != "" {
+		set, err := signature.LoadFile(cfg.signatures)
+		if err != nil {
+			log.Fatalf("시그니처 불러오기 실패:\n%v", err)
+		}
+		mon.SetSignatures(set)
+	}
+
+	// 격리 설정
+	if err := mon.SetQuarantine(cfg.quarantine); err != nil {
+		log.Fatalf("격리 설정 실패: %v", err)
+	}
+
+	// 이벤트 싱크
+	sinks, err := startSinks(mon, cfg.sinks)
 	if err != nil {
-		log.Fatalf("모니터링 시작 실패: %v", err)
+		log.Fatalf("싱크 설정 실패: %v", err)
+	}
+
+	// 테스트 모드
+	if *testFlag || debugMode {
+		go generateTestFiles()
 	}
 
-	fmt.Printf("파일 모니터링이 시작되었습니다. 종료하려면 Ctrl+C를 누르세요.\n")
+	fmt.Printf("파일 모니터링을 시작합니다. 종료하려면 Ctrl+C를 누르세요.\n")
 	fmt.Printf("모니터링 대상: %s\n", strings.Join(mon.GetDevices(), ", "))
+	for _, device := range mon.GetDevices() {
+		if backend := mon.GetDeviceBackend(device); backend != monitor.BackendNotify {
+			fmt.Printf("감시 방식 (%s): %s\n", device, backend)
+		}
+	}
 	fmt.Printf("파일 필터: %s\n", strings.Join(mon.GetFileFilters(), ", "))
-	fmt.Printf("데이터베이스: %s\n", *dbPathFlag)
-	fmt.Printf("저장 간격: %s\n", *intervalFlag)
-
-	// 종료 시그널 처리
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+	if len(mon.GetTypeFilters()) > 0 {
+		fmt.Printf("유형 필터: %s\n", strings.Join(mon.GetTypeFilters(), ", "))
+	}
+	if rule := mon.GetPathRule(); !rule.Empty() {
+		fmt.Printf("경로 규칙: %s\n", rule.String())
+	}
+	fmt.Printf("기록 작업: %s\n", strings.Join(mon.GetOperations(), ", "))
+	deviceFilters := mon.GetFilterConfig().Devices
+	for _, device := range slices.Sorted(maps.Keys(deviceFilters)) {
+		fmt.Printf("장치 필터 (%s): %s\n", device, deviceFilters[device])
+	}
+	if hashes := mon.GetHashAlgorithms(); len(hashes) > 0 {
+		fmt.Printf("해시: %s (최대 %dMB)\n", strings.Join(hashes, ", "), cfg.hashMaxSize)
+	}
+	if b := mon.GetBurstThresholds(); b.Global > 0 || b.Directory > 0 || b.Extension > 0 {
+		fmt.Printf("대량 변경 감지: %s 동안 전체 %d, 디렉토리 %d, 확장자 %d개 이상\n", b.Window, b.Global, b.Directory, b.Extension)
+	}
+	if cfg.rules != "" {
+		fmt.Printf("탐지 규칙: %s\n", cfg.rules)
+	}
+	if cfg.signatures != "" {
+		fmt.Printf("시그니처: %s\n", cfg.signatures)
+	}
+	if q := mon.GetQuarantine(); q.Dir != "" {
+		if q.DryRun {
+			fmt.Printf("격리 디렉토리: %s (dry-run, 기록만 함)\n", q.Dir)
+		} else {
+			fmt.Printf("격리 디렉토리: %s\n", q.Dir)
+		}
+	}
+	fmt.Printf("데이터베이스: %s\n", cfg.dbPath)
+	fmt.Printf("저장 간격: %s\n", cfg.interval)
 
-	// 모니터링 중지
-	mon.Stop()
+	// 종료 시그널을 받을 때까지 모니터링 실행
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	if *configFlag != "" {
+		// SIGHUP을 받으면 설정 파일을 다시 읽어 필터 설정과 장치 목록 교체
+		go reloadOnSIGHUP(ctx, mon, *configFlag, flags, cfg)
+	}
+	// 탐지 규칙 평가
+	detection := &sync.WaitGroup{}
+	if cfg.rules != "" {
+		if detection, err = startRules(ctx, mon, cfg.rules); err != nil {
+			log.Fatalf("탐지 규칙 불러오기 실패:\n%v", err)
+		}
+	}
+	err = mon.Run(ctx)
+	stop()
+	sinks.Wait()
+	detection.Wait()
 
 	// 이벤트 출력
 	mon.PrintStats()
 
+	if err != nil {
+		log.Fatalf("모니터링 실패: %v", err)
+	}
+
 	fmt.Println("프로그램이 종료되었습니다.")
 }
 
+// expandExcludeDirs는 디렉토리 제외 패턴 목록에서 "default" 항목을 플랫폼 기본 목록으로 확장합니다.
+func expandExcludeDirs(values []string) []string {
+	var patterns []string
+	for _, pattern := range values {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "default" {
+			patterns = append(patterns, monitor.DefaultExcludeDirs()...)
+		} else if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// commandLine은 isFlagSet이 확인하는 플래그 집합입니다. 테스트에서는 별도의 FlagSet으로 바꿉니다.
+var commandLine = flag.CommandLine
+
+// isFlagSet은 명령줄에서 해당 플래그가 명시적으로 지정되었는지 확인합니다.
+func isFlagSet(name string) bool {
+	set := false
+	commandLine.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 // generateTestFiles는 테스트용 더미 파일을 생성합니다.
 func generateTestFiles() {
 	log.Println("테스트 파일 생성 모드 시작")
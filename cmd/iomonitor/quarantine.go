@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+const quarantineUsage = "사용법: iomonitor quarantine list [-db 경로] [-all]\n" +
+	"       iomonitor quarantine restore [-db 경로] [-dry-run] <ID>...\n" +
+	"       iomonitor quarantine purge [-db 경로] [-dry-run] [-older-than 기간] [<ID>...]"
+
+// runQuarantineCommand는 "iomonitor quarantine <명령>" 하위 명령을 처리하고 종료 코드를 반환합니다.
+func runQuarantineCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, quarantineUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return runQuarantineList(args[1:])
+	case "restore":
+		return runQuarantineRelease("restore", args[1:])
+	case "purge":
+		return runQuarantineRelease("purge", args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "알 수 없는 quarantine 명령: %s\n", args[0])
+		return 2
+	}
+}
+
+// runQuarantineList는 격리 기록을 출력합니다. 기본으로 현재 격리 중인 파일만 보여 줍니다.
+func runQuarantineList(args []string) int {
+	fs := flag.NewFlagSet("quarantine list", flag.ContinueOnError)
+	dbPath := fs.String("db", "monitor.db", "데이터베이스 파일 경로")
+	all := fs.Bool("all", false, "복원, 삭제된 기록과 dry-run 기록까지 모두 출력")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	db, err := monitor.NewDatabase(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "데이터베이스 열기 실패: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	entries, err := db.GetQuarantineEntries(*all)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "격리 기록 조회 실패: %v\n", err)
+		return 1
+	}
+	for _, e := range entries {
+		fmt.Printf("%s %s\n", e.QuarantinedAt.Format("2006-01-02 15:04:05"), e)
+		owner := e.Owner
+		if e.Group != "" {
+			owner += ":" + e.Group
+		}
+		fmt.Printf("    크기 %d, 권한 %v, 소유자 %s", e.Size, e.Mode, owner)
+		if !e.ReleasedAt.IsZero() {
+			fmt.Printf(", 해제 %s", e.ReleasedAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("격리 기록 %d개\n", len(entries))
+	return 0
+}
+
+// runQuarantineRelease는 격리된 파일을 복원(restore)하거나 영구히 삭제(purge)합니다.
+// purge는 ID 대신 -older-than으로 오래된 격리 파일을 한꺼번에 지정할 수 있습니다.
+func runQuarantineRelease(command string, args []string) int {
+	fs := flag.NewFlagSet("quarantine "+command, flag.ContinueOnError)
+	dbPath := fs.String("db", "monitor.db", "데이터베이스 파일 경로")
+	dryRun := fs.Bool("dry-run", false, "변경 없이 처리할 대상만 출력")
+	var olderThan *time.Duration
+	if command == "purge" {
+		olderThan = fs.Duration("older-than", 0, "이 기간보다 오래 격리된 파일을 모두 삭제 (예: 720h)")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var ids []int64
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			fmt.Fprintf(os.Stderr, "올바르지 않은 격리 ID: %s\n", arg)
+			return 2
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 && (olderThan == nil || *olderThan <= 0) {
+		fmt.Fprintln(os.Stderr, quarantineUsage)
+		return 2
+	}
+
+	db, err := monitor.NewDatabase(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "데이터베이스 열기 실패: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if olderThan != nil && *olderThan > 0 {
+		entries, err := db.GetQuarantineEntries(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "격리 기록 조회 실패: %v\n", err)
+			return 1
+		}
+		cutoff := time.Now().Add(-*olderThan)
+		for _, e := range entries {
+			if e.QuarantinedAt.Before(cutoff) {
+				ids = append(ids, e.ID)
+			}
+		}
+	}
+
+	release, done := monitor.RestoreQuarantined, "복원됨"
+	if command == "purge" {
+		release, done = monitor.PurgeQuarantined, "삭제됨"
+	}
+	if *dryRun {
+		done = "dry-run, " + done
+	}
+
+	failed := 0
+	for _, id := range ids {
+		entry, err := release(db, id, *dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "#%d 처리 실패: %v\n", id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("#%d %s (%s): %s\n", entry.ID, done, entry.Reason, entry.OriginalPath)
+	}
+	if *dryRun {
+		fmt.Println("dry-run 모드: 변경 사항이 적용되지 않았습니다.")
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+	"github.com/yhj0901/windowsIOMonitoring/pkg/rules"
+)
+
+// rulesCheckInterval은 탐지 규칙 파일이 바뀌었는지 확인하는 주기입니다.
+const rulesCheckInterval = 2 * time.Second
+
+// startRules는 탐지 규칙 파일을 불러와 이벤트 스트림에 적용합니다. Run 전에 호출해야 합니다.
+// 규칙 파일이 바뀌면 자동으로 다시 불러오며, 모니터가 중지되면 반환된 WaitGroup이 완료됩니다.
+func startRules(ctx context.Context, mon *monitor.Monitor, path string) (*sync.WaitGroup, error) {
+	engine := rules.NewEngine()
+	if err := engine.LoadFile(path); err != nil {
+		return nil, err
+	}
+	// hash 조건은 파일을 다시 읽지 않고 모니터가 계산한 해시로 확인
+	engine.SetHashSource(func(event monitor.FileEvent) (monitor.FileHashes, bool) {
+		return mon.WaitFileHashes(ctx, event.ID)
+	})
+	// type 조건은 이벤트 시점에 유형이 없으면 모니터가 파일이 안정된 뒤에 판별한 유형으로 확인
+	engine.SetTypeSource(func(event monitor.FileEvent) (string, bool) {
+		return mon.WaitDetectedType(ctx, event.ID)
+	})
+	sub, err := mon.Subscribe(monitor.SubscribeOptions{Buffer: sinkBuffer})
+	if err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		engine.Run(sub.Events(), func(a rules.Alert) {
+			log.Printf("[탐지] %s", a)
+			if a.Quarantine {
+				quarantineAlert(mon, a)
+			}
+		})
+		if dropped := sub.Dropped(); dropped > 0 {
+			log.Printf("탐지 규칙 평가에서 유실된 이벤트: %d", dropped)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		engine.WatchFile(ctx, rulesCheckInterval)
+	}()
+	return &wg, nil
+}
+
+// quarantineAlert는 quarantine: true인 규칙의 경고에 기여한 이벤트의 파일 중 아직 남아 있는 파일을 격리합니다.
+func quarantineAlert(mon *monitor.Monitor, a rules.Alert) {
+	if mon.GetQuarantine().Dir == "" {
+		log.Printf("격리 디렉토리가 설정되지 않아 격리하지 않음: %s", a.RuleID)
+		return
+	}
+	seen := make(map[string]bool)
+	for _, event := range a.Events {
+		if seen[event.Path] {
+			continue
+		}
+		seen[event.Path] = true
+		if _, err := os.Lstat(event.Path); err != nil {
+			continue
+		}
+		if _, err := mon.Quarantine(event.Path, "rule:"+a.RuleID, event.ID); err != nil {
+			log.Printf("파일 격리 실패: %s - %v", event.Path, err)
+		}
+	}
+}
+
+// runRulesCommand는 "iomonitor rules <명령>" 하위 명령을 처리하고 종료 코드를 반환합니다.
+func runRulesCommand(args []string) int {
+	if len(args) != 2 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "사용법: iomonitor rules validate <규칙 파일>")
+		return 2
+	}
+
+	list, err := rules.LoadFile(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%s: 규칙 %d개가 올바릅니다\n", args[1], len(list))
+	return 0
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+	"github.com/yhj0901/windowsIOMonitoring/pkg/signature"
+)
+
+// runScanCommand는 "iomonitor scan <경로>..." 하위 명령을 처리하고 종료 코드를 반환합니다.
+// 디렉토리는 하위 파일까지 모두 검사합니다. 일치한 파일이 있으면 1, 검사 중 오류만 있으면 2,
+// 아무것도 일치하지 않으면 0을 반환합니다.
+func runScanCommand(args []string) int {
+	fset := flag.NewFlagSet("scan", flag.ContinueOnError)
+	sigPath := fset.String("signatures", "signatures.yaml", "바이트 시그니처 파일 (YAML)")
+	maxSize := fset.Int64("max-size", 32, "파일마다 앞부분에서 검사할 최대 크기 (MB, 0은 전체)")
+	fset.Usage = func() {
+		fmt.Fprintln(os.Stderr, "사용법: iomonitor scan [-signatures 파일] [-max-size MB] <경로>...")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return 2
+	}
+
+	set, err := signature.LoadFile(*sigPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var scanned, matched, failed int
+	scanFile := func(path string) {
+		types := []string{strings.ToLower(filepath.Ext(path))}
+		if detected, err := monitor.SniffFile(path); err == nil {
+			types = append(types, detected)
+		}
+		if !set.Applies(types...) {
+			return
+		}
+		matches, err := set.ScanFile(path, *maxSize<<20, types...)
+		scanned++
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "검사 실패: %s - %v\n", path, err)
+			failed++
+			return
+		}
+		if len(matches) > 0 {
+			matched++
+		}
+		for _, m := range matches {
+			fmt.Printf("%s: %s\n", path, m)
+		}
+	}
+
+	for _, root := range fset.Args() {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "읽기 실패: %s - %v\n", path, err)
+				failed++
+				return nil
+			}
+			if d.Type().IsRegular() {
+				scanFile(path)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "검사 실패: %s - %v\n", root, err)
+			failed++
+		}
+	}
+
+	fmt.Printf("파일 %d개 검사, %d개 일치", scanned, matched)
+	if failed > 0 {
+		fmt.Printf(", 오류 %d개", failed)
+	}
+	fmt.Println()
+	switch {
+	case matched > 0:
+		return 1
+	case failed > 0:
+		return 2
+	}
+	return 0
+}
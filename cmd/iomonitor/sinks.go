@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// 설정 파일의 sinks[].type에 사용할 수 있는 싱크 종류입니다.
+const (
+	sinkConsole = "console"
+	sinkJSONL   = "jsonl"
+)
+
+// sinkBuffer는 싱크별 구독 버퍼 크기입니다.
+const sinkBuffer = 1000
+
+// jsonlEvent는 jsonl 싱크가 한 줄에 기록하는 이벤트입니다.
+type jsonlEvent struct {
+	ID           int64             `json:"id"`
+	Path         string            `json:"path"`
+	Operation    string            `json:"operation"`
+	Timestamp    time.Time         `json:"timestamp"`
+	FileType     string            `json:"file_type,omitempty"`
+	DetectedType string            `json:"detected_type,omitempty"`
+	OldPath      string            `json:"old_path,omitempty"`
+	NewPath      string            `json:"new_path,omitempty"`
+	WriteCount   int               `json:"write_count,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Reconciled   bool              `json:"reconciled,omitempty"`
+	Stat         *jsonlStat        `json:"stat,omitempty"`
+	Process      *jsonlProcess     `json:"process,omitempty"`
+	Alert        *jsonlAlert       `json:"alert,omitempty"`
+}
+
+// jsonlAlert는 대량 변경 경고입니다. operation이 ALERT인 줄에만 기록됩니다.
+type jsonlAlert struct {
+	ID          int64          `json:"id"`
+	Scope       string         `json:"scope"`
+	Key         string         `json:"key,omitempty"`
+	Count       int            `json:"count"`
+	Threshold   int            `json:"threshold"`
+	Window      string         `json:"window"`
+	FirstSeen   time.Time      `json:"first_seen"`
+	Operations  map[string]int `json:"operations"`
+	Directories int            `json:"directories"`
+	Samples     []string       `json:"samples,omitempty"`
+}
+
+// newJSONLAlert는 경고를 jsonl 형식으로 변환합니다. 경고 이벤트가 아니면 nil입니다.
+func newJSONLAlert(a *monitor.Alert) *jsonlAlert {
+	if a == nil {
+		return nil
+	}
+	return &jsonlAlert{
+		ID:          a.ID,
+		Scope:       a.Scope,
+		Key:         a.Key,
+		Count:       a.Count,
+		Threshold:   a.Threshold,
+		Window:      a.Window.String(),
+		FirstSeen:   a.FirstSeen,
+		Operations:  a.Operations,
+		Directories: a.Directories,
+		Samples:     a.Samples,
+	}
+}
+
+// jsonlProcess는 이벤트를 일으킨 프로세스입니다.
+type jsonlProcess struct {
+	PID int    `json:"pid"`
+	Exe string `json:"exe,omitempty"`
+	UID int    `json:"uid"`
+}
+
+// jsonlStat은 이벤트 시점의 파일 상태입니다.
+type jsonlStat struct {
+	Size       int64     `json:"size"`
+	Mode       string    `json:"mode"`
+	Executable bool      `json:"executable"`
+	UID        int       `json:"uid"`
+	GID        int       `json:"gid"`
+	Owner      string    `json:"owner,omitempty"`
+	Group      string    `json:"group,omitempty"`
+	ModTime    time.Time `json:"mtime"`
+	ChangeTime time.Time `json:"ctime,omitzero"`
+	Inode      uint64    `json:"inode,omitempty"`
+	Links      uint64    `json:"nlink,omitempty"`
+}
+
+// newJSONLProcess는 프로세스 정보를 jsonl 형식으로 변환합니다. 정보가 없으면 nil입니다.
+func newJSONLProcess(p *monitor.ProcessInfo) *jsonlProcess {
+	if p == nil {
+		return nil
+	}
+	return &jsonlProcess{PID: p.PID, Exe: p.Exe, UID: p.UID}
+}
+
+// newJSONLStat은 파일 상태를 jsonl 형식으로 변환합니다. 상태가 없으면 nil입니다.
+func newJSONLStat(st *monitor.FileStat) *jsonlStat {
+	if st == nil {
+		return nil
+	}
+	return &jsonlStat{
+		Size:       st.Size,
+		Mode:       st.Mode.String(),
+		Executable: st.Executable,
+		UID:        st.UID,
+		GID:        st.GID,
+		Owner:      st.Owner,
+		Group:      st.Group,
+		ModTime:    st.ModTime,
+		ChangeTime: st.ChangeTime,
+		Inode:      st.Inode,
+		Links:      st.Links,
+	}
+}
+
+// startSinks는 싱크마다 구독을 만들어 이벤트를 내보냅니다. Run 전에 호출해야 합니다.
+// 모니터가 중지되어 모든 구독이 닫히면 반환된 WaitGroup이 완료됩니다.
+func startSinks(mon *monitor.Monitor, sinks []sinkConfig) (*sync.WaitGroup, error) {
+	var wg sync.WaitGroup
+	for _, sink := range sinks {
+		var write func(monitor.FileEvent) error
+		var closeSink func()
+		switch sink.Type {
+		case sinkConsole:
+			write = writeConsoleEvent
+		case sinkJSONL:
+			f, err := os.OpenFile(sink.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return nil, fmt.Errorf("jsonl 싱크 파일 열기 실패: %v", err)
+			}
+			enc := json.NewEncoder(f)
+			write = func(e monitor.FileEvent) error {
+				return enc.Encode(jsonlEvent{
+					ID:           e.ID,
+					Path:         e.Path,
+					Operation:    e.Operation,
+					Timestamp:    e.Timestamp,
+					FileType:     e.FileType,
+					DetectedType: e.DetectedType,
+					OldPath:      e.OldPath,
+					NewPath:      e.NewPath,
+					WriteCount:   e.WriteCount,
+					Metadata:     e.Metadata,
+					Reconciled:   e.Reconciled,
+					Stat:         newJSONLStat(e.Stat),
+					Process:      newJSONLProcess(e.Process),
+					Alert:        newJSONLAlert(e.Alert),
+				})
+			}
+			closeSink = func() { f.Close() }
+		default:
+			return nil, fmt.Errorf("알 수 없는 싱크 종류: %s", sink.Type)
+		}
+
+		sub, err := mon.Subscribe(monitor.SubscribeOptions{Buffer: sinkBuffer})
+		if err != nil {
+			return nil, err
+		}
+		wg.Add(1)
+		go func(sink sinkConfig) {
+			defer wg.Done()
+			if closeSink != nil {
+				defer closeSink()
+			}
+			for event := range sub.Events() {
+				if err := write(event); err != nil {
+					log.Printf("%s 싱크 기록 실패: %v", sink.Type, err)
+				}
+			}
+			if dropped := sub.Dropped(); dropped > 0 {
+				log.Printf("%s 싱크에서 유실된 이벤트: %d", sink.Type, dropped)
+			}
+		}(sink)
+		log.Printf("싱크 추가됨: %s %s", sink.Type, sink.Path)
+	}
+	return &wg, nil
+}
+
+// writeConsoleEvent는 이벤트를 한 줄로 표준 출력에 씁니다.
+func writeConsoleEvent(e monitor.FileEvent) error {
+	path := e.Path
+	if e.Alert != nil {
+		path = e.Alert.String()
+	}
+	if e.OldPath != "" && e.NewPath != "" {
+		path = e.OldPath + " -> " + e.NewPath
+	}
+	if p := e.Process; p != nil {
+		path += fmt.Sprintf(" (PID %d %s)", p.PID, p.Exe)
+	}
+	_, err := fmt.Printf("[%s] %s %s\n", e.Timestamp.Format("2006-01-02 15:04:05"), e.Operation, path)
+	return err
+}
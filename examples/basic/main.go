@@ -23,7 +23,9 @@ func main() {
 	mon.AddDevice("C:\\")
 
 	// 파일 필터 설정
-	mon.SetFileFilters([]string{".exe", ".dll"})
+	if err := mon.SetFileFilters([]string{".exe", ".dll"}); err != nil {
+		log.Fatalf("파일 필터 설정 실패: %v", err)
+	}
 
 	// 모니터링 시작
 	err := mon.Start()
@@ -2,9 +2,11 @@ module github.com/yhj0901/windowsIOMonitoring
 
 go 1.24.0
 
-require github.com/fsnotify/fsnotify v1.8.0
-
 require (
-	github.com/mattn/go-sqlite3 v1.14.24 // indirect
-	golang.org/x/sys v0.13.0 // indirect
+	github.com/BurntSushi/toml v1.6.0
+	github.com/bmatcuk/doublestar/v4 v4.10.0
+	github.com/fsnotify/fsnotify v1.8.0
+	github.com/mattn/go-sqlite3 v1.14.24
+	golang.org/x/sys v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
 )
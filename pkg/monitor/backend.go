@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchOp는 감시 백엔드가 보고하는 파일 시스템 작업 종류입니다.
+type WatchOp uint32
+
+const (
+	WatchCreate WatchOp = 1 << iota
+	WatchWrite
+	WatchRemove
+	WatchRename
+	WatchChmod
+	// WatchExec는 파일이 실행되었음을 나타냅니다. fanotify 백엔드만 보고합니다.
+	WatchExec
+)
+
+// Has는 op에 o 작업이 포함되어 있는지 확인합니다.
+func (op WatchOp) Has(o WatchOp) bool {
+	return op&o == o
+}
+
+// String은 작업 종류를 "CREATE|WRITE" 형식의 문자열로 반환합니다.
+func (op WatchOp) String() string {
+	var names []string
+	if op.Has(WatchCreate) {
+		names = append(names, "CREATE")
+	}
+	if op.Has(WatchWrite) {
+		names = append(names, "WRITE")
+	}
+	if op.Has(WatchRemove) {
+		names = append(names, "REMOVE")
+	}
+	if op.Has(WatchRename) {
+		names = append(names, "RENAME")
+	}
+	if op.Has(WatchChmod) {
+		names = append(names, "CHMOD")
+	}
+	if op.Has(WatchExec) {
+		names = append(names, "EXEC")
+	}
+	if len(names) == 0 {
+		return "[no events]"
+	}
+	return strings.Join(names, "|")
+}
+
+// WatchEvent는 감시 백엔드가 전달하는 원시 파일 시스템 이벤트입니다.
+type WatchEvent struct {
+	Name    string
+	Op      WatchOp
+	Process *ProcessInfo // 이벤트를 일으킨 프로세스 (백엔드가 제공하지 않으면 nil)
+}
+
+// ProcessInfo는 이벤트를 일으킨 프로세스입니다.
+// 이벤트를 처리할 때 이미 종료된 프로세스는 Exe가 빈 문자열, UID가 -1일 수 있습니다.
+type ProcessInfo struct {
+	PID int
+	Exe string // 실행 파일 경로
+	UID int    // 프로세스의 실제 사용자 ID
+}
+
+// WatcherBackend는 Monitor에 원시 파일 시스템 이벤트를 공급하는 감시 백엔드입니다.
+//
+// Add와 Remove는 단일 디렉토리 단위로 동작하며, 하위 디렉토리의 재귀 등록은
+// Monitor가 담당합니다. Close 이후에는 Events와 Errors 채널이 닫혀야 합니다.
+type WatcherBackend interface {
+	Add(path string) error
+	Remove(path string) error
+	Events() <-chan WatchEvent
+	Errors() <-chan error
+	Close() error
+}
+
+// recursiveBackend는 Add로 등록한 디렉토리 아래의 모든 하위 경로 이벤트를 전달하는 백엔드입니다.
+// Monitor는 이런 백엔드에 장치 루트만 등록하고 하위 디렉토리를 탐색하여 등록하지 않습니다.
+type recursiveBackend interface {
+	recursive() bool
+}
+
+// isRecursiveBackend는 백엔드가 하위 디렉토리까지 감시하는지 확인합니다.
+func isRecursiveBackend(b WatcherBackend) bool {
+	rb, ok := b.(recursiveBackend)
+	return ok && rb.recursive()
+}
+
+// fsnotifyBackend는 fsnotify 기반의 기본 감시 백엔드입니다.
+type fsnotifyBackend struct {
+	watcher   *fsnotify.Watcher
+	events    chan WatchEvent
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewFsnotifyBackend는 fsnotify 기반 감시 백엔드를 생성합니다.
+func NewFsnotifyBackend() (WatcherBackend, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &fsnotifyBackend{
+		watcher: watcher,
+		events:  make(chan WatchEvent),
+		errors:  make(chan error),
+		done:    make(chan struct{}),
+	}
+	go b.forward()
+	return b, nil
+}
+
+// forward는 fsnotify 이벤트를 WatchEvent로 변환하여 전달합니다.
+func (b *fsnotifyBackend) forward() {
+	defer close(b.events)
+	defer close(b.errors)
+
+	for {
+		select {
+		case event, ok := <-b.watcher.Events:
+			if !ok {
+				return
+			}
+			select {
+			case b.events <- WatchEvent{Name: event.Name, Op: convertFsnotifyOp(event.Op)}:
+			case <-b.done:
+				return
+			}
+
+		case err, ok := <-b.watcher.Errors:
+			if !ok {
+				return
+			}
+			select {
+			case b.errors <- err:
+			case <-b.done:
+				return
+			}
+
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// convertFsnotifyOp는 fsnotify 작업 비트를 WatchOp로 변환합니다.
+func convertFsnotifyOp(op fsnotify.Op) WatchOp {
+	var result WatchOp
+	if op.Has(fsnotify.Create) {
+		result |= WatchCreate
+	}
+	if op.Has(fsnotify.Write) {
+		result |= WatchWrite
+	}
+	if op.Has(fsnotify.Remove) {
+		result |= WatchRemove
+	}
+	if op.Has(fsnotify.Rename) {
+		result |= WatchRename
+	}
+	if op.Has(fsnotify.Chmod) {
+		result |= WatchChmod
+	}
+	return result
+}
+
+func (b *fsnotifyBackend) Add(path string) error     { return b.watcher.Add(path) }
+func (b *fsnotifyBackend) Remove(path string) error  { return b.watcher.Remove(path) }
+func (b *fsnotifyBackend) Events() <-chan WatchEvent { return b.events }
+func (b *fsnotifyBackend) Errors() <-chan error      { return b.errors }
+
+// Close는 fsnotify 감시자를 닫고 이벤트 전달을 중지합니다.
+func (b *fsnotifyBackend) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		err = b.watcher.Close()
+	})
+	return err
+}
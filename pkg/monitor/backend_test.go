@@ -0,0 +1,314 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startFakeMonitor는 FakeBackend와 임시 데이터베이스를 사용하는 모니터를 시작합니다.
+// configure 함수들은 Start 전에 호출됩니다.
+func startFakeMonitor(t *testing.T, configure ...func(*Monitor)) (*Monitor, *FakeBackend, string) {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+
+	fake := NewFakeBackend()
+	mon := NewMonitor(time.Hour)
+	mon.SetBackend(fake)
+	mon.SetDatabasePath(dbPath)
+	mon.AddDevice(dir)
+	for _, fn := range configure {
+		fn(mon)
+	}
+
+	if err := mon.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	return mon, fake, dir
+}
+
+// waitEvents는 이벤트 채널에서 n개의 이벤트를 기다립니다.
+func waitEvents(t *testing.T, mon *Monitor, n int) []FileEvent {
+	t.Helper()
+	var events []FileEvent
+	timeout := time.After(5 * time.Second)
+	for len(events) < n {
+		select {
+		case event := <-mon.EventChan():
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("Expected %d events, got %d: %v", n, len(events), events)
+		}
+	}
+	return events
+}
+
+func TestFakeBackendPipeline(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	dbPath := mon.dbPath
+
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "a.exe"), Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "notes.txt"), Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "a.exe"), Op: WatchWrite})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "b.DLL"), Op: WatchRemove})
+
+	events := waitEvents(t, mon, 2)
+	if events[0].Operation != "CREATE" || events[0].FileType != ".exe" {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1].Operation != "REMOVE" || events[1].FileType != ".dll" {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	saved, err := db.GetFileEvents()
+	if err != nil {
+		t.Fatalf("GetFileEvents failed: %v", err)
+	}
+	if len(saved) != 2 {
+		t.Fatalf("Expected 2 saved events, got %d: %v", len(saved), saved)
+	}
+}
+
+func TestFakeBackendWatchesNewDirectories(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	defer mon.Stop()
+
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(filepath.Join(sub, "inner"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: sub, Op: WatchCreate})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		watched := map[string]bool{}
+		for _, path := range fake.Watched() {
+			watched[path] = true
+		}
+		if watched[sub] && watched[filepath.Join(sub, "inner")] {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("New directory was not watched: %v", fake.Watched())
+}
+
+func TestFakeBackendPushWhenFull(t *testing.T) {
+	fake := NewFakeBackend()
+	for i := 0; i < cap(fake.events); i++ {
+		fake.Push(WatchEvent{Name: "fill", Op: WatchWrite})
+	}
+	pushed := make(chan struct{})
+	go func() {
+		fake.Push(WatchEvent{Name: "blocked", Op: WatchCreate})
+		close(pushed)
+	}()
+
+	// 가득 찬 버퍼에서 기다리는 Push가 있어도 Add는 바로 반환되어야 함
+	added := make(chan error, 1)
+	go func() { added <- fake.Add("dir") }()
+	select {
+	case err := <-added:
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Add blocked while Push was waiting on a full buffer")
+	}
+
+	// Close는 기다리는 Push를 끝내고 채널을 닫음
+	fake.Close()
+	select {
+	case <-pushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Push was not released by Close")
+	}
+	fake.Push(WatchEvent{Name: "after", Op: WatchCreate})
+}
+
+func TestFakeBackendMoveCorrelation(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	defer mon.Stop()
+
+	oldPath := filepath.Join(dir, "payload.txt")
+	newPath := filepath.Join(dir, "payload.exe")
+	fake.Push(WatchEvent{Name: oldPath, Op: WatchRename})
+	fake.Push(WatchEvent{Name: newPath, Op: WatchCreate})
+
+	events := waitEvents(t, mon, 1)
+	event := events[0]
+	if event.Operation != "MOVE" {
+		t.Fatalf("Expected MOVE event, got %+v", event)
+	}
+	if event.OldPath != oldPath || event.NewPath != newPath || event.Path != newPath {
+		t.Errorf("Unexpected MOVE paths: %+v", event)
+	}
+	if event.FileType != ".exe" {
+		t.Errorf("Expected file type .exe, got %s", event.FileType)
+	}
+}
+
+func TestFakeBackendUnrelatedCreateAfterRename(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	defer mon.Stop()
+
+	// 다른 디렉토리에서 다른 이름으로 생성된 파일은 이름 변경과 짝짓지 않음
+	renamed := filepath.Join(dir, "old.dll")
+	created := filepath.Join(dir, "sub", "new.exe")
+	fake.Push(WatchEvent{Name: renamed, Op: WatchRename})
+	fake.Push(WatchEvent{Name: created, Op: WatchCreate})
+
+	events := waitEvents(t, mon, 2)
+	if events[0].Operation != OperationCreate || events[0].Path != created {
+		t.Errorf("Expected CREATE for %s, got %+v", created, events[0])
+	}
+	if events[1].Operation != OperationRename || events[1].OldPath != renamed {
+		t.Errorf("Expected orphan RENAME for %s, got %+v", renamed, events[1])
+	}
+}
+
+func TestFakeBackendOrphanRename(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	defer mon.Stop()
+
+	path := filepath.Join(dir, "gone.dll")
+	fake.Push(WatchEvent{Name: path, Op: WatchRename})
+
+	events := waitEvents(t, mon, 1)
+	if events[0].Operation != "RENAME" || events[0].OldPath != path {
+		t.Errorf("Expected RENAME event for %s, got %+v", path, events[0])
+	}
+}
+
+func TestFakeBackendOperationMask(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetOperations([]string{"WRITE", "CHMOD"}); err != nil {
+			t.Fatal(err)
+		}
+		m.SetWriteSettle(50 * time.Millisecond)
+	})
+	defer mon.Stop()
+
+	path := filepath.Join(dir, "app.exe")
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	fake.Push(WatchEvent{Name: path, Op: WatchChmod})
+	fake.Push(WatchEvent{Name: path, Op: WatchWrite})
+
+	events := waitEvents(t, mon, 2)
+	if events[0].Operation != "CHMOD" || events[1].Operation != "MODIFIED" {
+		t.Errorf("Expected CHMOD and MODIFIED events, got %+v", events)
+	}
+}
+
+func TestFakeBackendMoveWithoutRenameOp(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetOperations([]string{"CREATE", "REMOVE"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	defer mon.Stop()
+
+	// RENAME을 기록하지 않아도 새 이름은 CREATE로 기록되어야 함
+	newPath := filepath.Join(dir, "payload.exe")
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "payload.txt"), Op: WatchRename})
+	fake.Push(WatchEvent{Name: newPath, Op: WatchCreate})
+
+	events := waitEvents(t, mon, 1)
+	if events[0].Operation != OperationCreate || events[0].Path != newPath {
+		t.Errorf("Expected CREATE for %s, got %+v", newPath, events[0])
+	}
+}
+
+func TestFakeBackendWriteCoalescing(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetOperations([]string{"CREATE", "REMOVE", "WRITE"}); err != nil {
+			t.Fatal(err)
+		}
+		m.SetWriteSettle(100 * time.Millisecond)
+	})
+	defer mon.Stop()
+
+	path := filepath.Join(dir, "big.dll")
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	for i := 0; i < 20; i++ {
+		fake.Push(WatchEvent{Name: path, Op: WatchWrite})
+	}
+
+	events := waitEvents(t, mon, 2)
+	modified := events[1]
+	if events[0].Operation != "CREATE" || modified.Operation != "MODIFIED" {
+		t.Fatalf("Expected CREATE then MODIFIED, got %+v", events)
+	}
+	if modified.WriteCount != 20 {
+		t.Errorf("Expected 20 coalesced writes, got %d", modified.WriteCount)
+	}
+	if modified.FirstWrite.After(modified.LastWrite) {
+		t.Errorf("FirstWrite %v is after LastWrite %v", modified.FirstWrite, modified.LastWrite)
+	}
+
+	// 쓰기 도중 삭제되면 진행 중인 묶음이 삭제보다 먼저 기록되어야 함
+	fake.Push(WatchEvent{Name: path, Op: WatchWrite})
+	fake.Push(WatchEvent{Name: path, Op: WatchRemove})
+	events = waitEvents(t, mon, 2)
+	if events[0].Operation != "MODIFIED" || events[0].WriteCount != 1 || events[1].Operation != "REMOVE" {
+		t.Errorf("Expected MODIFIED then REMOVE, got %+v", events)
+	}
+}
+
+func TestFakeBackendSkipsExcludedDirs(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"src/app", "src/node_modules/pkg", ".git/objects", "build/out"} {
+		if err := os.MkdirAll(filepath.Join(base, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fake := NewFakeBackend()
+	mon := NewMonitor(time.Hour)
+	mon.SetBackend(fake)
+	mon.SetDatabasePath(filepath.Join(t.TempDir(), "test.db"))
+	mon.AddDevice(base)
+	if err := mon.SetExcludeDirs(append(DefaultExcludeDirs(), "re:/build$")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mon.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer mon.Stop()
+
+	want := []string{base, filepath.Join(base, "src"), filepath.Join(base, "src", "app")}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fake.Watched()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 제외된 디렉토리 아래에 새로 생성된 디렉토리도 감시하지 않아야 함
+	newModules := filepath.Join(base, "src", "app", "node_modules")
+	if err := os.Mkdir(newModules, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: newModules, Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(base, "marker.exe"), Op: WatchCreate})
+	waitEvents(t, mon, 1)
+
+	watched := fake.Watched()
+	if len(watched) != len(want) {
+		t.Fatalf("Expected watched dirs %v, got %v", want, watched)
+	}
+	for i := range want {
+		if watched[i] != want[i] {
+			t.Errorf("Expected watched dirs %v, got %v", want, watched)
+			break
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBurstWindow는 BurstThresholds.Window가 0일 때 사용하는 슬라이딩 윈도 크기입니다.
+const defaultBurstWindow = 10 * time.Second
+
+// maxAlertSamples는 경고에 포함하는 최근 경로의 최대 개수입니다.
+const maxAlertSamples = 10
+
+// Alert.Scope에 기록되는 경고 범위입니다.
+const (
+	AlertScopeGlobal    = "global"    // 감시 중인 전체 경로
+	AlertScopeDirectory = "directory" // 한 디렉토리 (하위 디렉토리는 포함하지 않음)
+	AlertScopeExtension = "extension" // 한 확장자
+)
+
+// BurstThresholds는 대량 파일 변경(랜섬웨어의 일괄 암호화, 대량 삭제 등) 감지 기준입니다.
+// Window 동안 발생한 변경 수가 범위별 임계값 이상이 되면 경고가 발생합니다.
+// 임계값이 0인 범위는 감지하지 않으며, 모두 0이면 감지를 사용하지 않습니다.
+type BurstThresholds struct {
+	Window    time.Duration // 변경 수를 세는 슬라이딩 윈도 (0이면 10초)
+	Global    int           // 전체 변경 수
+	Directory int           // 디렉토리별 변경 수
+	Extension int           // 확장자별 변경 수
+}
+
+// enabled는 하나 이상의 범위에 임계값이 설정되었는지 확인합니다.
+func (t BurstThresholds) enabled() bool {
+	return t.Global > 0 || t.Directory > 0 || t.Extension > 0
+}
+
+// Alert는 대량 파일 변경 감지 결과입니다.
+// 이벤트 스트림에는 Operation이 OperationAlert인 FileEvent의 Alert 필드로 전달되고,
+// 데이터베이스의 alerts 테이블에 저장됩니다.
+type Alert struct {
+	ID          int64
+	Timestamp   time.Time      // 임계값을 넘은 시간
+	Scope       string         // AlertScopeGlobal, AlertScopeDirectory, AlertScopeExtension
+	Key         string         // 디렉토리 경로 또는 확장자 (global이면 빈 문자열)
+	Count       int            // 윈도 안에서 발생한 변경 수
+	Threshold   int            // 넘어선 임계값
+	Window      time.Duration  // 변경 수를 센 윈도 크기
+	FirstSeen   time.Time      // 윈도 안의 첫 변경 시간
+	Operations  map[string]int // 작업별 변경 수 (CREATE, REMOVE, RENAME, WRITE)
+	Directories int            // 변경이 발생한 서로 다른 디렉토리 수
+	Samples     []string       // 최근 변경된 경로 (최대 10개)
+}
+
+// String은 로그에 출력할 경고 요약을 반환합니다.
+func (a Alert) String() string {
+	target := "전체"
+	if a.Key != "" {
+		target = fmt.Sprintf("%s %s", a.Scope, a.Key)
+	}
+	ops := make([]string, 0, len(a.Operations))
+	for op := range a.Operations {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for i, op := range ops {
+		ops[i] = fmt.Sprintf("%s %d", op, a.Operations[op])
+	}
+	return fmt.Sprintf("%s: %s 동안 %d개 변경 (임계값 %d, 디렉토리 %d개, %s)",
+		target, a.Window, a.Count, a.Threshold, a.Directories, strings.Join(ops, ", "))
+}
+
+// SetBurstThresholds는 대량 파일 변경 감지 기준을 설정합니다.
+// 모니터링 중에도 호출할 수 있으며, 지금까지 센 변경 수는 초기화됩니다.
+// 감지는 파일 필터, 경로 규칙, 기록 작업 설정과 관계없이 감시 중인 모든 파일의
+// 생성, 삭제, 이름 변경, 쓰기를 대상으로 합니다. 제외 디렉토리는 세지 않습니다.
+func (m *Monitor) SetBurstThresholds(t BurstThresholds) error {
+	if t.Window < 0 || t.Global < 0 || t.Directory < 0 || t.Extension < 0 {
+		return fmt.Errorf("잘못된 대량 변경 감지 기준: %+v", t)
+	}
+	if t.Window == 0 {
+		t.Window = defaultBurstWindow
+	}
+	m.bursts.reset(t)
+	if t.enabled() {
+		log.Printf("대량 변경 감지 설정됨: 윈도 %s, 전체 %d, 디렉토리 %d, 확장자 %d", t.Window, t.Global, t.Directory, t.Extension)
+	} else {
+		log.Printf("대량 변경 감지 사용 안 함")
+	}
+	return nil
+}
+
+// GetBurstThresholds는 현재 대량 파일 변경 감지 기준을 반환합니다.
+func (m *Monitor) GetBurstThresholds() BurstThresholds {
+	m.bursts.mu.Lock()
+	defer m.bursts.mu.Unlock()
+	return m.bursts.thresholds
+}
+
+// observeBurst는 원시 이벤트를 대량 변경 감지기에 전달하고, 임계값을 넘은 범위의 경고를 기록합니다.
+func (m *Monitor) observeBurst(event WatchEvent, now time.Time) {
+	op := burstOperation(event.Op)
+	if op == "" {
+		return
+	}
+	for _, alert := range m.bursts.observe(event.Name, op, now) {
+		m.recordAlert(alert)
+	}
+}
+
+// recordAlert는 경고에 ID를 부여하여 저장 대기 목록에 추가하고 구독자에게 전달합니다.
+func (m *Monitor) recordAlert(alert Alert) {
+	m.eventsMutex.Lock()
+	m.lastAlertID++
+	alert.ID = m.lastAlertID
+	m.alerts = append(m.alerts, alert)
+	m.eventsMutex.Unlock()
+
+	log.Printf("[경고] 대량 파일 변경 감지됨: %s", alert)
+
+	m.publish(FileEvent{
+		Path:      alert.Key,
+		Operation: OperationAlert,
+		Timestamp: alert.Timestamp,
+		Alert:     &alert,
+	})
+}
+
+// burstOperation은 원시 이벤트 작업 중 대량 변경 감지에서 세는 작업의 이름을 반환합니다.
+// 여러 작업이 합쳐진 이벤트는 handleEvent와 같은 우선순위로 하나만 셉니다.
+func burstOperation(op WatchOp) string {
+	switch {
+	case op.Has(WatchRename):
+		return OperationRename
+	case op.Has(WatchCreate):
+		return OperationCreate
+	case op.Has(WatchRemove):
+		return OperationRemove
+	case op.Has(WatchWrite):
+		return OperationWrite
+	}
+	return ""
+}
+
+// burstKey는 변경 수를 따로 세는 범위입니다.
+type burstKey struct {
+	scope string
+	key   string
+}
+
+// burstEntry는 윈도 안의 변경 하나입니다.
+type burstEntry struct {
+	at   time.Time
+	op   string
+	path string
+}
+
+// burstWindow는 한 범위의 윈도 안에 있는 변경 목록입니다.
+// 경고를 보낸 뒤에는 변경 수가 임계값의 절반 아래로 내려갈 때까지 다시 경고하지 않습니다.
+type burstWindow struct {
+	threshold int
+	entries   []burstEntry
+	alerted   bool
+}
+
+// trim은 cutoff보다 오래된 변경을 버리고, 충분히 줄었으면 다시 경고할 수 있게 합니다.
+func (w *burstWindow) trim(cutoff time.Time) {
+	i := 0
+	for i < len(w.entries) && w.entries[i].at.Before(cutoff) {
+		i++
+	}
+	w.entries = w.entries[i:]
+	if w.alerted && len(w.entries) < (w.threshold+1)/2 {
+		w.alerted = false
+	}
+}
+
+// alert는 현재 윈도의 변경 목록으로 경고를 만듭니다.
+func (w *burstWindow) alert(k burstKey, window time.Duration, now time.Time) Alert {
+	alert := Alert{
+		Timestamp:  now,
+		Scope:      k.scope,
+		Key:        k.key,
+		Count:      len(w.entries),
+		Threshold:  w.threshold,
+		Window:     window,
+		FirstSeen:  w.entries[0].at,
+		Operations: make(map[string]int),
+	}
+	dirs := make(map[string]bool)
+	for _, e := range w.entries {
+		alert.Operations[e.op]++
+		dirs[filepath.Dir(e.path)] = true
+	}
+	alert.Directories = len(dirs)
+
+	// 최근 경로부터 중복 없이
+	seen := make(map[string]bool)
+	for i := len(w.entries) - 1; i >= 0 && len(alert.Samples) < maxAlertSamples; i-- {
+		path := w.entries[i].path
+		if !seen[path] {
+			seen[path] = true
+			alert.Samples = append(alert.Samples, path)
+		}
+	}
+	return alert
+}
+
+// burstDetector는 범위별 슬라이딩 윈도로 변경 수를 세어 임계값을 넘은 범위를 찾습니다.
+// 변경은 processEvents 고루틴에서 전달되지만, 기준은 모니터링 중에도 바뀔 수 있으므로 잠금으로 보호합니다.
+type burstDetector struct {
+	mu         sync.Mutex
+	thresholds BurstThresholds
+	windows    map[burstKey]*burstWindow
+	// lastWrite는 경로별로 마지막으로 센 쓰기(또는 생성) 시간입니다.
+	// 파일 하나에 대한 연속된 쓰기는 윈도마다 한 번만 셉니다.
+	lastWrite map[string]time.Time
+}
+
+func newBurstDetector() burstDetector {
+	return burstDetector{
+		thresholds: BurstThresholds{Window: defaultBurstWindow},
+		windows:    make(map[burstKey]*burstWindow),
+		lastWrite:  make(map[string]time.Time),
+	}
+}
+
+// reset은 기준을 교체하고 지금까지 센 변경을 모두 버립니다.
+func (d *burstDetector) reset(t BurstThresholds) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.thresholds = t
+	d.windows = make(map[burstKey]*burstWindow)
+	d.lastWrite = make(map[string]time.Time)
+}
+
+// observe는 변경 하나를 범위별 윈도에 추가하고, 이번 변경으로 임계값에 도달한 범위의 경고를 반환합니다.
+func (d *burstDetector) observe(path, op string, now time.Time) []Alert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t := d.thresholds
+	if !t.enabled() {
+		return nil
+	}
+	switch op {
+	case OperationWrite:
+		if last, ok := d.lastWrite[path]; ok && now.Sub(last) < t.Window {
+			return nil
+		}
+		d.lastWrite[path] = now
+	case OperationCreate:
+		// 새 파일에 내용을 쓰는 것은 생성과 함께 한 번으로 셈
+		d.lastWrite[path] = now
+	}
+
+	entry := burstEntry{at: now, op: op, path: path}
+	cutoff := now.Add(-t.Window)
+
+	var alerts []Alert
+	add := func(k burstKey, threshold int) {
+		if threshold <= 0 {
+			return
+		}
+		w, ok := d.windows[k]
+		if !ok {
+			w = &burstWindow{threshold: threshold}
+			d.windows[k] = w
+		}
+		w.entries = append(w.entries, entry)
+		w.trim(cutoff)
+		if !w.alerted && len(w.entries) >= threshold {
+			w.alerted = true
+			alerts = append(alerts, w.alert(k, t.Window, now))
+		}
+	}
+
+	add(burstKey{scope: AlertScopeGlobal}, t.Global)
+	add(burstKey{scope: AlertScopeDirectory, key: filepath.Dir(path)}, t.Directory)
+	if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+		add(burstKey{scope: AlertScopeExtension, key: ext}, t.Extension)
+	}
+	return alerts
+}
+
+// expire는 윈도를 벗어난 변경을 버리고 빈 범위를 정리합니다.
+func (d *burstDetector) expire(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cutoff := now.Add(-d.thresholds.Window)
+	for k, w := range d.windows {
+		w.trim(cutoff)
+		if len(w.entries) == 0 {
+			delete(d.windows, k)
+		}
+	}
+	for path, last := range d.lastWrite {
+		if last.Before(cutoff) {
+			delete(d.lastWrite, path)
+		}
+	}
+}
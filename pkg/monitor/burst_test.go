@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBurstDetectorWindow(t *testing.T) {
+	d := newBurstDetector()
+	d.reset(BurstThresholds{Window: time.Second, Directory: 4})
+
+	base := time.Now()
+	dir := filepath.Join("data", "docs")
+	var alerts []Alert
+	for i := 0; i < 6; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("f%d.txt", i))
+		alerts = append(alerts, d.observe(path, OperationRemove, base.Add(time.Duration(i)*time.Millisecond))...)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected one alert while above threshold, got %d: %v", len(alerts), alerts)
+	}
+	a := alerts[0]
+	if a.Scope != AlertScopeDirectory || a.Key != dir || a.Count != 4 || a.Threshold != 4 {
+		t.Errorf("Unexpected alert: %+v", a)
+	}
+	if a.Operations[OperationRemove] != 4 || a.Directories != 1 || len(a.Samples) != 4 || a.Samples[0] != filepath.Join(dir, "f3.txt") {
+		t.Errorf("Unexpected alert details: %+v", a)
+	}
+
+	// 연속된 쓰기는 윈도마다 한 번만 셈
+	later := base.Add(2 * time.Second)
+	d.expire(later)
+	if len(d.windows) != 0 {
+		t.Errorf("Expected expired windows to be removed, got %d", len(d.windows))
+	}
+	for i := 0; i < 10; i++ {
+		alerts = d.observe(filepath.Join(dir, "big.txt"), OperationWrite, later.Add(time.Duration(i)*time.Millisecond))
+		if len(alerts) != 0 {
+			t.Fatalf("Expected repeated writes to one file to count once, got %v", alerts)
+		}
+	}
+
+	// 변경 수가 줄어든 뒤에는 다시 경고 (생성 직후의 쓰기는 세지 않음)
+	alerts = nil
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("g%d.txt", i))
+		at := later.Add(time.Duration(20+i) * time.Millisecond)
+		alerts = append(alerts, d.observe(path, OperationCreate, at)...)
+		alerts = append(alerts, d.observe(path, OperationWrite, at)...)
+	}
+	if len(alerts) != 1 || alerts[0].Operations[OperationWrite] != 1 || alerts[0].Operations[OperationCreate] != 3 {
+		t.Errorf("Expected a second alert after the window drained, got %v", alerts)
+	}
+}
+
+func TestBurstAlert(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetBurstThresholds(BurstThresholds{Window: time.Minute, Global: 1000, Directory: 20, Extension: 15}); err != nil {
+			t.Fatalf("SetBurstThresholds failed: %v", err)
+		}
+	})
+	dbPath := mon.dbPath
+
+	sub, err := mon.Subscribe(SubscribeOptions{Filter: func(e FileEvent) bool { return e.Operation == OperationAlert }})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// 필터 대상이 아닌 문서 파일도 셈
+	docs := filepath.Join(dir, "docs")
+	for i := 0; i < 25; i++ {
+		path := filepath.Join(docs, fmt.Sprintf("report%d.docx", i))
+		fake.Push(WatchEvent{Name: path, Op: WatchRemove})
+	}
+
+	var alerts []*Alert
+	timeout := time.After(5 * time.Second)
+	for len(alerts) < 2 {
+		select {
+		case e := <-sub.Events():
+			if e.Alert == nil || e.ID != 0 {
+				t.Fatalf("Unexpected alert event: %+v", e)
+			}
+			alerts = append(alerts, e.Alert)
+		case <-timeout:
+			t.Fatalf("Expected 2 alerts, got %d", len(alerts))
+		}
+	}
+	if alerts[0].Scope != AlertScopeExtension || alerts[0].Key != ".docx" || alerts[0].Count != 15 {
+		t.Errorf("Unexpected extension alert: %+v", alerts[0])
+	}
+	if alerts[1].Scope != AlertScopeDirectory || alerts[1].Key != docs || alerts[1].Count != 20 {
+		t.Errorf("Unexpected directory alert: %+v", alerts[1])
+	}
+	if alerts[0].ID != 1 || alerts[1].ID != 2 {
+		t.Errorf("Expected alert IDs 1 and 2, got %d and %d", alerts[0].ID, alerts[1].ID)
+	}
+
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	saved, err := db.GetAlerts()
+	if err != nil {
+		t.Fatalf("GetAlerts failed: %v", err)
+	}
+	if len(saved) != 2 {
+		t.Fatalf("Expected 2 saved alerts, got %d: %v", len(saved), saved)
+	}
+	got := saved[1]
+	if got.Scope != AlertScopeDirectory || got.Key != docs || got.Count != 20 || got.Threshold != 20 ||
+		got.Window != time.Minute || got.Operations[OperationRemove] != 20 || len(got.Samples) != maxAlertSamples {
+		t.Errorf("Unexpected saved alert: %+v", got)
+	}
+	events, err := db.GetFileEvents()
+	if err != nil {
+		t.Fatalf("GetFileEvents failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no file events for filtered documents, got %v", events)
+	}
+}
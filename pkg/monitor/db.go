@@ -2,15 +2,50 @@ package monitor
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// fileEventColumns는 FileEvent와 대응되는 file_events 컬럼 목록입니다.
+// 조회 시 scanFileEvents와 같은 순서를 유지해야 합니다.
+const fileEventColumns = "timestamp, path, operation, file_type, old_path, new_path, " +
+	"first_write, last_write, write_count, detected_type, reconciled, " +
+	"size, mode, uid, gid, owner, group_name, mtime, ctime, inode, nlink, " +
+	"pid, exe, process_uid"
+
+// selectFileEventColumns는 조회 시 이벤트 ID를 포함한 컬럼 목록입니다.
+const selectFileEventColumns = "id, " + fileEventColumns
+
+// insertFileEventSQL은 ID를 지정하여 이벤트를 삽입합니다. ID가 NULL이면 자동으로 부여됩니다.
+const insertFileEventSQL = `
+    INSERT INTO file_events (` + selectFileEventColumns + `)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`
+
+const insertEventMetadataSQL = `
+    INSERT OR REPLACE INTO event_metadata (event_id, key, value)
+    VALUES (?, ?, ?);
+`
+
+const updateDetectedTypeSQL = `
+    UPDATE file_events SET detected_type = ? WHERE id = ? AND detected_type = '';
+`
+
+const insertFileHashSQL = `
+    INSERT OR REPLACE INTO file_hashes (event_id, path, size, md5, sha1, sha256, hashed_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?);
+`
+
+// timeLayout은 데이터베이스에 시간을 저장할 때 사용하는 형식입니다.
+const timeLayout = "2006-01-02 15:04:05"
+
 // Database는 모니터링 데이터를 저장하기 위한 데이터베이스 연결을 관리합니다.
 type Database struct {
 	db         *sql.DB
@@ -41,29 +76,17 @@ func NewDatabase(dbPath string) (*Database, error) {
 		return nil, err
 	}
 
-	log.Printf("테이블 생성 시도")
-	// 테이블 생성
-	_, err = db.Exec(`
-        CREATE TABLE IF NOT EXISTS file_events (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            timestamp DATETIME NOT NULL,
-            path TEXT NOT NULL UNIQUE,
-            operation TEXT NOT NULL,
-            file_type TEXT NOT NULL
-        );
-    `)
-	if err != nil {
-		log.Printf("테이블 생성 실패: %v", err)
+	// 스키마 마이그레이션
+	log.Printf("스키마 마이그레이션 확인")
+	if err := migrate(db); err != nil {
+		log.Printf("스키마 마이그레이션 실패: %v", err)
 		db.Close()
 		return nil, err
 	}
 
-	// 파일 이벤트 삽입 또는 업데이트 준비문 생성
+	// 파일 이벤트 삽입 준비문 생성
 	log.Printf("SQL 준비문 생성 시도")
-	insertFileStmt, err := db.Prepare(`
-        INSERT OR REPLACE INTO file_events (timestamp, path, operation, file_type)
-        VALUES (?, ?, ?, ?);
-    `)
+	insertFileStmt, err := db.Prepare(insertFileEventSQL)
 	if err != nil {
 		log.Printf("SQL 준비문 생성 실패: %v", err)
 		db.Close()
@@ -86,109 +109,659 @@ func (d *Database) Close() error {
 }
 
 // SaveFileEvent는 파일 이벤트를 데이터베이스에 저장합니다.
-// 같은 경로의 파일이 이미 존재하면 덮어씁니다.
 func (d *Database) SaveFileEvent(event FileEvent) error {
 	log.Printf("SaveFileEvent: %v", event)
-	_, err := d.insertStmt.Exec(
-		event.Timestamp.Format("2006-01-02 15:04:05"),
-		event.Path,
-		event.Operation,
-		event.FileType,
-	)
-	return err
+	if len(event.Metadata) == 0 {
+		_, err := d.insertStmt.Exec(fileEventArgs(event)...)
+		return err
+	}
+	// 메타데이터가 있으면 이벤트와 함께 하나의 트랜잭션으로 저장
+	return d.SaveBatchFileEvents([]FileEvent{event})
 }
 
 // SaveBatchFileEvents는 여러 파일 이벤트를 일괄적으로 저장합니다.
-// 같은 경로의 파일이 이미 존재하면 덮어씁니다.
 func (d *Database) SaveBatchFileEvents(events []FileEvent) error {
 	tx, err := d.db.Begin()
 	if err != nil {
 		return err
 	}
 
-	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO file_events (timestamp, path, operation, file_type)
-		VALUES (?, ?, ?, ?);
-	`)
+	stmt, err := tx.Prepare(insertFileEventSQL)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 	defer stmt.Close()
 
+	metaStmt, err := tx.Prepare(insertEventMetadataSQL)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer metaStmt.Close()
+
 	for _, event := range events {
-		_, err := stmt.Exec(
-			// 포맷 형식은 2006-01-02 15:04:05 형식으로 지정 이건 go 언어의 시간 포멧 지정 방식
-			event.Timestamp.Format("2006-01-02 15:04:05"),
-			event.Path,
-			event.Operation,
-			event.FileType,
-		)
+		result, err := stmt.Exec(fileEventArgs(event)...)
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
+		if len(event.Metadata) == 0 {
+			continue
+		}
+
+		eventID := event.ID
+		if eventID == 0 {
+			if eventID, err = result.LastInsertId(); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		for key, value := range event.Metadata {
+			if _, err := metaStmt.Exec(eventID, key, value); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
 	}
 
 	return tx.Commit()
 }
 
+// SaveFileHashes는 이벤트에 연결된 파일 해시를 일괄적으로 저장합니다.
+func (d *Database) SaveFileHashes(hashes []FileHashes) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(insertFileHashSQL)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, h := range hashes {
+		_, err := stmt.Exec(h.EventID, h.Path, h.Size, h.MD5, h.SHA1, h.SHA256, h.HashedAt.Format(timeLayout))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFileHashes는 지정된 이벤트 ID에 연결된 파일 해시를 조회합니다.
+// 해시가 계산되지 않은 이벤트이면 nil을 반환합니다.
+func (d *Database) GetFileHashes(eventID int64) (*FileHashes, error) {
+	rows, err := d.db.Query(`
+		SELECT `+fileHashColumns+`
+		FROM file_hashes
+		WHERE event_id = ?;
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := scanFileHashes(rows)
+	if err != nil || len(hashes) == 0 {
+		return nil, err
+	}
+	return &hashes[0], nil
+}
+
+// GetFileHashesByDigest는 MD5, SHA-1, SHA-256 중 하나가 일치하는 해시 기록을 조회합니다.
+// 위협 정보에서 얻은 해시로 파일이 나타난 이벤트를 찾을 때 사용합니다.
+func (d *Database) GetFileHashesByDigest(digest string) ([]FileHashes, error) {
+	digest = strings.ToLower(digest)
+	rows, err := d.db.Query(`
+		SELECT `+fileHashColumns+`
+		FROM file_hashes
+		WHERE sha256 = ? OR sha1 = ? OR md5 = ?
+		ORDER BY event_id DESC;
+	`, digest, digest, digest)
+	if err != nil {
+		return nil, err
+	}
+	return scanFileHashes(rows)
+}
+
+// LastEventID는 저장된 이벤트 중 가장 큰 ID를 반환합니다. 이벤트가 없으면 0입니다.
+func (d *Database) LastEventID() (int64, error) {
+	var id int64
+	err := d.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM file_events;`).Scan(&id)
+	return id, err
+}
+
+// SaveEventMetadata는 이벤트에 연결된 파일 분석 결과를 일괄적으로 저장합니다.
+// 판별한 유형은 유형 없이 저장된 이벤트의 detected_type에 기록합니다.
+func (d *Database) SaveEventMetadata(metadata []EventMetadata) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(insertEventMetadataSQL)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	typeStmt, err := tx.Prepare(updateDetectedTypeSQL)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer typeStmt.Close()
+
+	for _, m := range metadata {
+		if m.DetectedType != "" {
+			if _, err := typeStmt.Exec(m.DetectedType, m.EventID); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		for key, value := range m.Values {
+			if _, err := stmt.Exec(m.EventID, key, value); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetEventMetadata는 지정된 이벤트 ID에 저장된 메타데이터를 조회합니다.
+// 메타데이터가 없으면 빈 맵을 반환합니다.
+func (d *Database) GetEventMetadata(eventID int64) (map[string]string, error) {
+	rows, err := d.db.Query(`
+		SELECT key, value
+		FROM event_metadata
+		WHERE event_id = ?;
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		metadata[key] = value
+	}
+	return metadata, rows.Err()
+}
+
 // GetFileEventsByTimeRange는 지정된 시간 범위 내의 파일 이벤트를 조회합니다.
 func (d *Database) GetFileEventsByTimeRange(start, end time.Time) ([]FileEvent, error) {
 	rows, err := d.db.Query(`
-		SELECT timestamp, path, operation, file_type 
+		SELECT `+selectFileEventColumns+`
 		FROM file_events 
 		WHERE timestamp BETWEEN ? AND ?
-		ORDER BY timestamp DESC;
+		ORDER BY timestamp DESC, id DESC;
 	`,
-		start.Format("2006-01-02 15:04:05"),
-		end.Format("2006-01-02 15:04:05"),
+		start.Format(timeLayout),
+		end.Format(timeLayout),
 	)
 	if err != nil {
 		return nil, err
 	}
+	return scanFileEvents(rows)
+}
+
+// GetFileEvents는 저장된 모든 파일 이벤트를 조회합니다.
+func (d *Database) GetFileEvents() ([]FileEvent, error) {
+	rows, err := d.db.Query(`
+		SELECT ` + selectFileEventColumns + `
+		FROM file_events 
+		ORDER BY timestamp DESC, id DESC;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanFileEvents(rows)
+}
+
+// GetFileEventsByPath는 지정된 경로와 관련된 모든 이벤트를 조회합니다.
+// MOVE 이벤트의 이전 경로나 새 경로가 일치하는 경우도 포함됩니다.
+func (d *Database) GetFileEventsByPath(path string) ([]FileEvent, error) {
+	rows, err := d.db.Query(`
+		SELECT `+selectFileEventColumns+`
+		FROM file_events
+		WHERE path = ? OR old_path = ? OR new_path = ?
+		ORDER BY timestamp DESC, id DESC;
+	`, path, path, path)
+	if err != nil {
+		return nil, err
+	}
+	return scanFileEvents(rows)
+}
+
+// GetFileStates는 경로별 가장 최근 이벤트(현재 상태)를 조회합니다.
+// 이후의 MOVE 이벤트로 옮겨진 이전 경로는 포함되지 않습니다.
+func (d *Database) GetFileStates() ([]FileEvent, error) {
+	rows, err := d.db.Query(`
+		SELECT ` + selectFileEventColumns + `
+		FROM file_states
+		ORDER BY timestamp DESC, path;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanFileEvents(rows)
+}
+
+// SaveInventory는 인벤토리 전체를 교체하여 저장합니다.
+// devices는 인벤토리를 만든 장치 목록으로, 다음 시작 시 오프라인 변경을 비교할 대상입니다.
+func (d *Database) SaveInventory(entries []InventoryEntry, devices []string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM inventory"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM inventory_devices"); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO inventory (path, device, size, mod_time, sha256) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range entries {
+		if _, err := stmt.Exec(e.Path, e.Device, e.Size, e.ModTime.UnixNano(), e.SHA256); err != nil {
+			return fmt.Errorf("인벤토리 저장 실패 (%s): %v", e.Path, err)
+		}
+	}
+
+	savedAt := time.Now().Format(timeLayout)
+	for _, device := range devices {
+		if _, err := tx.Exec("INSERT INTO inventory_devices (device, saved_at) VALUES (?, ?)", device, savedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadInventory는 저장된 인벤토리와 인벤토리를 만든 장치 목록을 반환합니다.
+func (d *Database) LoadInventory() ([]InventoryEntry, []string, error) {
+	rows, err := d.db.Query("SELECT path, device, size, mod_time, sha256 FROM inventory ORDER BY path")
+	if err != nil {
+		return nil, nil, err
+	}
 	defer rows.Close()
 
-	var events []FileEvent
+	var entries []InventoryEntry
 	for rows.Next() {
-		var event FileEvent
-		var timeStr string
-		err := rows.Scan(&timeStr, &event.Path, &event.Operation, &event.FileType)
+		var e InventoryEntry
+		var modTime int64
+		if err := rows.Scan(&e.Path, &e.Device, &e.Size, &modTime, &e.SHA256); err != nil {
+			return nil, nil, err
+		}
+		e.ModTime = time.Unix(0, modTime)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	deviceRows, err := d.db.Query("SELECT device FROM inventory_devices ORDER BY device")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer deviceRows.Close()
+
+	var devices []string
+	for deviceRows.Next() {
+		var device string
+		if err := deviceRows.Scan(&device); err != nil {
+			return nil, nil, err
+		}
+		devices = append(devices, device)
+	}
+	return entries, devices, deviceRows.Err()
+}
+
+const alertColumns = "id, timestamp, scope, key, count, threshold, window_ms, first_seen, operations, directories, samples"
+
+// SaveAlerts는 대량 변경 경고를 저장합니다. 같은 ID의 경고가 있으면 교체합니다.
+func (d *Database) SaveAlerts(alerts []Alert) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("INSERT OR REPLACE INTO alerts (" + alertColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, a := range alerts {
+		operations, err := json.Marshal(a.Operations)
+		if err != nil {
+			return err
+		}
+		samples, err := json.Marshal(a.Samples)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.Exec(a.ID, a.Timestamp.Format(timeLayout), a.Scope, a.Key, a.Count, a.Threshold,
+			a.Window.Milliseconds(), formatOptionalTime(a.FirstSeen), string(operations), a.Directories, string(samples))
+		if err != nil {
+			return fmt.Errorf("경고 저장 실패 (%d): %v", a.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAlerts는 저장된 대량 변경 경고를 발생 순서대로 조회합니다.
+func (d *Database) GetAlerts() ([]Alert, error) {
+	rows, err := d.db.Query("SELECT " + alertColumns + " FROM alerts ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var a Alert
+		var timestamp, firstSeen, operations, samples string
+		var windowMS int64
+		err := rows.Scan(&a.ID, &timestamp, &a.Scope, &a.Key, &a.Count, &a.Threshold,
+			&windowMS, &firstSeen, &operations, &a.Directories, &samples)
 		if err != nil {
 			return nil, err
 		}
-		event.Timestamp, _ = time.Parse("2006-01-02 15:04:05", timeStr)
-		events = append(events, event)
+		a.Timestamp = parseOptionalTime(timestamp)
+		a.FirstSeen = parseOptionalTime(firstSeen)
+		a.Window = time.Duration(windowMS) * time.Millisecond
+		if err := json.Unmarshal([]byte(operations), &a.Operations); err != nil {
+			return nil, fmt.Errorf("경고 %d의 작업별 변경 수를 읽을 수 없습니다: %v", a.ID, err)
+		}
+		if err := json.Unmarshal([]byte(samples), &a.Samples); err != nil {
+			return nil, fmt.Errorf("경고 %d의 경로 목록을 읽을 수 없습니다: %v", a.ID, err)
+		}
+		alerts = append(alerts, a)
 	}
+	return alerts, rows.Err()
+}
 
-	return events, nil
+// LastAlertID는 저장된 경고 중 가장 큰 ID를 반환합니다. 경고가 없으면 0입니다.
+func (d *Database) LastAlertID() (int64, error) {
+	var id int64
+	err := d.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM alerts;`).Scan(&id)
+	return id, err
 }
 
-// GetFileEvents는 저장된 모든 파일 이벤트를 조회합니다.
-func (d *Database) GetFileEvents() ([]FileEvent, error) {
-	rows, err := d.db.Query(`
-		SELECT timestamp, path, operation, file_type 
-		FROM file_events 
-		ORDER BY timestamp DESC;
-	`)
+const signatureMatchColumns = "event_id, signature, description, path, strings, scanned_at"
+
+// SaveSignatureMatches는 이벤트에 연결된 시그니처 일치 결과를 저장합니다.
+// 같은 이벤트와 시그니처의 결과가 있으면 교체합니다.
+func (d *Database) SaveSignatureMatches(matches []SignatureMatch) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("INSERT OR REPLACE INTO signature_matches (" + signatureMatchColumns + ") VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, m := range matches {
+		strs, err := json.Marshal(m.Strings)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.Exec(m.EventID, m.Signature, m.Description, m.Path, string(strs), m.ScannedAt.Format(timeLayout))
+		if err != nil {
+			return fmt.Errorf("시그니처 일치 저장 실패 (%d, %s): %v", m.EventID, m.Signature, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSignatureMatches는 지정된 이벤트 ID에 연결된 시그니처 일치 결과를 시그니처 ID 순으로 조회합니다.
+func (d *Database) GetSignatureMatches(eventID int64) ([]SignatureMatch, error) {
+	rows, err := d.db.Query("SELECT "+signatureMatchColumns+" FROM signature_matches WHERE event_id = ? ORDER BY signature", eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []SignatureMatch
+	for rows.Next() {
+		var m SignatureMatch
+		var strs, scannedAt string
+		if err := rows.Scan(&m.EventID, &m.Signature, &m.Description, &m.Path, &strs, &scannedAt); err != nil {
+			return nil, err
+		}
+		m.ScannedAt = parseOptionalTime(scannedAt)
+		if err := json.Unmarshal([]byte(strs), &m.Strings); err != nil {
+			return nil, fmt.Errorf("시그니처 일치 %d의 문자열 목록을 읽을 수 없습니다: %v", m.EventID, err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+const quarantineColumns = "id, event_id, reason, original_path, quarantine_path, sha256, size, mode, " +
+	"uid, gid, owner, group_name, quarantined_at, status, released_at"
+
+// SaveQuarantineEntry는 격리 기록을 추가하고 할당된 ID를 entry.ID에 설정합니다.
+func (d *Database) SaveQuarantineEntry(entry *QuarantineEntry) error {
+	result, err := d.db.Exec(`
+		INSERT INTO quarantine (event_id, reason, original_path, quarantine_path, sha256, size, mode,
+			uid, gid, owner, group_name, quarantined_at, status, released_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`, entry.EventID, entry.Reason, entry.OriginalPath, entry.QuarantinePath, entry.SHA256, entry.Size,
+		int64(entry.Mode), entry.UID, entry.GID, entry.Owner, entry.Group,
+		entry.QuarantinedAt.Format(timeLayout), entry.Status, formatOptionalTime(entry.ReleasedAt))
+	if err != nil {
+		return err
+	}
+	entry.ID, err = result.LastInsertId()
+	return err
+}
+
+// UpdateQuarantineStatus는 격리 기록의 상태와 복원 또는 삭제 시간을 변경합니다.
+func (d *Database) UpdateQuarantineStatus(id int64, status string, releasedAt time.Time) error {
+	result, err := d.db.Exec(`UPDATE quarantine SET status = ?, released_at = ? WHERE id = ?;`,
+		status, formatOptionalTime(releasedAt), id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("격리 기록이 없습니다: %d", id)
+	}
+	return nil
+}
+
+// GetQuarantineEntry는 지정된 ID의 격리 기록을 조회합니다. 없으면 nil을 반환합니다.
+func (d *Database) GetQuarantineEntry(id int64) (*QuarantineEntry, error) {
+	entries, err := d.queryQuarantine("WHERE id = ?", id)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// GetQuarantineEntries는 격리 기록을 ID 순으로 조회합니다.
+// all이 false이면 현재 격리 중인(quarantined) 파일만 조회합니다.
+func (d *Database) GetQuarantineEntries(all bool) ([]QuarantineEntry, error) {
+	if all {
+		return d.queryQuarantine("")
+	}
+	return d.queryQuarantine("WHERE status = ?", QuarantineStatusActive)
+}
+
+// LatestQuarantineEntry는 같은 경로와 SHA-256을 가진 가장 최근의 격리 기록을 조회합니다. 없으면 nil을 반환합니다.
+func (d *Database) LatestQuarantineEntry(path, sha256 string) (*QuarantineEntry, error) {
+	entries, err := d.queryQuarantine("WHERE original_path = ? AND sha256 = ? ORDER BY id DESC LIMIT 1", path, sha256)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+func (d *Database) queryQuarantine(where string, args ...interface{}) ([]QuarantineEntry, error) {
+	if !strings.Contains(where, "ORDER BY") {
+		where += " ORDER BY id"
+	}
+	rows, err := d.db.Query("SELECT "+quarantineColumns+" FROM quarantine "+where, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var entries []QuarantineEntry
+	for rows.Next() {
+		var e QuarantineEntry
+		var mode int64
+		var quarantinedAt, releasedAt string
+		err := rows.Scan(&e.ID, &e.EventID, &e.Reason, &e.OriginalPath, &e.QuarantinePath, &e.SHA256, &e.Size, &mode,
+			&e.UID, &e.GID, &e.Owner, &e.Group, &quarantinedAt, &e.Status, &releasedAt)
+		if err != nil {
+			return nil, err
+		}
+		e.Mode = os.FileMode(mode)
+		e.QuarantinedAt = parseOptionalTime(quarantinedAt)
+		e.ReleasedAt = parseOptionalTime(releasedAt)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// fileEventArgs는 selectFileEventColumns 순서에 맞는 INSERT 인자를 반환합니다.
+func fileEventArgs(event FileEvent) []interface{} {
+	var id interface{}
+	if event.ID != 0 {
+		id = event.ID
+	}
+	// 파일 상태가 없는 이벤트는 상태 컬럼을 NULL로 저장
+	stat := make([]interface{}, 10)
+	if st := event.Stat; st != nil {
+		stat = []interface{}{
+			st.Size, uint32(st.Mode), st.UID, st.GID, st.Owner, st.Group,
+			formatOptionalTime(st.ModTime), formatOptionalTime(st.ChangeTime), int64(st.Inode), int64(st.Links),
+		}
+	}
+	process := make([]interface{}, 3)
+	if p := event.Process; p != nil {
+		process = []interface{}{p.PID, p.Exe, p.UID}
+	}
+	args := append([]interface{}{
+		id,
+		// 포맷 형식은 2006-01-02 15:04:05 형식으로 지정 이건 go 언어의 시간 포멧 지정 방식
+		event.Timestamp.Format(timeLayout),
+		event.Path,
+		event.Operation,
+		event.FileType,
+		event.OldPath,
+		event.NewPath,
+		formatOptionalTime(event.FirstWrite),
+		formatOptionalTime(event.LastWrite),
+		event.WriteCount,
+		event.DetectedType,
+		event.Reconciled,
+	}, stat...)
+	return append(args, process...)
+}
+
+// formatOptionalTime은 시간을 저장 형식으로 변환하며, 값이 없으면 빈 문자열을 반환합니다.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(timeLayout)
+}
+
+// parseOptionalTime은 formatOptionalTime으로 저장된 시간을 복원합니다.
+func parseOptionalTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, _ := time.Parse(timeLayout, s)
+	return t
+}
+
+// scanFileEvents는 selectFileEventColumns 조회 결과를 FileEvent 목록으로 변환하고 rows를 닫습니다.
+func scanFileEvents(rows *sql.Rows) ([]FileEvent, error) {
+	defer rows.Close()
+
 	var events []FileEvent
 	for rows.Next() {
 		var event FileEvent
-		var timeStr string
-		err := rows.Scan(&timeStr, &event.Path, &event.Operation, &event.FileType)
+		var timeStr, firstWrite, lastWrite string
+		var size, mode, uid, gid, inode, nlink sql.NullInt64
+		var owner, group, mtime, ctime sql.NullString
+		var pid, processUID sql.NullInt64
+		var exe sql.NullString
+		err := rows.Scan(
+			&event.ID, &timeStr, &event.Path, &event.Operation, &event.FileType, &event.OldPath, &event.NewPath,
+			&firstWrite, &lastWrite, &event.WriteCount, &event.DetectedType, &event.Reconciled,
+			&size, &mode, &uid, &gid, &owner, &group, &mtime, &ctime, &inode, &nlink,
+			&pid, &exe, &processUID,
+		)
 		if err != nil {
 			return nil, err
 		}
-		event.Timestamp, _ = time.Parse("2006-01-02 15:04:05", timeStr)
+		event.Timestamp, _ = time.Parse(timeLayout, timeStr)
+		event.FirstWrite = parseOptionalTime(firstWrite)
+		event.LastWrite = parseOptionalTime(lastWrite)
+		if size.Valid {
+			event.Stat = &FileStat{
+				Size:       size.Int64,
+				Mode:       os.FileMode(mode.Int64),
+				UID:        int(uid.Int64),
+				GID:        int(gid.Int64),
+				Owner:      owner.String,
+				Group:      group.String,
+				ModTime:    parseOptionalTime(mtime.String),
+				ChangeTime: parseOptionalTime(ctime.String),
+				Inode:      uint64(inode.Int64),
+				Links:      uint64(nlink.Int64),
+				Executable: isExecutable(event.Path, os.FileMode(mode.Int64)),
+			}
+		}
+		if pid.Valid {
+			event.Process = &ProcessInfo{PID: int(pid.Int64), Exe: exe.String, UID: int(processUID.Int64)}
+		}
 		events = append(events, event)
 	}
 
-	return events, nil
+	return events, rows.Err()
+}
+
+// fileHashColumns는 scanFileHashes와 같은 순서의 file_hashes 컬럼 목록입니다.
+const fileHashColumns = "event_id, path, size, md5, sha1, sha256, hashed_at"
+
+// scanFileHashes는 조회 결과를 FileHashes 목록으로 변환하고 rows를 닫습니다.
+func scanFileHashes(rows *sql.Rows) ([]FileHashes, error) {
+	defer rows.Close()
+
+	var hashes []FileHashes
+	for rows.Next() {
+		var h FileHashes
+		var hashedAt string
+		if err := rows.Scan(&h.EventID, &h.Path, &h.Size, &h.MD5, &h.SHA1, &h.SHA256, &hashedAt); err != nil {
+			return nil, err
+		}
+		h.HashedAt = parseOptionalTime(hashedAt)
+		hashes = append(hashes, h)
+	}
+	return hashes, rows.Err()
 }
 
 // createDirIfNotExists 함수 수정
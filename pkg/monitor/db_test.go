@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDatabaseKeepsEventHistory(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	events := []FileEvent{
+		{Path: "C:\\a.exe", Operation: "CREATE", Timestamp: now, FileType: ".exe"},
+		{Path: "C:\\a.exe", Operation: "REMOVE", Timestamp: now.Add(time.Second), FileType: ".exe"},
+		{Path: "C:\\b.dll", Operation: "CREATE", Timestamp: now.Add(2 * time.Second), FileType: ".dll"},
+	}
+	if err := db.SaveBatchFileEvents(events); err != nil {
+		t.Fatalf("SaveBatchFileEvents failed: %v", err)
+	}
+
+	all, err := db.GetFileEvents()
+	if err != nil {
+		t.Fatalf("GetFileEvents failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 events in history, got %d", len(all))
+	}
+
+	states, err := db.GetFileStates()
+	if err != nil {
+		t.Fatalf("GetFileStates failed: %v", err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("Expected 2 file states, got %d", len(states))
+	}
+	for _, state := range states {
+		if state.Path == "C:\\a.exe" && state.Operation != "REMOVE" {
+			t.Errorf("Expected current state of a.exe to be REMOVE, got %s", state.Operation)
+		}
+	}
+}
+
+func TestDatabaseMigratesLegacySchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = legacy.Exec(`
+		CREATE TABLE file_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp DATETIME NOT NULL,
+			path TEXT NOT NULL UNIQUE,
+			operation TEXT NOT NULL,
+			file_type TEXT NOT NULL
+		);
+		INSERT INTO file_events (timestamp, path, operation, file_type)
+		VALUES ('2025-01-01 10:00:00', 'C:\old.exe', 'CREATE', '.exe');
+	`)
+	legacy.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	// 같은 경로의 이벤트를 추가해도 기존 행이 유지되어야 함
+	err = db.SaveFileEvent(FileEvent{Path: "C:\\old.exe", Operation: "REMOVE", Timestamp: time.Now(), FileType: ".exe"})
+	if err != nil {
+		t.Fatalf("SaveFileEvent failed: %v", err)
+	}
+
+	all, err := db.GetFileEvents()
+	if err != nil {
+		t.Fatalf("GetFileEvents failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 events after migration, got %d: %v", len(all), all)
+	}
+}
+
+func TestDatabaseRecordsSchemaVersion(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "version.db")
+
+	current, pending, err := PendingMigrations(dbPath)
+	if err != nil {
+		t.Fatalf("PendingMigrations failed: %v", err)
+	}
+	if current != 0 || len(pending) == 0 {
+		t.Fatalf("Expected all migrations pending for new database, got version %d, %d pending", current, len(pending))
+	}
+	latest := pending[len(pending)-1].Version
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	db.Close()
+
+	current, pending, err = PendingMigrations(dbPath)
+	if err != nil {
+		t.Fatalf("PendingMigrations failed: %v", err)
+	}
+	if current != latest || len(pending) != 0 {
+		t.Errorf("Expected version %d with no pending migrations, got version %d, %d pending", latest, current, len(pending))
+	}
+}
+
+func TestDatabaseRefusesNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "newer.db")
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	_, err = db.db.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (9999, 'future', '2099-01-01 00:00:00');`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if db, err := NewDatabase(dbPath); err == nil {
+		db.Close()
+		t.Fatal("Expected NewDatabase to refuse a newer schema version")
+	}
+}
+
+func TestDatabaseFileStatesAfterMove(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "states.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	events := []FileEvent{
+		{Path: "C:\\a.txt", Operation: "CREATE", Timestamp: now, FileType: ".txt"},
+		{Path: "C:\\a.exe", Operation: "MOVE", Timestamp: now.Add(time.Second), FileType: ".exe",
+			OldPath: "C:\\a.txt", NewPath: "C:\\a.exe"},
+	}
+	if err := db.SaveBatchFileEvents(events); err != nil {
+		t.Fatalf("SaveBatchFileEvents failed: %v", err)
+	}
+
+	// 옮겨진 이전 경로는 현재 상태에 나타나지 않음
+	states, err := db.GetFileStates()
+	if err != nil {
+		t.Fatalf("GetFileStates failed: %v", err)
+	}
+	if len(states) != 1 || states[0].Path != "C:\\a.exe" || states[0].Operation != "MOVE" {
+		t.Fatalf("Expected only the moved file in states, got %+v", states)
+	}
+
+	// 이동 뒤에 같은 경로에 다시 생긴 파일은 다시 나타남
+	recreated := FileEvent{Path: "C:\\a.txt", Operation: "CREATE", Timestamp: now.Add(2 * time.Second), FileType: ".txt"}
+	if err := db.SaveFileEvent(recreated); err != nil {
+		t.Fatalf("SaveFileEvent failed: %v", err)
+	}
+	states, err = db.GetFileStates()
+	if err != nil {
+		t.Fatalf("GetFileStates failed: %v", err)
+	}
+	if len(states) != 2 {
+		t.Errorf("Expected recreated file in states, got %+v", states)
+	}
+}
+
+func TestDatabaseStoresMoveEvents(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "move.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	move := FileEvent{
+		Path:      "C:\\payload.exe",
+		Operation: "MOVE",
+		Timestamp: time.Now(),
+		FileType:  ".exe",
+		OldPath:   "C:\\payload.txt",
+		NewPath:   "C:\\payload.exe",
+	}
+	if err := db.SaveFileEvent(move); err != nil {
+		t.Fatalf("SaveFileEvent failed: %v", err)
+	}
+
+	events, err := db.GetFileEventsByPath("C:\\payload.txt")
+	if err != nil {
+		t.Fatalf("GetFileEventsByPath failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event for old path, got %d", len(events))
+	}
+	if events[0].OldPath != move.OldPath || events[0].NewPath != move.NewPath {
+		t.Errorf("Unexpected stored MOVE event: %+v", events[0])
+	}
+}
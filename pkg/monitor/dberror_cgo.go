@@ -0,0 +1,16 @@
+//go:build cgo
+
+package monitor
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// isEventIDConflict는 저장하려는 이벤트의 ID가 이미 사용 중이어서 실패한 오류인지 확인합니다.
+// file_events에서 기본 키 제약을 가진 컬럼은 id뿐이므로 기본 키 제약 위반이면 ID 충돌입니다.
+func isEventIDConflict(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package monitor
+
+// isEventIDConflict는 cgo 없이 빌드하면 SQLite를 사용할 수 없으므로 항상 false를 반환합니다.
+// sqlite3.Error는 cgo 빌드에서만 정의됩니다.
+func isEventIDConflict(err error) bool {
+	return false
+}
@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// deviceWalk는 장치 루트를 재귀적으로 감시 등록하는 고루틴의 취소 함수와 종료 신호입니다.
+type deviceWalk struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// AddDevice는 모니터링할 장치(루트 디렉토리)를 추가합니다.
+// 모니터링 중에 호출하면 즉시 새 트리의 감시 등록을 시작합니다.
+func (m *Monitor) AddDevice(device string) error {
+	device = filepath.Clean(device)
+
+	m.runMutex.Lock()
+	defer m.runMutex.Unlock()
+
+	m.devicesMutex.Lock()
+	for _, d := range m.devices {
+		if d == device {
+			m.devicesMutex.Unlock()
+			return fmt.Errorf("이미 추가된 장치입니다: %s", device)
+		}
+	}
+	m.devices = append(m.devices, device)
+	m.devicesMutex.Unlock()
+	log.Printf("장치 추가됨: %s", device)
+
+	if m.running && !m.stopping {
+		m.startWalk(device)
+	}
+	return nil
+}
+
+// RemoveDevice는 모니터링 대상에서 장치를 제거합니다.
+// 모니터링 중이면 진행 중인 감시 등록을 중단하고, 해당 루트 아래의 감시를 모두 해제합니다.
+// 다른 장치에 포함된 디렉토리의 감시는 유지됩니다.
+func (m *Monitor) RemoveDevice(device string) error {
+	device = filepath.Clean(device)
+
+	m.runMutex.Lock()
+	m.devicesMutex.Lock()
+	index := -1
+	for i, d := range m.devices {
+		if d == device {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		m.devicesMutex.Unlock()
+		m.runMutex.Unlock()
+		return fmt.Errorf("등록되지 않은 장치입니다: %s", device)
+	}
+	m.devices = append(m.devices[:index:index], m.devices[index+1:]...)
+	m.devicesMutex.Unlock()
+
+	walk, ok := m.deviceWalks[device]
+	if ok {
+		walk.cancel()
+		delete(m.deviceWalks, device)
+	}
+	m.runMutex.Unlock()
+
+	// 탐색 중이던 고루틴이 끝난 뒤에 해제해야 이후에 감시가 다시 추가되지 않음.
+	// 탐색이 취소를 확인하기까지 걸리는 동안 다른 장치의 추가·제거와 중지를 막지 않도록 잠금 없이 기다림
+	if ok {
+		<-walk.done
+	}
+
+	m.runMutex.Lock()
+	defer m.runMutex.Unlock()
+	// 기다리는 동안 같은 장치가 다시 추가되었으면 감시와 인벤토리를 유지
+	if slices.Contains(m.GetDevices(), device) {
+		log.Printf("장치 제거됨: %s (다시 추가되어 감시 유지)", device)
+		return nil
+	}
+	m.forgetInventoryDevice(device)
+	if m.running && !m.stopping {
+		removed := m.unwatchTree(device)
+		log.Printf("장치 제거됨: %s (감시 해제 %d개 디렉토리)", device, removed)
+	} else {
+		log.Printf("장치 제거됨: %s", device)
+	}
+	return nil
+}
+
+// GetDevices는 현재 모니터링 중인 장치 목록을 반환합니다.
+func (m *Monitor) GetDevices() []string {
+	m.devicesMutex.RLock()
+	defer m.devicesMutex.RUnlock()
+	return append([]string(nil), m.devices...)
+}
+
+// startWalk는 장치 루트의 감시 등록 고루틴을 시작합니다. runMutex를 잡은 상태에서 호출해야 합니다.
+func (m *Monitor) startWalk(device string) {
+	ctx, cancel := context.WithCancel(m.runCtx)
+	walk := &deviceWalk{cancel: cancel, done: make(chan struct{})}
+	m.deviceWalks[device] = walk
+
+	m.walkers.Add(1)
+	go func() {
+		defer m.walkers.Done()
+		defer close(walk.done)
+		defer cancel()
+
+		log.Printf("%s 장치 모니터링 시작...\n", device)
+		// 조정에 필요한 파일 목록은 감시 등록과 같은 탐색에서 모음
+		scan := m.newInventoryScan(device)
+		var visit func(path string, d fs.DirEntry)
+		if scan != nil {
+			visit = scan.visit(m)
+		}
+		err := m.watchRecursive(ctx, device, visit)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("장치 %s 감시 설정 중 오류 발생: %v\n", device, err)
+			}
+			return
+		}
+
+		// 감시 등록이 끝난 뒤 중지된 동안의 변경을 조정
+		if scan != nil {
+			m.reconcileDevice(ctx, scan)
+		}
+	}()
+}
+
+// unwatchTree는 root 아래의 감시를 모두 해제하고 해제한 개수를 반환합니다.
+// 남아 있는 다른 장치에 포함된 디렉토리는 건너뜁니다.
+func (m *Monitor) unwatchTree(root string) int {
+	m.watchMutex.Lock()
+	defer m.watchMutex.Unlock()
+
+	removed := 0
+	for path := range m.watched {
+		if !isUnderPath(path, root) || m.isDevicePath(path) {
+			continue
+		}
+		// 이미 삭제된 디렉토리는 백엔드에서 자동으로 해제되었을 수 있으므로 오류는 무시
+		m.watcher.Remove(path)
+		delete(m.watched, path)
+		removed++
+	}
+	for path := range m.uncovered {
+		if isUnderPath(path, root) && !m.isDevicePath(path) {
+			delete(m.uncovered, path)
+		}
+	}
+	return removed
+}
+
+// isDevicePath는 경로가 등록된 장치 중 하나에 포함되는지 확인합니다.
+func (m *Monitor) isDevicePath(path string) bool {
+	m.devicesMutex.RLock()
+	defer m.devicesMutex.RUnlock()
+	for _, device := range m.devices {
+		if isUnderPath(path, device) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnderPath는 path가 root 자신이거나 그 하위 경로인지 확인합니다.
+func isUnderPath(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitWatched는 조건을 만족하는 감시 목록이 될 때까지 기다립니다.
+func waitWatched(t *testing.T, fake *FakeBackend, cond func([]string) bool) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		watched := fake.Watched()
+		if cond(watched) {
+			return watched
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for watch list, got %v", watched)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func countUnder(paths []string, root string) int {
+	n := 0
+	for _, p := range paths {
+		if p == root || strings.HasPrefix(p, root+string(filepath.Separator)) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAddRemoveDeviceAtRuntime(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	defer mon.Stop()
+
+	other := t.TempDir()
+	os.MkdirAll(filepath.Join(other, "a", "b"), 0755)
+
+	if err := mon.AddDevice(other); err != nil {
+		t.Fatalf("AddDevice failed: %v", err)
+	}
+	if err := mon.AddDevice(other); err == nil {
+		t.Error("Expected error when adding a device twice")
+	}
+	waitWatched(t, fake, func(w []string) bool { return countUnder(w, other) == 3 })
+	if devices := mon.GetDevices(); len(devices) != 2 || devices[1] != other {
+		t.Errorf("Expected new device in GetDevices, got %v", devices)
+	}
+
+	fake.Push(WatchEvent{Name: filepath.Join(other, "a", "x.exe"), Op: WatchCreate})
+	if events := waitEvents(t, mon, 1); events[0].Path != filepath.Join(other, "a", "x.exe") {
+		t.Errorf("Unexpected event from added device: %+v", events[0])
+	}
+
+	if err := mon.RemoveDevice(other); err != nil {
+		t.Fatalf("RemoveDevice failed: %v", err)
+	}
+	watched := fake.Watched()
+	if countUnder(watched, other) != 0 || countUnder(watched, dir) == 0 {
+		t.Errorf("Expected only watches under %s to be removed, got %v", other, watched)
+	}
+	if devices := mon.GetDevices(); len(devices) != 1 || devices[0] != dir {
+		t.Errorf("Expected removed device to disappear from GetDevices, got %v", devices)
+	}
+	if err := mon.RemoveDevice(other); err == nil {
+		t.Error("Expected error when removing an unknown device")
+	}
+
+	// 제거된 장치의 늦게 도착한 이벤트는 무시되고 남은 장치의 이벤트만 기록됨
+	fake.Push(WatchEvent{Name: filepath.Join(other, "late.exe"), Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "kept.exe"), Op: WatchCreate})
+	if events := waitEvents(t, mon, 1); events[0].Path != filepath.Join(dir, "kept.exe") {
+		t.Errorf("Expected event from removed device to be ignored, got %+v", events[0])
+	}
+}
+
+func TestRemoveNestedDeviceKeepsParentWatches(t *testing.T) {
+	nested := ""
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		nested = filepath.Join(m.GetDevices()[0], "sub")
+		os.MkdirAll(filepath.Join(nested, "deep"), 0755)
+	})
+	defer mon.Stop()
+
+	if err := mon.AddDevice(nested); err != nil {
+		t.Fatal(err)
+	}
+	waitWatched(t, fake, func(w []string) bool { return countUnder(w, dir) == 3 })
+
+	if err := mon.RemoveDevice(nested); err != nil {
+		t.Fatal(err)
+	}
+	// 상위 장치에 포함된 디렉토리는 계속 감시
+	if watched := fake.Watched(); countUnder(watched, nested) != 2 {
+		t.Errorf("Expected nested directories to stay watched by parent device, got %v", watched)
+	}
+}
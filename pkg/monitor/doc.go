@@ -7,7 +7,9 @@
 //
 //	mon := monitor.NewMonitor(5 * time.Second)
 //	mon.AddDevice("C:\\")
-//	mon.SetFileFilters([]string{".exe", ".dll"})
+//	if err := mon.SetFileFilters([]string{".exe", ".dll"}); err != nil {
+//		log.Fatal(err)
+//	}
 //	err := mon.Start()
 //	// ... 종료 신호 대기 ...
 //	mon.Stop()
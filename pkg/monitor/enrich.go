@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+const (
+	defaultAnalyzeWorkers = 1
+	// defaultAnalyzeQueue는 파일 분석 대기열 크기로, 가득 차면 이벤트는 감지 유형과 메타데이터 없이 기록됩니다.
+	defaultAnalyzeQueue = 256
+)
+
+// EventMetadata는 이벤트 발생 후 작업자가 파일을 분석한 결과입니다.
+// EventID는 분석을 요청한 FileEvent의 ID이며, Values는 event_metadata 테이블에 저장됩니다.
+// DetectedType은 파일이 안정된 뒤의 내용으로 판별한 유형으로, 이벤트에 유형이 없었던 경우에만 채워지며
+// 저장 시 이벤트의 detected_type에 기록됩니다.
+type EventMetadata struct {
+	EventID      int64
+	Path         string
+	DetectedType string
+	Values       map[string]string
+}
+
+// enrichEvent는 기록하기 전에 이벤트에 파일 상태를 덧붙입니다.
+// 새로 생기거나 이동·수정·실행된 파일은 상태(크기, 권한, 소유자 등)를 조회합니다.
+// 유형 판별과 PE 헤더 분석은 파일이 안정된 뒤에 읽어야 하므로 이벤트 처리를 멈추지 않도록 analyzePool에서 따로 수행합니다.
+func (m *Monitor) enrichEvent(event *FileEvent) {
+	switch event.Operation {
+	case OperationCreate, OperationMove, OperationModified, OperationExec:
+		event.Stat = statFile(event.Path)
+	}
+}
+
+// analyzeJob은 분석할 이벤트의 ID와 파일 경로입니다.
+// detect이면 유형을 판별하여 전달하고, parsePE이면 PE 파일의 헤더를 분석합니다.
+type analyzeJob struct {
+	eventID int64
+	path    string
+	detect  bool
+	parsePE bool
+}
+
+// analyzePool은 이벤트 대상 파일의 유형을 판별하고 PE이면 헤더를 분석하는 작업자 풀입니다.
+// 이벤트 시점에는 파일이 아직 비어 있을 수 있으므로 파일이 안정된 뒤의 내용으로 판별하며,
+// 결과는 result로 전달되고 성공 여부와 관계없이 작업이 끝나면 finished가 호출됩니다.
+type analyzePool struct {
+	fileWorkers[analyzeJob]
+	result   func(EventMetadata)
+	finished func(eventID int64)
+}
+
+func newAnalyzePool() *analyzePool {
+	return &analyzePool{fileWorkers: newFileWorkers[analyzeJob](defaultAnalyzeWorkers, defaultAnalyzeQueue)}
+}
+
+// job은 이벤트에 필요한 분석 작업을 반환합니다. 분석할 것이 없으면 false를 반환합니다.
+// 새로 생기거나 이동·수정된 파일은 PE 헤더를, 유형을 모르는 이벤트의 파일은 유형을 분석합니다.
+func (p *analyzePool) job(event FileEvent) (analyzeJob, bool) {
+	job := analyzeJob{eventID: event.ID, path: event.Path}
+	switch event.Operation {
+	case OperationCreate, OperationMove, OperationModified:
+		job.parsePE = true
+		job.detect = event.DetectedType == ""
+	case OperationExec:
+		job.detect = event.DetectedType == ""
+	}
+	return job, job.detect || job.parsePE
+}
+
+// start는 작업자 고루틴을 시작합니다.
+func (p *analyzePool) start(result func(EventMetadata), finished func(eventID int64)) {
+	p.result = result
+	p.finished = finished
+	p.fileWorkers.start(p.run)
+}
+
+func (p *analyzePool) run(job analyzeJob) {
+	defer p.finished(job.eventID)
+
+	var metadata EventMetadata
+	err := p.attempt(func(final bool) (retry bool, err error) {
+		metadata, retry, err = p.tryAnalyze(job, final)
+		return retry, err
+	})
+	if err != nil {
+		log.Printf("파일 분석 실패: %s - %v", job.path, err)
+		return
+	}
+	if metadata.DetectedType == "" && len(metadata.Values) == 0 {
+		return
+	}
+	p.result(metadata)
+}
+
+// tryAnalyze는 분석을 한 번 시도합니다. retry는 다시 시도할 가치가 있는 오류인지를 나타냅니다.
+func (p *analyzePool) tryAnalyze(job analyzeJob, final bool) (metadata EventMetadata, retry bool, err error) {
+	metadata = EventMetadata{EventID: job.eventID, Path: job.path}
+	before, retry, err := p.stableStat(job.path, final)
+	if err != nil {
+		return metadata, retry, err
+	}
+	detected, err := SniffFile(job.path)
+	if err != nil {
+		return metadata, false, err
+	}
+	if job.detect {
+		metadata.DetectedType = detected
+	}
+	if job.parsePE && detected == ContentTypePE {
+		// 헤더가 손상된 PE도 판별한 유형은 기록
+		if info, err := ParsePE(job.path); err != nil {
+			log.Printf("PE 헤더 분석 실패: %s - %v", job.path, err)
+		} else {
+			metadata.Values = info.Metadata()
+		}
+	}
+
+	after, err := os.Stat(job.path)
+	if err != nil {
+		return metadata, !os.IsNotExist(err), err
+	}
+	if changedSince(before, after) {
+		return metadata, true, fmt.Errorf("분석 중 파일이 변경되었습니다")
+	}
+	return metadata, false, nil
+}
+
+// recordMetadata는 분석 결과를 다음 저장 시점까지 메모리에 보관하고, 판별한 유형은 WaitDetectedType으로 조회할 수 있게 합니다.
+func (m *Monitor) recordMetadata(metadata EventMetadata) {
+	if metadata.DetectedType != "" {
+		m.recentTypes.add(metadata.EventID, metadata.DetectedType)
+		log.Printf("파일 유형 판별 완료: %s (%s)", metadata.Path, metadata.DetectedType)
+	}
+	if len(metadata.Values) > 0 {
+		log.Printf("PE 헤더 분석 완료: %s (%s, %s, DLL=%s)", metadata.Path, metadata.Values["pe.machine"],
+			metadata.Values["pe.subsystem"], metadata.Values["pe.dll"])
+	}
+	m.eventsMutex.Lock()
+	metadata.EventID = m.remapEventID(metadata.EventID)
+	m.eventMetadata = append(m.eventMetadata, metadata)
+	m.eventsMutex.Unlock()
+}
+
+// WaitDetectedType은 이벤트에 유형이 없어 작업자가 파일이 안정된 뒤에 판별한 콘텐츠 유형을 반환합니다.
+// 판별 중이면 끝나거나 ctx가 취소될 때까지 기다립니다. 이벤트에 이미 유형이 있었거나, 알 수 없는 유형이거나,
+// 판별에 실패했거나, 최근 결과로 보관하지 않는 오래된 이벤트이면 false를 반환합니다.
+func (m *Monitor) WaitDetectedType(ctx context.Context, eventID int64) (string, bool) {
+	return m.recentTypes.wait(ctx, eventID)
+}
+
+// formatOwner는 소유자를 "이름(UID):그룹(GID)" 형식으로 반환합니다.
+// UID가 없는 Windows에서는 "소유자:그룹" 계정 이름이며, 소유자 정보가 없으면 "-"입니다.
+func formatOwner(st *FileStat) string {
+	if st.UID < 0 {
+		if st.Owner == "" {
+			return "-"
+		}
+		if st.Group == "" {
+			return st.Owner
+		}
+		return st.Owner + ":" + st.Group
+	}
+	format := func(name string, id int) string {
+		if name == "" {
+			return strconv.Itoa(id)
+		}
+		return fmt.Sprintf("%s(%d)", name, id)
+	}
+	return format(st.Owner, st.UID) + ":" + format(st.Group, st.GID)
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"log"
+	"strings"
+)
+
+// dirExclusion은 재귀 감시 등록 시 건너뛸 디렉토리 패턴입니다.
+// "/"가 없는 글롭은 디렉토리 이름에, "/"가 있는 글롭과 "re:" 정규식은 전체 경로에 적용됩니다.
+type dirExclusion struct {
+	pattern  pathPattern
+	baseOnly bool
+}
+
+// DefaultExcludeDirs는 현재 플랫폼의 기본 감시 제외 디렉토리 패턴 목록을 반환합니다.
+func DefaultExcludeDirs() []string {
+	return append([]string(nil), defaultExcludeDirs...)
+}
+
+// compileDirExclusions는 디렉토리 제외 패턴 목록을 컴파일합니다.
+func compileDirExclusions(patterns []string) ([]dirExclusion, error) {
+	var exclusions []dirExclusion
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		p, err := compilePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		baseOnly := p.re == nil && !strings.Contains(p.glob, "/")
+		exclusions = append(exclusions, dirExclusion{pattern: p, baseOnly: baseOnly})
+	}
+	return exclusions, nil
+}
+
+// SetExcludeDirs는 재귀 감시 등록 시 건너뛸 디렉토리 패턴을 설정합니다.
+// 기본값은 DefaultExcludeDirs이며, 빈 목록을 설정하면 모든 디렉토리를 감시합니다.
+// 장치 루트로 직접 지정한 디렉토리는 제외되지 않습니다.
+func (m *Monitor) SetExcludeDirs(patterns []string) error {
+	err := m.updateFilters(func(cfg *FilterConfig) { cfg.ExcludeDirs = patterns })
+	if err != nil {
+		return err
+	}
+	log.Printf("감시 제외 디렉토리 설정됨: %v", m.GetExcludeDirs())
+	return nil
+}
+
+// GetExcludeDirs는 현재 설정된 감시 제외 디렉토리 패턴 목록을 반환합니다.
+func (m *Monitor) GetExcludeDirs() []string {
+	return m.filters().config().ExcludeDirs
+}
+
+// isExcludedDir은 디렉토리가 현재 설정된 감시 제외 패턴과 일치하는지 확인합니다.
+func (m *Monitor) isExcludedDir(dir string) bool {
+	return m.filters().isExcludedDir(dir)
+}
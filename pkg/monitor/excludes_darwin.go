@@ -0,0 +1,16 @@
+package monitor
+
+// defaultExcludeDirs는 macOS에서 기본으로 감시하지 않는 디렉토리 패턴입니다.
+var defaultExcludeDirs = []string{
+	"/System/Volumes",
+	"/private/var/vm",
+	".Spotlight-V100",
+	".fseventsd",
+	".Trashes",
+	"**/Library/Caches",
+	"node_modules",
+	".git",
+	".svn",
+	".hg",
+	"__pycache__",
+}
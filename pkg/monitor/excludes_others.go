@@ -0,0 +1,18 @@
+//go:build !windows && !darwin
+
+package monitor
+
+// defaultExcludeDirs는 Linux 등 기타 플랫폼에서 기본으로 감시하지 않는 디렉토리 패턴입니다.
+// 가상 파일 시스템과 사용자 캐시, 개발 도구 디렉토리가 포함됩니다.
+var defaultExcludeDirs = []string{
+	"/proc",
+	"/sys",
+	"/dev",
+	"/run",
+	"**/.cache",
+	"node_modules",
+	".git",
+	".svn",
+	".hg",
+	"__pycache__",
+}
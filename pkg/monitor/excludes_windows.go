@@ -0,0 +1,15 @@
+package monitor
+
+// defaultExcludeDirs는 Windows에서 기본으로 감시하지 않는 디렉토리 패턴입니다.
+// 휴지통, 시스템 복원 지점, 구성 요소 저장소와 개발 도구 캐시 등 변경이 잦고 분석 가치가 낮은 경로입니다.
+var defaultExcludeDirs = []string{
+	"$Recycle.Bin",
+	"System Volume Information",
+	"WinSxS",
+	"INetCache",
+	"node_modules",
+	".git",
+	".svn",
+	".hg",
+	"__pycache__",
+}
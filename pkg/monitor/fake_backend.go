@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// FakeBackend는 테스트용 메모리 기반 감시 백엔드입니다.
+// 실제 파일 시스템 대신 Push로 전달한 가상 이벤트를 Monitor에 공급합니다.
+type FakeBackend struct {
+	mu      sync.Mutex
+	watched map[string]bool
+	events  chan WatchEvent
+	errors  chan error
+	done    chan struct{}  // Close 시 닫혀 대기 중인 Push를 깨움
+	senders sync.WaitGroup // 채널에 보내는 중인 Push (끝난 뒤에 채널을 닫음)
+	closed  bool
+	limit   int
+}
+
+// NewFakeBackend는 새로운 테스트용 감시 백엔드를 생성합니다.
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		watched: make(map[string]bool),
+		events:  make(chan WatchEvent, 100),
+		errors:  make(chan error, 10),
+		done:    make(chan struct{}),
+	}
+}
+
+// Push는 가상 파일 시스템 이벤트를 전달합니다. 닫힌 백엔드에서는 무시됩니다.
+// 버퍼가 가득 차면 Monitor가 읽을 때까지 기다리며, 기다리는 동안 잠금을 잡지 않으므로
+// Monitor가 이벤트 처리 중에 Add를 호출해도 교착되지 않습니다.
+func (b *FakeBackend) Push(event WatchEvent) {
+	if !b.beginSend() {
+		return
+	}
+	defer b.senders.Done()
+	select {
+	case b.events <- event:
+	case <-b.done:
+	}
+}
+
+// PushError는 가상 감시자 오류를 전달합니다. 닫힌 백엔드에서는 무시됩니다.
+func (b *FakeBackend) PushError(err error) {
+	if !b.beginSend() {
+		return
+	}
+	defer b.senders.Done()
+	select {
+	case b.errors <- err:
+	case <-b.done:
+	}
+}
+
+// beginSend는 백엔드가 닫히지 않았으면 보내는 중인 Push로 등록합니다.
+func (b *FakeBackend) beginSend() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.senders.Add(1)
+	return true
+}
+
+// SetWatchLimit은 동시에 감시할 수 있는 디렉토리 수를 제한합니다. 한도를 넘는 Add는
+// inotify와 같이 ENOSPC 오류를 반환합니다. 0이면 제한이 없습니다.
+func (b *FakeBackend) SetWatchLimit(limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limit = limit
+}
+
+// Watched는 현재 등록된 감시 경로 목록을 정렬하여 반환합니다.
+func (b *FakeBackend) Watched() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	paths := make([]string, 0, len(b.watched))
+	for path := range b.watched {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (b *FakeBackend) Add(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return fmt.Errorf("백엔드가 이미 닫혔습니다")
+	}
+	if b.limit > 0 && !b.watched[path] && len(b.watched) >= b.limit {
+		return syscall.ENOSPC
+	}
+	b.watched[path] = true
+	return nil
+}
+
+func (b *FakeBackend) Remove(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.watched[path] {
+		return fmt.Errorf("감시 중이 아닌 경로입니다: %s", path)
+	}
+	delete(b.watched, path)
+	return nil
+}
+
+func (b *FakeBackend) Events() <-chan WatchEvent { return b.events }
+func (b *FakeBackend) Errors() <-chan error      { return b.errors }
+
+// Close는 대기 중인 Push를 중단시키고 이벤트 채널을 닫습니다.
+func (b *FakeBackend) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.done)
+	b.mu.Unlock()
+
+	b.senders.Wait()
+	close(b.events)
+	close(b.errors)
+	return nil
+}
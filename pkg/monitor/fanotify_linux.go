@@ -0,0 +1,375 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// fanotifyMask는 파일 시스템 표시에 등록하는 이벤트입니다.
+const fanotifyMask = unix.FAN_CREATE | unix.FAN_DELETE | unix.FAN_MOVED_FROM | unix.FAN_MOVED_TO |
+	unix.FAN_MODIFY | unix.FAN_ATTRIB | unix.FAN_OPEN_EXEC | unix.FAN_ONDIR
+
+// fanotify 이벤트 구조의 크기와 위치 (linux/fanotify.h)
+const (
+	fanotifyMetadataSize = 24 // struct fanotify_event_metadata
+	fanotifyInfoHeader   = 4  // struct fanotify_event_info_header
+	fanotifyFsidSize     = 8  // __kernel_fsid_t
+	fileHandleHeader     = 8  // struct file_handle (handle_bytes, handle_type)
+)
+
+// fanotifyBackend는 Linux fanotify로 파일 시스템 전체를 표시하여 감시하는 백엔드입니다.
+// 디렉토리마다 감시를 등록하지 않으므로 inotify 감시 한도의 영향을 받지 않고,
+// 이벤트를 일으킨 프로세스(PID, 실행 파일, UID)와 파일 실행(FAN_OPEN_EXEC)을 보고합니다.
+// 같은 파일 시스템의 다른 경로 이벤트도 커널에서 전달되므로 등록된 루트 아래의 이벤트만 골라 전달합니다.
+type fanotifyBackend struct {
+	fd   int
+	file *os.File
+
+	mu    sync.Mutex
+	roots map[string]unix.Fsid        // 등록된 루트와 그 파일 시스템
+	marks map[unix.Fsid]*fanotifyMark // 파일 시스템별 표시
+
+	events    chan WatchEvent
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// fanotifyMark는 표시한 파일 시스템 하나입니다.
+type fanotifyMark struct {
+	path    string // 표시할 때 사용한 경로
+	mountFD int    // 파일 핸들을 경로로 바꿀 때 사용하는 파일 시스템 안의 디렉토리
+	refs    int    // 이 파일 시스템에 등록된 루트 수
+}
+
+// NewFanotifyBackend는 fanotify 기반 감시 백엔드를 생성합니다.
+// CAP_SYS_ADMIN 권한과 파일 이름 보고(FAN_REPORT_DFID_NAME)를 지원하는 Linux 5.9 이상이 필요하며,
+// 파일 핸들을 경로로 바꾸기 위해 CAP_DAC_READ_SEARCH도 필요합니다.
+func NewFanotifyBackend() (WatcherBackend, error) {
+	fd, err := unix.FanotifyInit(
+		unix.FAN_CLASS_NOTIF|unix.FAN_CLOEXEC|unix.FAN_NONBLOCK|unix.FAN_REPORT_DFID_NAME,
+		unix.O_RDONLY|unix.O_LARGEFILE|unix.O_CLOEXEC)
+	switch {
+	case errors.Is(err, unix.EPERM):
+		return nil, fmt.Errorf("fanotify 초기화 실패: CAP_SYS_ADMIN 권한이 필요합니다 (%v)", err)
+	case errors.Is(err, unix.EINVAL):
+		return nil, fmt.Errorf("fanotify 초기화 실패: 커널이 파일 이름 보고를 지원하지 않습니다 (Linux 5.9 이상 필요, %v)", err)
+	case err != nil:
+		return nil, fmt.Errorf("fanotify 초기화 실패: %v", err)
+	}
+
+	b := &fanotifyBackend{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "fanotify"),
+		roots:  make(map[string]unix.Fsid),
+		marks:  make(map[unix.Fsid]*fanotifyMark),
+		events: make(chan WatchEvent),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	go b.run()
+	return b, nil
+}
+
+func (b *fanotifyBackend) recursive() bool { return true }
+
+// Add는 path가 속한 파일 시스템을 표시하고 path를 루트로 등록합니다.
+// 같은 파일 시스템은 한 번만 표시합니다.
+func (b *fanotifyBackend) Add(path string) error {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.roots[path]; ok {
+		return nil
+	}
+	if mark, ok := b.marks[stat.Fsid]; ok {
+		mark.refs++
+		b.roots[path] = stat.Fsid
+		return nil
+	}
+
+	mountFD, err := unix.Open(path, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	if err := unix.FanotifyMark(b.fd, unix.FAN_MARK_ADD|unix.FAN_MARK_FILESYSTEM, fanotifyMask, unix.AT_FDCWD, path); err != nil {
+		unix.Close(mountFD)
+		return fmt.Errorf("fanotify 표시 실패: %s - %w", path, err)
+	}
+	b.marks[stat.Fsid] = &fanotifyMark{path: path, mountFD: mountFD, refs: 1}
+	b.roots[path] = stat.Fsid
+	return nil
+}
+
+// Remove는 루트 등록을 해제하고, 파일 시스템에 남은 루트가 없으면 표시를 제거합니다.
+func (b *fanotifyBackend) Remove(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fsid, ok := b.roots[path]
+	if !ok {
+		return fmt.Errorf("감시 중이 아닌 디렉토리입니다: %s", path)
+	}
+	delete(b.roots, path)
+
+	mark := b.marks[fsid]
+	if mark.refs--; mark.refs > 0 {
+		return nil
+	}
+	delete(b.marks, fsid)
+	unix.Close(mark.mountFD)
+	return unix.FanotifyMark(b.fd, unix.FAN_MARK_REMOVE|unix.FAN_MARK_FILESYSTEM, fanotifyMask, unix.AT_FDCWD, mark.path)
+}
+
+func (b *fanotifyBackend) Events() <-chan WatchEvent { return b.events }
+func (b *fanotifyBackend) Errors() <-chan error      { return b.errors }
+
+// Close는 fanotify를 닫고 이벤트 전달을 중지합니다.
+func (b *fanotifyBackend) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		err = b.file.Close()
+
+		b.mu.Lock()
+		for _, mark := range b.marks {
+			unix.Close(mark.mountFD)
+		}
+		b.marks = make(map[unix.Fsid]*fanotifyMark)
+		b.mu.Unlock()
+	})
+	return err
+}
+
+// run은 fanotify 이벤트를 읽어 WatchEvent로 변환하여 전달합니다.
+func (b *fanotifyBackend) run() {
+	defer close(b.events)
+	defer close(b.errors)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			select {
+			case <-b.done:
+			default:
+				b.sendError(fmt.Errorf("fanotify 읽기 실패: %w", err))
+			}
+			return
+		}
+		if !b.parse(buf[:n]) {
+			return
+		}
+	}
+}
+
+// parse는 읽은 이벤트들을 변환하여 전달합니다. 중지되었으면 false를 반환합니다.
+func (b *fanotifyBackend) parse(buf []byte) bool {
+	for len(buf) >= fanotifyMetadataSize {
+		eventLen := binary.NativeEndian.Uint32(buf[0:])
+		version := buf[4]
+		metadataLen := binary.NativeEndian.Uint16(buf[6:])
+		mask := binary.NativeEndian.Uint64(buf[8:])
+		fd := int32(binary.NativeEndian.Uint32(buf[16:]))
+		pid := int32(binary.NativeEndian.Uint32(buf[20:]))
+		if eventLen < fanotifyMetadataSize || int(eventLen) > len(buf) || int(metadataLen) > int(eventLen) {
+			return b.sendError(fmt.Errorf("fanotify 이벤트 길이가 잘못되었습니다: %d", eventLen))
+		}
+		if version != unix.FANOTIFY_METADATA_VERSION {
+			return b.sendError(fmt.Errorf("지원하지 않는 fanotify 이벤트 버전: %d", version))
+		}
+		info := buf[metadataLen:eventLen]
+		buf = buf[eventLen:]
+
+		if fd >= 0 {
+			unix.Close(int(fd))
+		}
+		if mask&unix.FAN_Q_OVERFLOW != 0 {
+			if !b.sendError(fmt.Errorf("fanotify 이벤트 대기열이 넘쳐 일부 이벤트를 놓쳤습니다")) {
+				return false
+			}
+			continue
+		}
+
+		path, ok := b.resolvePath(info)
+		if !ok || !b.underRoot(path) {
+			continue
+		}
+		// 커널이 같은 파일의 연속된 이벤트를 하나로 합치므로, 일반적인 발생 순서대로 나누어 전달
+		process := lookupProcess(int(pid))
+		op := convertFanotifyMask(mask)
+		for _, o := range fanotifyOrder {
+			if !op.Has(o) {
+				continue
+			}
+			select {
+			case b.events <- WatchEvent{Name: path, Op: o, Process: process}:
+			case <-b.done:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fanotifyOrder는 합쳐진 이벤트를 나누어 전달하는 순서입니다.
+// 생성된 파일이 곧바로 이름이 바뀌거나 삭제되어도 생성이 먼저 기록되도록 RENAME과 REMOVE를 마지막에 둡니다.
+var fanotifyOrder = []WatchOp{WatchCreate, WatchWrite, WatchChmod, WatchExec, WatchRename, WatchRemove}
+
+// resolvePath는 이벤트 정보 레코드의 디렉토리 파일 핸들과 이름으로 경로를 만듭니다.
+func (b *fanotifyBackend) resolvePath(info []byte) (string, bool) {
+	for len(info) >= fanotifyInfoHeader {
+		infoType := info[0]
+		infoLen := int(binary.NativeEndian.Uint16(info[2:]))
+		if infoLen < fanotifyInfoHeader || infoLen > len(info) {
+			return "", false
+		}
+		record := info[fanotifyInfoHeader:infoLen]
+		info = info[infoLen:]
+		if infoType != unix.FAN_EVENT_INFO_TYPE_DFID_NAME && infoType != unix.FAN_EVENT_INFO_TYPE_DFID {
+			continue
+		}
+		if len(record) < fanotifyFsidSize+fileHandleHeader {
+			return "", false
+		}
+
+		var fsid unix.Fsid
+		fsid.Val[0] = int32(binary.NativeEndian.Uint32(record[0:]))
+		fsid.Val[1] = int32(binary.NativeEndian.Uint32(record[4:]))
+		handleBytes := int(binary.NativeEndian.Uint32(record[8:]))
+		handleType := int32(binary.NativeEndian.Uint32(record[12:]))
+		handleEnd := fanotifyFsidSize + fileHandleHeader + handleBytes
+		if handleEnd > len(record) {
+			return "", false
+		}
+		handle := unix.NewFileHandle(handleType, record[fanotifyFsidSize+fileHandleHeader:handleEnd])
+
+		dir, ok := b.openHandle(fsid, handle)
+		if !ok {
+			return "", false
+		}
+		name := ""
+		if infoType == unix.FAN_EVENT_INFO_TYPE_DFID_NAME {
+			name = unix.ByteSliceToString(record[handleEnd:])
+		}
+		if name == "" || name == "." {
+			return dir, true
+		}
+		return dir + string(os.PathSeparator) + name, true
+	}
+	return "", false
+}
+
+// openHandle은 디렉토리 파일 핸들을 열어 현재 경로를 반환합니다. 이미 삭제된 디렉토리는 false를 반환합니다.
+func (b *fanotifyBackend) openHandle(fsid unix.Fsid, handle unix.FileHandle) (string, bool) {
+	b.mu.Lock()
+	mark, ok := b.marks[fsid]
+	mountFD := -1
+	if ok {
+		mountFD = mark.mountFD
+	}
+	// 핸들을 여는 동안 Remove나 Close가 mountFD를 닫지 않도록 잠금을 유지
+	defer b.mu.Unlock()
+	if mountFD < 0 {
+		return "", false
+	}
+
+	fd, err := unix.OpenByHandleAt(mountFD, handle, unix.O_PATH|unix.O_CLOEXEC)
+	if err != nil {
+		return "", false
+	}
+	defer unix.Close(fd)
+	path, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(fd))
+	if err != nil || strings.HasSuffix(path, " (deleted)") {
+		return "", false
+	}
+	return path, true
+}
+
+// underRoot는 경로가 등록된 루트 중 하나에 속하는지 확인합니다.
+func (b *fanotifyBackend) underRoot(path string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for root := range b.roots {
+		if isUnderPath(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendError는 오류를 전달합니다. 중지되었으면 false를 반환합니다.
+func (b *fanotifyBackend) sendError(err error) bool {
+	select {
+	case b.errors <- err:
+		return true
+	case <-b.done:
+		return false
+	}
+}
+
+// convertFanotifyMask는 fanotify 이벤트 비트를 WatchOp로 변환합니다.
+// 이름 변경은 fsnotify와 같이 이전 이름의 RENAME과 새 이름의 CREATE로 나타냅니다.
+func convertFanotifyMask(mask uint64) WatchOp {
+	var op WatchOp
+	if mask&(unix.FAN_CREATE|unix.FAN_MOVED_TO) != 0 {
+		op |= WatchCreate
+	}
+	if mask&unix.FAN_MODIFY != 0 {
+		op |= WatchWrite
+	}
+	if mask&unix.FAN_DELETE != 0 {
+		op |= WatchRemove
+	}
+	if mask&unix.FAN_MOVED_FROM != 0 {
+		op |= WatchRename
+	}
+	if mask&unix.FAN_ATTRIB != 0 {
+		op |= WatchChmod
+	}
+	if mask&unix.FAN_OPEN_EXEC != 0 {
+		op |= WatchExec
+	}
+	return op
+}
+
+// lookupProcess는 /proc에서 프로세스의 실행 파일과 실제 UID를 읽습니다.
+// 프로세스가 이미 종료되었으면 PID만 채웁니다.
+func lookupProcess(pid int) *ProcessInfo {
+	if pid <= 0 {
+		return nil
+	}
+	proc := &ProcessInfo{PID: pid, UID: -1}
+	dir := "/proc/" + strconv.Itoa(pid)
+	proc.Exe, _ = os.Readlink(dir + "/exe")
+
+	f, err := os.Open(dir + "/status")
+	if err != nil {
+		return proc
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Uid: 실제 유효 저장 파일시스템
+		if fields := strings.Fields(scanner.Text()); len(fields) >= 2 && fields[0] == "Uid:" {
+			if uid, err := strconv.Atoi(fields[1]); err == nil {
+				proc.UID = uid
+			}
+			break
+		}
+	}
+	return proc
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestFanotifyProcessAttribution(t *testing.T) {
+	backend, err := NewFanotifyBackend()
+	if err != nil {
+		t.Skipf("fanotify unavailable: %v", err)
+	}
+
+	dir := t.TempDir()
+	watchDir := filepath.Join(dir, "watch")
+	os.MkdirAll(filepath.Join(watchDir, "node_modules"), 0755)
+
+	mon := NewMonitor(time.Hour)
+	mon.SetBackend(backend)
+	mon.SetDatabasePath(filepath.Join(dir, "test.db"))
+	if err := mon.SetFileFilters([]string{".sh"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mon.SetOperations([]string{"CREATE", "EXEC"}); err != nil {
+		t.Fatal(err)
+	}
+	mon.AddDevice(watchDir)
+	if err := mon.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer mon.Stop()
+
+	// 장치 루트만 등록됨
+	deadline := time.Now().Add(2 * time.Second)
+	for mon.Status().NotifyDirs != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the device, status %+v", mon.Status())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 제외 디렉토리 아래의 이벤트는 기록되지 않음
+	os.WriteFile(filepath.Join(watchDir, "node_modules", "ignored.sh"), []byte("#!/bin/sh\n"), 0755)
+
+	script := filepath.Join(watchDir, "tool.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\nexit 0\n"), 0755)
+	if err := exec.Command(script).Run(); err != nil {
+		t.Fatalf("Running script failed: %v", err)
+	}
+
+	events := waitEvents(t, mon, 2)
+	self, _ := os.Executable()
+	create, execEvent := events[0], events[1]
+	if create.Operation != OperationCreate || create.Path != script || create.Process == nil ||
+		create.Process.PID != os.Getpid() || create.Process.Exe != self || create.Process.UID != os.Getuid() {
+		t.Errorf("Expected CREATE by this process, got %+v (process %+v)", create, create.Process)
+	}
+	if execEvent.Operation != OperationExec || execEvent.Path != script || execEvent.Process == nil ||
+		execEvent.Process.PID == os.Getpid() {
+		t.Errorf("Expected EXEC by the child process, got %+v (process %+v)", execEvent, execEvent.Process)
+	}
+}
+
+func TestConvertFanotifyMask(t *testing.T) {
+	got := convertFanotifyMask(unix.FAN_CREATE | unix.FAN_MOVED_TO | unix.FAN_OPEN_EXEC)
+	if got != WatchCreate|WatchExec {
+		t.Errorf("Expected CREATE|EXEC, got %s", got)
+	}
+}
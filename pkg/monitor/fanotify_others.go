@@ -0,0 +1,10 @@
+//go:build !linux
+
+package monitor
+
+import "fmt"
+
+// NewFanotifyBackend는 Linux 외의 플랫폼에서 항상 오류를 반환합니다.
+func NewFanotifyBackend() (WatcherBackend, error) {
+	return nil, fmt.Errorf("fanotify는 Linux에서만 지원됩니다")
+}
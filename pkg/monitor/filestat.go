@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FileStat은 이벤트 시점에 조회한 파일의 상태입니다.
+// 플랫폼이 제공하지 않는 항목은 0, 빈 문자열 또는 -1(UID/GID)입니다.
+type FileStat struct {
+	Size       int64
+	Mode       os.FileMode
+	UID        int    // 소유자 ID (Windows에서는 -1)
+	GID        int    // 그룹 ID (Windows에서는 -1)
+	Owner      string // UID의 사용자 이름 (Windows에서는 소유자 SID의 "도메인\계정", 조회할 수 없으면 빈 문자열)
+	Group      string // GID의 그룹 이름 (Windows에서는 그룹 SID의 "도메인\계정", 조회할 수 없으면 빈 문자열)
+	ModTime    time.Time
+	ChangeTime time.Time // 메타데이터 변경 시간 (ctime, 제공하지 않는 플랫폼에서는 0)
+	Inode      uint64    // inode 번호 (Windows에서는 파일 인덱스)
+	Links      uint64    // 하드 링크 수
+	Executable bool      // 실행 가능한 일반 파일 (실행 권한 비트, Windows에서는 실행 파일 확장자로 판단)
+}
+
+// isExecutable은 일반 파일이 실행 권한 비트가 있거나 플랫폼의 실행 파일 확장자인지 확인합니다.
+// 내용으로 판별한 PE 파일은 FileEvent.DetectedType으로 확인할 수 있습니다.
+func isExecutable(path string, mode os.FileMode) bool {
+	return mode.IsRegular() && (mode.Perm()&0111 != 0 || executableExt(path))
+}
+
+// statFile은 파일의 상태를 조회합니다. 파일이 이미 사라졌으면 nil을 반환합니다.
+func statFile(path string) *FileStat {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil
+	}
+	st := &FileStat{
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		UID:     -1,
+		GID:     -1,
+		ModTime: info.ModTime(),
+	}
+	st.Executable = isExecutable(path, info.Mode())
+	fillPlatformStat(path, info, st)
+	if st.UID >= 0 {
+		st.Owner = ownerNames.lookup(st.UID, false)
+	}
+	if st.GID >= 0 {
+		st.Group = ownerNames.lookup(st.GID, true)
+	}
+	return st
+}
+
+// nameCache는 UID/GID를 사용자·그룹 이름으로 바꾼 결과를 기억합니다.
+// 조회에 실패한 ID도 빈 문자열로 기억하여 이벤트마다 다시 조회하지 않습니다.
+type nameCache struct {
+	mu     sync.Mutex
+	users  map[int]string
+	groups map[int]string
+}
+
+var ownerNames = &nameCache{users: make(map[int]string), groups: make(map[int]string)}
+
+func (c *nameCache) lookup(id int, group bool) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := c.users
+	if group {
+		names = c.groups
+	}
+	if name, ok := names[id]; ok {
+		return name
+	}
+
+	name := ""
+	if group {
+		if g, err := user.LookupGroupId(strconv.Itoa(id)); err == nil {
+			name = g.Name
+		}
+	} else if u, err := user.LookupId(strconv.Itoa(id)); err == nil {
+		name = u.Username
+	}
+	names[id] = name
+	return name
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEventFileStat(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	dbPath := mon.dbPath
+
+	path := filepath.Join(dir, "tool.exe")
+	os.WriteFile(path, []byte("MZ payload"), 0755)
+	os.Chmod(path, 0755)
+	info, _ := os.Stat(path)
+
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "gone.exe"), Op: WatchRemove})
+	events := waitEvents(t, mon, 2)
+	mon.Stop()
+
+	st := events[0].Stat
+	if st == nil {
+		t.Fatalf("Expected file stat on CREATE, got %+v", events[0])
+	}
+	if st.Size != 10 || !st.ModTime.Equal(info.ModTime()) || st.Links != 1 {
+		t.Errorf("Unexpected stat: %+v", st)
+	}
+	if events[1].Stat != nil {
+		t.Errorf("Expected no stat on REMOVE, got %+v", events[1].Stat)
+	}
+	if runtime.GOOS != "windows" {
+		if st.Mode.Perm() != 0755 || !st.Executable || st.Inode != fileInode(info) || st.ChangeTime.IsZero() {
+			t.Errorf("Unexpected mode, inode or ctime: %+v", st)
+		}
+		if st.UID != os.Getuid() || st.GID != os.Getgid() {
+			t.Errorf("Expected owner %d:%d, got %d:%d", os.Getuid(), os.Getgid(), st.UID, st.GID)
+		}
+		if u, err := user.LookupId(strconv.Itoa(os.Getuid())); err == nil && st.Owner != u.Username {
+			t.Errorf("Expected owner name %q, got %q", u.Username, st.Owner)
+		}
+	} else {
+		// 권한 비트 대신 확장자로 실행 파일을 판단하고(Mode에는 실행 비트를 더하지 않음), 소유자는 SID의 계정 이름
+		if !st.Executable || st.Mode.Perm()&0111 != 0 || st.UID != -1 || st.Owner == "" || st.Inode == 0 {
+			t.Errorf("Unexpected executable, owner or file index: %+v", st)
+		}
+		if u, err := user.Current(); err == nil && st.Owner != u.Username && !strings.HasSuffix(st.Owner, `\Administrators`) {
+			t.Errorf("Expected owner %q, got %q", u.Username, st.Owner)
+		}
+	}
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+	stored, err := db.GetFileEvents()
+	if err != nil {
+		t.Fatalf("GetFileEvents failed: %v", err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("Expected 2 events in database, got %d", len(stored))
+	}
+	byPath := make(map[string]FileEvent)
+	for _, e := range stored {
+		byPath[e.Path] = e
+	}
+	got := byPath[path].Stat
+	if got == nil || got.Size != st.Size || got.Mode != st.Mode || got.UID != st.UID || got.Owner != st.Owner || got.Executable != st.Executable ||
+		got.Inode != st.Inode || got.Links != st.Links || got.ModTime.Format(timeLayout) != st.ModTime.Format(timeLayout) {
+		t.Errorf("Stat not preserved in database: stored %+v, recorded %+v", got, st)
+	}
+	if removed := byPath[filepath.Join(dir, "gone.exe")]; removed.Stat != nil {
+		t.Errorf("Expected NULL stat columns for REMOVE, got %+v", removed.Stat)
+	}
+}
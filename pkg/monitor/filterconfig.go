@@ -0,0 +1,292 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FilterConfig는 모니터링 중에도 교체할 수 있는 이벤트 필터 설정입니다.
+// ApplyFilterConfig로 한 번에 적용하면 이벤트 처리 중인 고루틴은 항상 교체 전 또는 교체 후의
+// 설정 전체를 보게 되며, 두 설정이 섞이지 않습니다.
+type FilterConfig struct {
+	FileFilters []string  // 확장자 필터 (빈 목록은 모든 확장자)
+	TypeFilters []string  // 매직 바이트로 감지한 콘텐츠 유형 필터
+	PathRule    *PathRule // 경로 포함/제외 규칙 (nil이면 사용 안 함)
+	ExcludeDirs []string  // 감시 등록 시 건너뛸 디렉토리 패턴
+	Operations  []string  // 기록할 작업 종류
+
+	// Devices는 장치(루트 경로)별 필터입니다. 해당 장치 아래의 이벤트에는 전역 FileFilters,
+	// TypeFilters, PathRule 대신 장치의 필터가 사용됩니다. 장치가 겹치면 가장 깊은 장치가 우선합니다.
+	Devices map[string]DeviceFilter
+}
+
+// DeviceFilter는 특정 장치에만 적용되는 파일 유형 필터와 경로 규칙입니다.
+// 전역 설정과 합쳐지지 않고 대체하므로, FileFilters와 TypeFilters가 모두 비어 있으면 모든 파일이 대상입니다.
+type DeviceFilter struct {
+	FileFilters []string
+	TypeFilters []string
+	PathRule    *PathRule
+}
+
+// String은 장치 필터를 로그용 문자열로 반환합니다.
+func (d DeviceFilter) String() string {
+	return fmt.Sprintf("filters=%v types=%v path rules=[%s]", d.FileFilters, d.TypeFilters, d.PathRule.String())
+}
+
+// filterSet은 FilterConfig를 검증하고 컴파일한 불변 스냅샷입니다.
+// Monitor.filters에 원자적으로 저장되며, 저장된 뒤에는 수정하지 않습니다.
+type filterSet struct {
+	fileFilters []string
+	typeFilters []string
+	pathRule    *PathRule
+	excludeDirs []dirExclusion
+	operations  map[string]bool
+	devices     []deviceFilterSet
+}
+
+// deviceFilterSet은 장치 루트와 그 아래에 적용되는 스냅샷입니다.
+type deviceFilterSet struct {
+	root    string
+	filters *filterSet
+}
+
+// defaultFilterConfig는 NewMonitor의 기본 필터 설정입니다.
+func defaultFilterConfig() FilterConfig {
+	return FilterConfig{
+		FileFilters: []string{".exe", ".dll"},
+		ExcludeDirs: DefaultExcludeDirs(),
+		Operations:  append([]string(nil), defaultOperations...),
+	}
+}
+
+// compileFilterConfig는 설정을 검증하여 스냅샷을 만듭니다. 호출자의 슬라이스와 규칙은 복사됩니다.
+func compileFilterConfig(cfg FilterConfig) (*filterSet, error) {
+	operations, err := parseOperations(cfg.Operations)
+	if err != nil {
+		return nil, err
+	}
+	excludeDirs, err := compileDirExclusions(cfg.ExcludeDirs)
+	if err != nil {
+		return nil, err
+	}
+	set := &filterSet{
+		fileFilters: append([]string(nil), cfg.FileFilters...),
+		typeFilters: normalizeTypeFilters(cfg.TypeFilters),
+		pathRule:    cfg.PathRule.clone(),
+		excludeDirs: excludeDirs,
+		operations:  operations,
+	}
+
+	for root, device := range cfg.Devices {
+		set.devices = append(set.devices, deviceFilterSet{
+			root: filepath.Clean(root),
+			filters: &filterSet{
+				fileFilters: append([]string(nil), device.FileFilters...),
+				typeFilters: normalizeTypeFilters(device.TypeFilters),
+				pathRule:    device.PathRule.clone(),
+				excludeDirs: excludeDirs,
+				operations:  operations,
+			},
+		})
+	}
+	// 가장 깊은 장치가 먼저 검사되도록 긴 경로 순으로 정렬
+	slices.SortFunc(set.devices, func(a, b deviceFilterSet) int {
+		if n := len(b.root) - len(a.root); n != 0 {
+			return n
+		}
+		return strings.Compare(a.root, b.root)
+	})
+	return set, nil
+}
+
+// Validate는 설정을 적용하지 않고 검증만 합니다. ApplyFilterConfig가 거부할 설정이면 오류를 반환합니다.
+func (cfg FilterConfig) Validate() error {
+	_, err := compileFilterConfig(cfg)
+	return err
+}
+
+// forPath는 경로가 속한 장치의 스냅샷을 반환합니다. 장치별 필터가 없으면 자신을 반환합니다.
+func (f *filterSet) forPath(path string) *filterSet {
+	for _, d := range f.devices {
+		if isUnderPath(path, d.root) {
+			return d.filters
+		}
+	}
+	return f
+}
+
+// normalizeTypeFilters는 유형 필터를 소문자로 바꾸고 빈 항목을 제거합니다.
+func normalizeTypeFilters(types []string) []string {
+	filters := make([]string, 0, len(types))
+	for _, t := range types {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			filters = append(filters, t)
+		}
+	}
+	return filters
+}
+
+// config는 스냅샷을 FilterConfig로 되돌립니다.
+func (f *filterSet) config() FilterConfig {
+	cfg := FilterConfig{
+		FileFilters: append([]string(nil), f.fileFilters...),
+		TypeFilters: append([]string(nil), f.typeFilters...),
+		PathRule:    f.pathRule.clone(),
+		ExcludeDirs: make([]string, 0, len(f.excludeDirs)),
+	}
+	for _, e := range f.excludeDirs {
+		cfg.ExcludeDirs = append(cfg.ExcludeDirs, e.pattern.source)
+	}
+	for _, op := range selectableOperations {
+		if f.operations[op] {
+			cfg.Operations = append(cfg.Operations, op)
+		}
+	}
+	if len(f.devices) > 0 {
+		cfg.Devices = make(map[string]DeviceFilter, len(f.devices))
+		for _, d := range f.devices {
+			cfg.Devices[d.root] = DeviceFilter{
+				FileFilters: append([]string(nil), d.filters.fileFilters...),
+				TypeFilters: append([]string(nil), d.filters.typeFilters...),
+				PathRule:    d.filters.pathRule.clone(),
+			}
+		}
+	}
+	return cfg
+}
+
+// matches는 경로가 파일 유형 필터와 경로 규칙을 모두 만족하는지 확인합니다.
+func (f *filterSet) matches(path, detectedType string) bool {
+	return f.matchesType(path, detectedType) && f.pathRule.Match(path)
+}
+
+// matchesType은 확장자가 파일 필터와 일치하거나 감지된 유형이 유형 필터와 일치하는지 확인합니다.
+// 두 필터가 모두 비어 있으면 모든 파일이 일치합니다.
+func (f *filterSet) matchesType(path, detectedType string) bool {
+	if len(f.fileFilters) == 0 && len(f.typeFilters) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, filter := range f.fileFilters {
+		if ext == filter {
+			return true
+		}
+	}
+	if detectedType != "" {
+		for _, filter := range f.typeFilters {
+			if detectedType == filter {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isExcludedDir은 디렉토리가 감시 제외 패턴과 일치하는지 확인합니다.
+func (f *filterSet) isExcludedDir(dir string) bool {
+	slashPath := filepath.ToSlash(dir)
+	base := filepath.Base(dir)
+	for _, e := range f.excludeDirs {
+		if e.baseOnly {
+			if e.pattern.match(base) {
+				return true
+			}
+		} else if e.pattern.match(slashPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// filters는 현재 필터 스냅샷을 반환합니다. 이벤트 하나를 처리하는 동안에는 같은 스냅샷을 사용해야 합니다.
+func (m *Monitor) filters() *filterSet {
+	return m.filterSnapshot.Load()
+}
+
+// updateFilters는 현재 설정을 복사하여 수정한 뒤 새 스냅샷으로 교체합니다.
+func (m *Monitor) updateFilters(update func(cfg *FilterConfig)) error {
+	m.filterMutex.Lock()
+	defer m.filterMutex.Unlock()
+
+	cfg := m.filters().config()
+	update(&cfg)
+	next, err := compileFilterConfig(cfg)
+	if err != nil {
+		return err
+	}
+	m.filterSnapshot.Store(next)
+	return nil
+}
+
+// GetFilterConfig는 현재 적용된 필터 설정의 복사본을 반환합니다.
+func (m *Monitor) GetFilterConfig() FilterConfig {
+	return m.filters().config()
+}
+
+// ApplyFilterConfig는 필터 설정 전체를 검증한 뒤 원자적으로 교체합니다.
+// 모니터링 중에도 호출할 수 있으며, 검증에 실패하면 기존 설정을 유지합니다.
+// 변경된 항목을 "항목: 이전 -> 이후" 형식으로 로그에 남기고 반환합니다.
+func (m *Monitor) ApplyFilterConfig(cfg FilterConfig) ([]string, error) {
+	next, err := compileFilterConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m.filterMutex.Lock()
+	prev := m.filters()
+	m.filterSnapshot.Store(next)
+	m.filterMutex.Unlock()
+
+	changes := DiffFilterConfig(prev.config(), next.config())
+	if len(changes) == 0 {
+		log.Printf("필터 설정 변경 없음")
+	}
+	for _, change := range changes {
+		log.Printf("필터 설정 변경: %s", change)
+	}
+	return changes, nil
+}
+
+// DiffFilterConfig는 두 필터 설정에서 달라진 항목을 "항목: 이전 -> 이후" 형식으로 반환합니다.
+func DiffFilterConfig(prev, next FilterConfig) []string {
+	var changes []string
+	diff := func(name string, a, b []string) {
+		if !slices.Equal(a, b) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, a, b))
+		}
+	}
+	diff("filters", prev.FileFilters, next.FileFilters)
+	diff("types", prev.TypeFilters, next.TypeFilters)
+	if a, b := prev.PathRule.String(), next.PathRule.String(); a != b {
+		changes = append(changes, fmt.Sprintf("path rules: [%s] -> [%s]", a, b))
+	}
+	diff("exclude-dirs", prev.ExcludeDirs, next.ExcludeDirs)
+	diff("ops", prev.Operations, next.Operations)
+
+	var roots []string
+	for root := range prev.Devices {
+		roots = append(roots, root)
+	}
+	for root := range next.Devices {
+		if _, ok := prev.Devices[root]; !ok {
+			roots = append(roots, root)
+		}
+	}
+	slices.Sort(roots)
+	for _, root := range roots {
+		a, inPrev := prev.Devices[root]
+		b, inNext := next.Devices[root]
+		switch {
+		case !inPrev:
+			changes = append(changes, fmt.Sprintf("device %s: (전역 필터) -> %s", root, b))
+		case !inNext:
+			changes = append(changes, fmt.Sprintf("device %s: %s -> (전역 필터)", root, a))
+		case a.String() != b.String():
+			changes = append(changes, fmt.Sprintf("device %s: %s -> %s", root, a, b))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestApplyFilterConfigDiff(t *testing.T) {
+	mon := NewMonitor(0)
+	rule := &PathRule{}
+	rule.Exclude("**/Temp/**")
+
+	changes, err := mon.ApplyFilterConfig(FilterConfig{
+		FileFilters: []string{".exe"},
+		TypeFilters: []string{"PE"},
+		PathRule:    rule,
+		ExcludeDirs: DefaultExcludeDirs(),
+		Operations:  []string{"create", "remove", "rename"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyFilterConfig failed: %v", err)
+	}
+	want := []string{
+		"filters: [.exe .dll] -> [.exe]",
+		"types: [] -> [pe]",
+		"path rules: [] -> [-**/Temp/**]",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected changes %q, got %q", want, changes)
+	}
+
+	// 적용 후 원본 규칙을 수정해도 스냅샷에는 영향이 없어야 함
+	rule.Include("**")
+	if got := mon.GetPathRule().String(); got != "-**/Temp/**" {
+		t.Errorf("Expected path rule to be copied, got %q", got)
+	}
+
+	// 잘못된 설정은 거부되고 기존 설정이 유지됨
+	before := mon.GetFilterConfig()
+	if _, err := mon.ApplyFilterConfig(FilterConfig{Operations: []string{"DELETE"}}); err == nil {
+		t.Error("Expected error for invalid operation")
+	}
+	if !reflect.DeepEqual(mon.GetFilterConfig(), before) {
+		t.Error("Expected previous config to be kept after failed apply")
+	}
+
+	if changes, _ := mon.ApplyFilterConfig(before); len(changes) != 0 {
+		t.Errorf("Expected no changes when applying the same config, got %q", changes)
+	}
+}
+
+func TestFilterReloadWhileRunning(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	defer mon.Stop()
+
+	exeOnly := mon.GetFilterConfig()
+	txtOnly := exeOnly
+	txtOnly.FileFilters = []string{".txt"}
+
+	// 이벤트 처리 중 설정을 반복해서 교체해도 경쟁 상태가 없어야 함 (-race로 확인)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if i%2 == 0 {
+				mon.ApplyFilterConfig(txtOnly)
+			} else {
+				mon.ApplyFilterConfig(exeOnly)
+			}
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		fake.Push(WatchEvent{Name: filepath.Join(dir, "x.dat"), Op: WatchCreate})
+	}
+	wg.Wait()
+
+	mon.ApplyFilterConfig(txtOnly)
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "notes.txt"), Op: WatchCreate})
+	if events := waitEvents(t, mon, 1); events[0].Path != filepath.Join(dir, "notes.txt") {
+		t.Errorf("Expected event matching reloaded filter, got %+v", events[0])
+	}
+}
+
+func TestDeviceFilters(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t)
+	defer mon.Stop()
+
+	logs := filepath.Join(dir, "logs")
+	cfg := mon.GetFilterConfig()
+	cfg.Devices = map[string]DeviceFilter{logs: {FileFilters: []string{".log"}}}
+	changes, err := mon.ApplyFilterConfig(cfg)
+	if err != nil {
+		t.Fatalf("ApplyFilterConfig failed: %v", err)
+	}
+	want := []string{"device " + logs + ": (전역 필터) -> filters=[.log] types=[] path rules=[]"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected changes %q, got %q", want, changes)
+	}
+
+	// 장치 아래에서는 장치 필터만, 그 밖에서는 전역 필터만 적용됨
+	fake.Push(WatchEvent{Name: filepath.Join(logs, "a.exe"), Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "b.log"), Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(logs, "c.log"), Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "d.exe"), Op: WatchCreate})
+
+	events := waitEvents(t, mon, 2)
+	if events[0].Path != filepath.Join(logs, "c.log") || events[1].Path != filepath.Join(dir, "d.exe") {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+}
@@ -0,0 +1,269 @@
+package monitor
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// SetHashAlgorithms에 사용할 수 있는 해시 알고리즘 이름입니다.
+const (
+	HashMD5    = "md5"
+	HashSHA1   = "sha1"
+	HashSHA256 = "sha256"
+)
+
+const (
+	// defaultHashMaxSize보다 큰 파일은 해시를 계산하지 않습니다.
+	defaultHashMaxSize = 100 << 20
+	defaultHashWorkers = 2
+	// defaultHashQueue는 해시 대기열 크기로, 가득 차면 이벤트는 해시 없이 기록됩니다.
+	defaultHashQueue = 256
+)
+
+// defaultHashAlgorithms는 기본으로 계산하는 해시 알고리즘 목록입니다.
+var defaultHashAlgorithms = []string{HashSHA256}
+
+// FileHashes는 이벤트 발생 후 계산된 파일의 크기와 해시(16진수 소문자)입니다.
+// EventID는 해시 계산을 요청한 FileEvent의 ID이며, 선택하지 않은 알고리즘의 값은 빈 문자열입니다.
+type FileHashes struct {
+	EventID  int64
+	Path     string
+	Size     int64
+	MD5      string
+	SHA1     string
+	SHA256   string
+	HashedAt time.Time
+}
+
+// parseHashAlgorithms는 알고리즘 이름 목록을 검증하여 중복 없이 정규화합니다.
+func parseHashAlgorithms(algorithms []string) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+	for _, a := range algorithms {
+		a = strings.ToLower(strings.TrimSpace(a))
+		a = strings.ReplaceAll(a, "-", "")
+		if a == "" || seen[a] {
+			continue
+		}
+		switch a {
+		case HashMD5, HashSHA1, HashSHA256:
+		default:
+			return nil, fmt.Errorf("알 수 없는 해시 알고리즘: %s (가능한 값: md5, sha1, sha256)", a)
+		}
+		seen[a] = true
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// hashJob은 해시를 계산할 이벤트의 ID와 파일 경로입니다.
+// inventory가 있으면 이벤트 없이 인벤토리 항목의 SHA-256만 계산하여 전달하며, 계산에 실패하면 빈 문자열을 전달합니다.
+type hashJob struct {
+	eventID   int64
+	path      string
+	inventory func(sha256 string)
+}
+
+// hashPool은 이벤트 대상 파일의 해시를 계산하는 작업자 풀입니다.
+// 계산된 해시는 result로 전달되며, 성공 여부와 관계없이 작업이 끝나면 finished가 호출됩니다.
+type hashPool struct {
+	fileWorkers[hashJob]
+	algorithms []string
+	maxSize    int64
+	result     func(FileHashes)
+	finished   func(eventID int64)
+}
+
+func newHashPool() *hashPool {
+	return &hashPool{
+		fileWorkers: newFileWorkers[hashJob](defaultHashWorkers, defaultHashQueue),
+		algorithms:  defaultHashAlgorithms,
+		maxSize:     defaultHashMaxSize,
+	}
+}
+
+// enabled는 계산할 해시 알고리즘이 설정되어 있는지 확인합니다.
+func (p *hashPool) enabled() bool {
+	return len(p.algorithms) > 0
+}
+
+// start는 작업자 고루틴을 시작합니다.
+func (p *hashPool) start(result func(FileHashes), finished func(eventID int64)) {
+	p.result = result
+	p.finished = finished
+	p.fileWorkers.start(p.run)
+}
+
+func (p *hashPool) run(job hashJob) {
+	if job.inventory != nil {
+		hashes, err := p.hashFile(job.path, []string{HashSHA256})
+		if err != nil {
+			log.Printf("인벤토리 해시 계산 실패: %s - %v", job.path, err)
+			job.inventory("")
+			return
+		}
+		job.inventory(hashes.SHA256)
+		return
+	}
+
+	defer p.finished(job.eventID)
+	hashes, err := p.hashFile(job.path, p.algorithms)
+	if err != nil {
+		log.Printf("해시 계산 실패: %s - %v", job.path, err)
+		return
+	}
+	hashes.EventID = job.eventID
+	hashes.Path = job.path
+	hashes.HashedAt = time.Now()
+	p.result(*hashes)
+}
+
+// hashFile은 파일의 해시를 계산합니다.
+// 파일을 열 수 없거나(다른 프로세스가 잠금) 최근에 수정되었거나 계산 중에 크기나
+// 수정 시간이 바뀌면 아직 쓰이는 중인 것으로 보고 잠시 후 다시 시도합니다.
+// 마지막 시도에서는 최근 수정 여부와 관계없이 현재 내용의 해시를 계산합니다.
+func (p *hashPool) hashFile(path string, algorithms []string) (*FileHashes, error) {
+	var hashes *FileHashes
+	err := p.attempt(func(final bool) (retry bool, err error) {
+		hashes, retry, err = p.tryHash(path, algorithms, final)
+		return retry, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// tryHash는 해시 계산을 한 번 시도합니다. retry는 다시 시도할 가치가 있는 오류인지를 나타냅니다.
+// final이면 최근에 수정된 파일도 기다리지 않고 계산합니다.
+func (p *hashPool) tryHash(path string, algorithms []string, final bool) (hashes *FileHashes, retry bool, err error) {
+	before, retry, err := p.stableStat(path, final)
+	if err != nil {
+		return nil, retry, err
+	}
+	if before.Size() > p.maxSize {
+		return nil, false, fmt.Errorf("파일 크기 %d바이트가 제한(%d바이트)을 초과합니다", before.Size(), p.maxSize)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, !os.IsNotExist(err), err
+	}
+	defer f.Close()
+
+	hashers := make(map[string]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, 0, len(algorithms))
+	for _, a := range algorithms {
+		var h hash.Hash
+		switch a {
+		case HashMD5:
+			h = md5.New()
+		case HashSHA1:
+			h = sha1.New()
+		case HashSHA256:
+			h = sha256.New()
+		}
+		hashers[a] = h
+		writers = append(writers, h)
+	}
+
+	// 제한보다 1바이트 더 읽어 계산 중에 파일이 커졌는지 확인
+	n, err := io.Copy(io.MultiWriter(writers...), io.LimitReader(f, p.maxSize+1))
+	if err != nil {
+		return nil, true, err
+	}
+
+	after, err := f.Stat()
+	if err != nil {
+		return nil, true, err
+	}
+	if n != before.Size() || changedSince(before, after) {
+		return nil, true, fmt.Errorf("해시 계산 중 파일이 변경되었습니다")
+	}
+
+	hashes = &FileHashes{Size: n}
+	for a, h := range hashers {
+		sum := hex.EncodeToString(h.Sum(nil))
+		switch a {
+		case HashMD5:
+			hashes.MD5 = sum
+		case HashSHA1:
+			hashes.SHA1 = sum
+		case HashSHA256:
+			hashes.SHA256 = sum
+		}
+	}
+	return hashes, false, nil
+}
+
+// shouldHash는 이벤트 대상 파일의 해시를 계산해야 하는지 확인합니다.
+// 새로 생기거나(CREATE, MOVE) 내용이 바뀐(MODIFIED) 파일만 대상입니다.
+func shouldHash(event FileEvent) bool {
+	switch event.Operation {
+	case OperationCreate, OperationMove, OperationModified:
+		return true
+	}
+	return false
+}
+
+// recordHashes는 계산된 해시를 다음 저장 시점까지 메모리에 보관합니다.
+func (m *Monitor) recordHashes(hashes FileHashes) {
+	log.Printf("해시 계산 완료: %s (sha256: %s)", hashes.Path, hashes.SHA256)
+	m.recentHashes.add(hashes.EventID, hashes)
+	m.eventsMutex.Lock()
+	hashes.EventID = m.remapEventID(hashes.EventID)
+	m.fileHashes = append(m.fileHashes, hashes)
+	m.eventsMutex.Unlock()
+	m.updateInventoryHash(hashes)
+}
+
+// SetHashAlgorithms는 새 파일과 수정된 파일에 대해 계산할 해시 알고리즘(md5, sha1, sha256)을 설정합니다.
+// 빈 목록을 설정하면 해시를 계산하지 않습니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetHashAlgorithms(algorithms []string) error {
+	parsed, err := parseHashAlgorithms(algorithms)
+	if err != nil {
+		return err
+	}
+	m.hasher.algorithms = parsed
+	log.Printf("해시 알고리즘 설정됨: %v", parsed)
+	return nil
+}
+
+// GetHashAlgorithms는 현재 설정된 해시 알고리즘 목록을 반환합니다.
+func (m *Monitor) GetHashAlgorithms() []string {
+	return m.hasher.algorithms
+}
+
+// SetHashMaxSize는 해시를 계산할 최대 파일 크기(바이트)를 설정합니다.
+// 이보다 큰 파일의 이벤트는 해시 없이 기록됩니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetHashMaxSize(size int64) {
+	if size <= 0 {
+		size = defaultHashMaxSize
+	}
+	m.hasher.maxSize = size
+}
+
+// SetHashWorkers는 해시를 계산하는 작업자 고루틴 수를 설정합니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetHashWorkers(workers int) {
+	if workers <= 0 {
+		workers = defaultHashWorkers
+	}
+	m.hasher.workers = workers
+}
+
+// WaitFileHashes는 이벤트에 대해 계산된 해시를 반환합니다. 계산 중이면 끝나거나 ctx가 취소될 때까지 기다립니다.
+// 해시를 계산하지 않는 이벤트이거나, 계산에 실패했거나, 최근 결과로 보관하지 않는 오래된 이벤트이면 false를 반환합니다.
+// 탐지 규칙처럼 구독한 이벤트를 파일 해시로 확인할 때 파일을 다시 읽지 않도록 사용합니다.
+func (m *Monitor) WaitFileHashes(ctx context.Context, eventID int64) (FileHashes, bool) {
+	return m.recentHashes.wait(ctx, eventID)
+}
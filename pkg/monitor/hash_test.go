@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFakeBackendHashing(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.hasher.retryDelay = 20 * time.Millisecond
+		if err := m.SetHashAlgorithms([]string{"SHA-256", "sha1", "md5"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	dbPath := mon.dbPath
+
+	path := filepath.Join(dir, "drop.exe")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "gone.exe"), Op: WatchRemove})
+
+	// 해시 계산을 기다리지 않고 발생 순서대로 전달되어야 함
+	events := waitEvents(t, mon, 2)
+	if events[0].Operation != "CREATE" || events[1].Operation != "REMOVE" {
+		t.Fatalf("Expected events in order, got %v", events)
+	}
+	if events[0].ID == 0 || events[1].ID != events[0].ID+1 {
+		t.Errorf("Expected sequential event IDs, got %d and %d", events[0].ID, events[1].ID)
+	}
+
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	hashes, err := db.GetFileHashes(events[0].ID)
+	if err != nil || hashes == nil {
+		t.Fatalf("Expected stored hashes, got %v (err %v)", hashes, err)
+	}
+	want := FileHashes{
+		EventID: events[0].ID,
+		Path:    path,
+		Size:    5,
+		MD5:     "5d41402abc4b2a76b9719d911017c592",
+		SHA1:    "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		SHA256:  "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}
+	hashes.HashedAt = time.Time{}
+	if *hashes != want {
+		t.Errorf("Expected %+v, got %+v", want, *hashes)
+	}
+
+	if none, err := db.GetFileHashes(events[1].ID); err != nil || none != nil {
+		t.Errorf("Expected no hashes for REMOVE event, got %v (err %v)", none, err)
+	}
+	found, err := db.GetFileHashesByDigest(want.SHA256)
+	if err != nil || len(found) != 1 || found[0].EventID != events[0].ID {
+		t.Errorf("Expected lookup by digest to find the event, got %v (err %v)", found, err)
+	}
+}
+
+func TestHashPoolWaitsForWriter(t *testing.T) {
+	pool := newHashPool()
+	pool.retryDelay = 30 * time.Millisecond
+	pool.retries = 20
+	pool.quit = make(chan struct{})
+
+	path := filepath.Join(t.TempDir(), "growing.bin")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer f.Close()
+		for i := 0; i < 5; i++ {
+			f.Write([]byte("chunk"))
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	hashes, err := pool.hashFile(path, pool.algorithms)
+	<-done
+	if err != nil {
+		t.Fatalf("hashFile failed: %v", err)
+	}
+	sum := sha256.Sum256([]byte("chunkchunkchunkchunkchunk"))
+	if hashes.Size != 25 || hashes.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected hash of completed file, got %+v", hashes)
+	}
+}
+
+func TestHashPoolLimits(t *testing.T) {
+	pool := newHashPool()
+	pool.maxSize = 4
+	pool.retryDelay = 0
+	pool.quit = make(chan struct{})
+
+	path := filepath.Join(t.TempDir(), "big.bin")
+	os.WriteFile(path, []byte("too large"), 0644)
+	if _, err := pool.hashFile(path, pool.algorithms); err == nil {
+		t.Error("Expected error for file over size limit")
+	}
+
+	if _, err := parseHashAlgorithms([]string{"sha256", "crc32"}); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+}
+
+func TestFakeBackendEventIDCollision(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.hasher.retryDelay = 20 * time.Millisecond
+		if err := m.SetHashAlgorithms([]string{"sha256"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	dbPath := mon.dbPath
+
+	path := filepath.Join(dir, "drop.exe")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	events := waitEvents(t, mon, 1)
+
+	// 다른 프로세스가 같은 ID로 이벤트를 먼저 저장한 경우
+	other, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	imported := FileEvent{ID: events[0].ID, Path: filepath.Join(dir, "imported.exe"), Operation: "CREATE", Timestamp: time.Now()}
+	if err := other.SaveFileEvent(imported); err != nil {
+		t.Fatalf("SaveFileEvent failed: %v", err)
+	}
+	other.Close()
+
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	saved, err := db.GetFileEventsByPath(path)
+	if err != nil || len(saved) != 1 {
+		t.Fatalf("Expected the event to be saved with a new ID, got %v (err %v)", saved, err)
+	}
+	if saved[0].ID == events[0].ID {
+		t.Errorf("Expected a reassigned ID, got %d", saved[0].ID)
+	}
+	hashes, err := db.GetFileHashes(saved[0].ID)
+	if err != nil || hashes == nil || hashes.Path != path {
+		t.Errorf("Expected hashes linked to the reassigned ID, got %v (err %v)", hashes, err)
+	}
+	if hashes, err := db.GetFileHashes(events[0].ID); err != nil || hashes != nil {
+		t.Errorf("Expected no hashes linked to the imported event, got %v (err %v)", hashes, err)
+	}
+}
+
+func TestWaitFileHashes(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.hasher.retryDelay = 50 * time.Millisecond
+	})
+	defer mon.Stop()
+
+	path := filepath.Join(dir, "drop.exe")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "gone.exe"), Op: WatchRemove})
+	events := waitEvents(t, mon, 2)
+
+	// 이벤트를 받은 시점에는 아직 계산 중이므로 끝날 때까지 기다려야 함
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	hashes, ok := mon.WaitFileHashes(ctx, events[0].ID)
+	if !ok || hashes.SHA256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("Expected computed hashes, got %+v (ok %v)", hashes, ok)
+	}
+
+	// 해시를 계산하지 않는 이벤트는 기다리지 않음
+	start := time.Now()
+	if hashes, ok := mon.WaitFileHashes(ctx, events[1].ID); ok {
+		t.Errorf("Expected no hashes for REMOVE, got %+v", hashes)
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Errorf("Expected an immediate answer for REMOVE, took %v", time.Since(start))
+	}
+}
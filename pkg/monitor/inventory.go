@@ -0,0 +1,392 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InventoryEntry는 인벤토리에 기록되는 필터 대상 파일 하나의 상태입니다.
+// SHA256은 조정 스캔이나 모니터링 중에 계산되며, 해시 최대 크기보다 큰 파일과 처음 감시하는 장치의 파일은 비어 있습니다.
+type InventoryEntry struct {
+	Path    string
+	Device  string
+	Size    int64
+	ModTime time.Time
+	SHA256  string
+}
+
+// inventory는 필터 대상 파일의 목록입니다.
+// 시작 시 데이터베이스에서 불러와 장치를 다시 스캔한 결과와 비교하고(조정),
+// 모니터링 중에는 이벤트에 따라 갱신한 뒤 중지 시 저장합니다.
+type inventory struct {
+	enabled bool
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]InventoryEntry
+	devices map[string]bool // 인벤토리를 만든 장치
+}
+
+// SetReconcile은 중지된 동안의 변경을 시작 시 찾아낼지 설정합니다. 기본값은 true입니다.
+// 사용하면 중지 시 필터 대상 파일의 목록(경로, 크기, 수정 시간, 해시)을 저장하고, 다음 시작 시 장치를 다시 스캔하여
+// 달라진 파일을 Reconciled가 true인 CREATE/REMOVE/MODIFIED 이벤트로 기록합니다.
+// 처음 감시하는 장치는 목록만 만들고 이벤트를 기록하지 않습니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetReconcile(enabled bool) {
+	m.inventory.enabled = enabled
+}
+
+// loadInventory는 데이터베이스에 저장된 인벤토리를 불러옵니다.
+func (m *Monitor) loadInventory() error {
+	inv := &m.inventory
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.loaded = false
+	inv.entries = make(map[string]InventoryEntry)
+	inv.devices = make(map[string]bool)
+	if !inv.enabled {
+		return nil
+	}
+
+	entries, devices, err := m.db.LoadInventory()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		inv.entries[e.Path] = e
+	}
+	for _, device := range devices {
+		inv.devices[device] = true
+	}
+	inv.loaded = true
+	return nil
+}
+
+// saveInventory는 현재 인벤토리를 데이터베이스에 저장합니다.
+func (m *Monitor) saveInventory() {
+	inv := &m.inventory
+	inv.mu.Lock()
+	if !inv.loaded {
+		inv.mu.Unlock()
+		return
+	}
+	entries := make([]InventoryEntry, 0, len(inv.entries))
+	for _, e := range inv.entries {
+		entries = append(entries, e)
+	}
+	devices := make([]string, 0, len(inv.devices))
+	for device := range inv.devices {
+		devices = append(devices, device)
+	}
+	inv.mu.Unlock()
+
+	if err := m.db.SaveInventory(entries, devices); err != nil {
+		log.Printf("인벤토리 저장 실패: %v", err)
+		return
+	}
+	log.Printf("인벤토리 저장 완료: 파일 %d개, 장치 %d개", len(entries), len(devices))
+}
+
+// inventoryScan은 장치의 감시 등록 탐색 중에 모은 필터 대상 파일입니다.
+// 조정을 위해 장치를 따로 한 번 더 탐색하지 않도록 watchRecursive의 방문 함수로 채웁니다.
+type inventoryScan struct {
+	device   string
+	filters  *filterSet
+	started  time.Time
+	current  map[string]InventoryEntry
+	detected map[string]string
+}
+
+// newInventoryScan은 장치의 인벤토리 스캔을 시작합니다. 인벤토리를 사용하지 않으면 nil을 반환합니다.
+func (m *Monitor) newInventoryScan(device string) *inventoryScan {
+	inv := &m.inventory
+	inv.mu.Lock()
+	loaded := inv.loaded
+	inv.mu.Unlock()
+	if !loaded {
+		return nil
+	}
+	return &inventoryScan{
+		device:   device,
+		filters:  m.filters(),
+		started:  time.Now(),
+		current:  make(map[string]InventoryEntry),
+		detected: make(map[string]string),
+	}
+}
+
+// visit는 탐색 중에 만난 일반 파일이 필터 대상이면 스캔 결과에 추가합니다.
+func (s *inventoryScan) visit(m *Monitor) func(path string, d fs.DirEntry) {
+	return func(path string, d fs.DirEntry) {
+		f := s.filters.forPath(path)
+		// 확장자로 대상이 정해지는 파일은 내용을 읽지 않음
+		contentType := ""
+		if !f.matches(path, "") {
+			contentType = m.detectType(f, path)
+			if !f.matches(path, contentType) {
+				return
+			}
+		}
+		info, err := d.Info()
+		if err != nil {
+			return
+		}
+		s.current[path] = InventoryEntry{Path: path, Device: s.device, Size: info.Size(), ModTime: info.ModTime()}
+		s.detected[path] = contentType
+	}
+}
+
+// reconcileDevice는 감시 등록 탐색에서 모은 장치의 필터 대상 파일을 저장된 인벤토리와 비교하고,
+// 중지된 동안 생성·삭제·수정된 파일을 조정 이벤트로 기록합니다.
+// 크기와 수정 시간이 저장된 항목과 같은 파일은 저장된 SHA-256을 그대로 쓰고, 나머지는 해시 작업자 풀에서
+// 해시 최대 크기 이하인 경우 SHA-256을 계산하여 인벤토리에 기록합니다. 처음 감시하는 장치는 비교할 대상이
+// 없으므로 해시를 계산하지 않고 크기와 수정 시간만 기록합니다. 해시 계산이 중간에 취소되면 인벤토리를 바꾸지 않습니다.
+func (m *Monitor) reconcileDevice(ctx context.Context, scan *inventoryScan) {
+	inv := &m.inventory
+	device := scan.device
+	filters := scan.filters
+	current := scan.current
+	detected := scan.detected
+
+	// 파일을 읽는 동안 인벤토리를 잠그지 않도록 비교할 항목을 먼저 복사
+	inv.mu.Lock()
+	known := inv.devices[device]
+	stored := make(map[string]InventoryEntry, len(current))
+	for path := range current {
+		if before, ok := inv.entries[path]; ok {
+			stored[path] = before
+		}
+	}
+	inv.mu.Unlock()
+
+	var pending []string
+	for path, after := range current {
+		before, ok := stored[path]
+		if ok && before.SHA256 != "" && after.Size == before.Size && after.ModTime.Equal(before.ModTime) {
+			after.SHA256 = before.SHA256
+			current[path] = after
+		} else if known {
+			pending = append(pending, path)
+		}
+	}
+	if !m.hashInventory(ctx, device, current, pending) {
+		return
+	}
+
+	var created, removed, modified []string
+	inv.mu.Lock()
+	for path, before := range inv.entries {
+		if !isUnderPath(path, device) {
+			continue
+		}
+		after, ok := current[path]
+		if !ok {
+			info, err := os.Lstat(path)
+			switch {
+			case os.IsNotExist(err):
+				delete(inv.entries, path)
+				removed = append(removed, path)
+			case err == nil && info.ModTime().After(scan.started):
+				// 스캔이 지나간 뒤에 생긴 파일은 모니터링 중의 이벤트로 이미 반영됨
+			default:
+				// 필터가 바뀌어 대상에서 빠졌을 뿐 파일이 남아 있으면 삭제로 보지 않음
+				delete(inv.entries, path)
+			}
+			continue
+		}
+		if after.Size == before.Size && after.ModTime.Equal(before.ModTime) {
+			continue
+		}
+		// 수정 시간만 바뀌고 내용이 같으면 수정으로 보지 않음
+		if before.SHA256 == "" || after.SHA256 != before.SHA256 {
+			modified = append(modified, path)
+		}
+	}
+	for path, entry := range current {
+		if _, ok := inv.entries[path]; !ok {
+			created = append(created, path)
+		}
+		inv.entries[path] = entry
+	}
+	inv.devices[device] = true
+	inv.mu.Unlock()
+
+	if !known {
+		log.Printf("인벤토리 생성: %s (파일 %d개)", device, len(current))
+		return
+	}
+
+	sort.Strings(created)
+	sort.Strings(removed)
+	sort.Strings(modified)
+	log.Printf("중지된 동안의 변경 조정: %s (생성 %d개, 삭제 %d개, 수정 %d개)", device, len(created), len(removed), len(modified))
+
+	now := time.Now()
+	record := func(path, operation, selectOp string) {
+		if !filters.forPath(path).operations[selectOp] {
+			return
+		}
+		event := FileEvent{
+			Path:         path,
+			Operation:    operation,
+			Timestamp:    now,
+			FileType:     strings.ToLower(filepath.Ext(path)),
+			DetectedType: detected[path],
+			Reconciled:   true,
+		}
+		if operation == OperationModified {
+			event.LastWrite = current[path].ModTime
+		}
+		log.Printf("[중요] 중지된 동안의 변경: %s %s", operation, path)
+		m.recordEvent(event)
+	}
+	for _, path := range removed {
+		record(path, OperationRemove, OperationRemove)
+	}
+	for _, path := range created {
+		record(path, OperationCreate, OperationCreate)
+	}
+	for _, path := range modified {
+		record(path, OperationModified, OperationWrite)
+	}
+}
+
+// inventoryHash는 해시 작업자 풀에서 계산한 인벤토리 항목의 SHA-256입니다.
+type inventoryHash struct {
+	path   string
+	sha256 string
+}
+
+// hashInventory는 paths의 SHA-256을 해시 작업자 풀에서 계산하여 current에 기록하고, 모두 끝날 때까지 기다립니다.
+// 대기열이 가득 차면 빈자리가 생길 때까지 기다리며, ctx가 취소되면 false를 반환합니다.
+func (m *Monitor) hashInventory(ctx context.Context, device string, current map[string]InventoryEntry, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+
+	// 작업자가 결과를 전달하다 멈추지 않도록 모든 결과를 담을 수 있는 크기로 만듦
+	results := make(chan inventoryHash, len(paths))
+	submitted := 0
+	for _, path := range paths {
+		job := hashJob{path: path, inventory: func(sha256 string) {
+			results <- inventoryHash{path: path, sha256: sha256}
+		}}
+		if !m.hasher.submitWait(ctx, job) {
+			break
+		}
+		submitted++
+	}
+	for i := 0; i < submitted && ctx.Err() == nil; i++ {
+		select {
+		case r := <-results:
+			entry := current[r.path]
+			entry.SHA256 = r.sha256
+			current[r.path] = entry
+		case <-ctx.Done():
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		log.Printf("인벤토리 스캔 중단: %s - %v", device, err)
+		return false
+	}
+	log.Printf("인벤토리 해시 계산 완료: %s (파일 %d개)", device, submitted)
+	return true
+}
+
+// updateInventory는 모니터링 중에 기록된 이벤트를 인벤토리에 반영합니다.
+func (m *Monitor) updateInventory(event FileEvent) {
+	inv := &m.inventory
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if !inv.loaded {
+		return
+	}
+
+	switch event.Operation {
+	case OperationCreate, OperationModified:
+		m.putInventoryEntry(event.Path)
+	case OperationMove:
+		delete(inv.entries, event.OldPath)
+		m.putInventoryEntry(event.NewPath)
+	case OperationRemove, OperationRename:
+		delete(inv.entries, event.Path)
+	}
+}
+
+// putInventoryEntry는 파일의 현재 상태를 인벤토리에 기록합니다. inventory.mu를 잡은 상태에서 호출해야 합니다.
+func (m *Monitor) putInventoryEntry(path string) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	m.inventory.entries[path] = InventoryEntry{
+		Path:    path,
+		Device:  m.deviceOf(path),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+}
+
+// updateInventoryHash는 계산된 해시를 인벤토리 항목에 기록합니다. 그 사이에 파일이 바뀌었으면 무시합니다.
+func (m *Monitor) updateInventoryHash(hashes FileHashes) {
+	inv := &m.inventory
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if e, ok := inv.entries[hashes.Path]; ok && e.Size == hashes.Size && hashes.SHA256 != "" {
+		e.SHA256 = hashes.SHA256
+		inv.entries[hashes.Path] = e
+	}
+}
+
+// forgetInventoryDevice는 제거된 장치의 인벤토리를 지웁니다. 다른 장치에 포함된 파일은 유지합니다.
+func (m *Monitor) forgetInventoryDevice(device string) {
+	inv := &m.inventory
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	delete(inv.devices, device)
+	for path := range inv.entries {
+		if isUnderPath(path, device) && !m.isDevicePath(path) {
+			delete(inv.entries, path)
+		}
+	}
+}
+
+// deviceOf는 경로를 포함하는 가장 깊은 장치를 반환합니다.
+func (m *Monitor) deviceOf(path string) string {
+	m.devicesMutex.RLock()
+	defer m.devicesMutex.RUnlock()
+	root := ""
+	for _, device := range m.devices {
+		if len(device) > len(root) && isUnderPath(path, device) {
+			root = device
+		}
+	}
+	return root
+}
+
+// sha256File은 파일의 SHA-256을 계산합니다. 읽을 수 없거나 maxSize보다 크면 빈 문자열을 반환합니다.
+func sha256File(path string, maxSize int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.Size() > maxSize {
+		return ""
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
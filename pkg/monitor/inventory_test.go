@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReconcileOfflineChanges(t *testing.T) {
+	dir := t.TempDir()
+	watchDir := filepath.Join(dir, "watch")
+	os.Mkdir(watchDir, 0755)
+	dbPath := filepath.Join(dir, "test.db")
+	for _, name := range []string{"a.exe", "b.exe", "c.dll", "notes.txt"} {
+		os.WriteFile(filepath.Join(watchDir, name), []byte("MZ"), 0644)
+	}
+
+	start := func() *Monitor {
+		mon := NewMonitor(time.Hour)
+		mon.SetBackend(NewFakeBackend())
+		mon.SetDatabasePath(dbPath)
+		mon.SetOperations([]string{"CREATE", "REMOVE", "WRITE"})
+		mon.AddDevice(watchDir)
+		if err := mon.Start(); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		return mon
+	}
+
+	// 처음 감시하는 장치는 인벤토리만 만들고 이벤트를 기록하지 않음
+	mon := start()
+	time.Sleep(200 * time.Millisecond)
+	mon.Stop()
+	if events := mon.GetFileEvents(); len(events) != 0 {
+		t.Fatalf("Expected no events on first run, got %+v", events)
+	}
+
+	// 중지된 동안의 변경
+	os.Remove(filepath.Join(watchDir, "a.exe"))
+	os.WriteFile(filepath.Join(watchDir, "b.exe"), []byte("MZ modified"), 0644)
+	os.WriteFile(filepath.Join(watchDir, "d.exe"), []byte("MZ"), 0644)
+	os.WriteFile(filepath.Join(watchDir, "other.txt"), []byte("ignored"), 0644)
+
+	mon = start()
+	events := waitEvents(t, mon, 3)
+	mon.Stop()
+
+	want := []struct{ op, name string }{
+		{OperationRemove, "a.exe"},
+		{OperationCreate, "d.exe"},
+		{OperationModified, "b.exe"},
+	}
+	for i, w := range want {
+		if events[i].Operation != w.op || events[i].Path != filepath.Join(watchDir, w.name) || !events[i].Reconciled {
+			t.Errorf("Event %d: expected reconciled %s %s, got %+v", i, w.op, w.name, events[i])
+		}
+	}
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+	stored, err := db.GetFileEvents()
+	if err != nil {
+		t.Fatalf("GetFileEvents failed: %v", err)
+	}
+	if len(stored) != 3 || !stored[0].Reconciled {
+		t.Errorf("Expected 3 reconciled events in database, got %+v", stored)
+	}
+
+	entries, devices, err := db.LoadInventory()
+	if err != nil {
+		t.Fatalf("LoadInventory failed: %v", err)
+	}
+	if len(entries) != 3 || len(devices) != 1 || devices[0] != watchDir {
+		t.Errorf("Unexpected inventory: %+v %v", entries, devices)
+	}
+}
+
+func TestReconcileHashesInventory(t *testing.T) {
+	dir := t.TempDir()
+	watchDir := filepath.Join(dir, "watch")
+	os.Mkdir(watchDir, 0755)
+	dbPath := filepath.Join(dir, "test.db")
+	small := []byte("MZ small")
+	os.WriteFile(filepath.Join(watchDir, "a.exe"), small, 0644)
+	os.WriteFile(filepath.Join(watchDir, "big.exe"), make([]byte, 64), 0644)
+	// 해시 작업자가 아직 쓰이는 중인 파일로 보고 기다리지 않도록 수정 시간을 과거로 설정
+	earlier := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(watchDir, "a.exe"), earlier, earlier)
+	os.Chtimes(filepath.Join(watchDir, "big.exe"), earlier, earlier)
+	sum := sha256.Sum256(small)
+	want := hex.EncodeToString(sum[:])
+
+	start := func() *Monitor {
+		mon := NewMonitor(time.Hour)
+		mon.SetBackend(NewFakeBackend())
+		mon.SetDatabasePath(dbPath)
+		mon.SetOperations([]string{"CREATE", "REMOVE", "WRITE"})
+		mon.SetHashMaxSize(32)
+		mon.AddDevice(watchDir)
+		if err := mon.Start(); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		return mon
+	}
+	inventoryHashes := func() map[string]string {
+		db, err := NewDatabase(dbPath)
+		if err != nil {
+			t.Fatalf("NewDatabase failed: %v", err)
+		}
+		defer db.Close()
+		entries, _, err := db.LoadInventory()
+		if err != nil {
+			t.Fatalf("LoadInventory failed: %v", err)
+		}
+		hashes := make(map[string]string)
+		for _, e := range entries {
+			hashes[filepath.Base(e.Path)] = e.SHA256
+		}
+		return hashes
+	}
+
+	// 처음 감시하는 장치는 해시를 계산하지 않고 크기와 수정 시간만 기록
+	mon := start()
+	time.Sleep(200 * time.Millisecond)
+	mon.Stop()
+	hashes := inventoryHashes()
+	if hashes["a.exe"] != "" || hashes["big.exe"] != "" || len(hashes) != 2 {
+		t.Fatalf("Expected inventory without hashes on first run, got %v", hashes)
+	}
+
+	// 모니터링 중에 이벤트가 없었던 파일도 다음 조정 스캔에서 해시가 계산됨 (최대 크기 초과 파일은 제외)
+	mon = start()
+	time.Sleep(200 * time.Millisecond)
+	mon.Stop()
+	hashes = inventoryHashes()
+	if hashes["a.exe"] != want || hashes["big.exe"] != "" || len(hashes) != 2 {
+		t.Fatalf("Expected a.exe hashed and big.exe without hash, got %v", hashes)
+	}
+
+	// 수정 시간만 바뀌고 내용이 같으면 수정으로 보지 않음
+	later := earlier.Add(time.Minute)
+	os.Chtimes(filepath.Join(watchDir, "a.exe"), later, later)
+	mon = start()
+	time.Sleep(200 * time.Millisecond)
+	mon.Stop()
+	if events := mon.GetFileEvents(); len(events) != 0 {
+		t.Errorf("Expected no events for a touched file, got %+v", events)
+	}
+	if hashes := inventoryHashes(); hashes["a.exe"] != want {
+		t.Errorf("Expected a.exe hash to be kept, got %v", hashes)
+	}
+}
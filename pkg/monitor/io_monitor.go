@@ -1,64 +1,166 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/fsnotify/fsnotify"
 )
 
 // FileEvent는 파일 이벤트 정보를 저장하는 구조체입니다.
+// MOVE 이벤트의 경우 Path는 새 경로이며, OldPath와 NewPath에 이동 전후 경로가 기록됩니다.
+// MODIFIED 이벤트의 경우 묶인 쓰기의 첫/마지막 시간과 횟수가 기록됩니다.
+// FileType은 확장자, DetectedType은 파일 내용의 매직 바이트로 판별한 유형(예: "pe")입니다. DetectedType은 유형 필터를
+// 확인하느라 파일이 안정된 뒤에 기록된 이벤트에만 설정되며, 그 외에는 작업자가 파일이 안정된 뒤에 판별하여
+// 저장 시 detected_type에 기록하고 WaitDetectedType으로 조회할 수 있습니다.
+// Metadata는 이벤트와 함께 저장할 키/값입니다. 모니터가 추출하는 PE 메타데이터는 파일이 안정된 뒤
+// 작업자가 분석하므로 이벤트에는 설정되지 않고 EventID로 연결되어 따로 저장됩니다.
+// ID는 기록 시 부여되는 고유 번호로, 나중에 계산된 해시(FileHashes.EventID)와 연결됩니다.
+// Operation이 OperationAlert인 이벤트는 파일 이벤트가 아니라 Alert를 전달하기 위한 것으로,
+// ID가 0이고 file_events에 저장되지 않습니다.
 type FileEvent struct {
-	Path      string
-	Operation string
-	Timestamp time.Time
-	FileType  string
+	ID           int64
+	Path         string
+	Operation    string
+	Timestamp    time.Time
+	FileType     string
+	DetectedType string
+	OldPath      string
+	NewPath      string
+	FirstWrite   time.Time
+	LastWrite    time.Time
+	WriteCount   int
+	Metadata     map[string]string
+	Reconciled   bool         // 모니터가 중지된 동안의 변경을 시작 시 인벤토리와 비교하여 찾아낸 이벤트
+	Stat         *FileStat    // CREATE/MOVE/MODIFIED 시점의 파일 상태 (조회하지 못했으면 nil)
+	Process      *ProcessInfo // 이벤트를 일으킨 프로세스 (fanotify 백엔드에서만 제공)
+	Alert        *Alert       // 대량 변경 경고 (OperationAlert 이벤트에만 설정)
 }
 
 // Monitor는 파일 모니터링을 담당하는 구조체입니다.
 type Monitor struct {
-	interval    time.Duration
-	devices     []string
-	fileEvents  []FileEvent
-	running     bool
-	watcher     *fsnotify.Watcher
-	watchMutex  sync.Mutex
-	fileFilters []string
-	db          *Database
-	dbPath      string
-	saveTimer   *time.Ticker
-	eventsMutex sync.Mutex
-	eventChan   chan FileEvent
+	interval       time.Duration
+	devices        []string
+	devicesMutex   sync.RWMutex
+	deviceWalks    map[string]*deviceWalk
+	watched        map[string]bool
+	uncovered      map[string]bool // 감시 등록에 실패한 디렉토리
+	stopping       bool
+	fileEvents     []FileEvent
+	running        bool
+	runMutex       sync.Mutex
+	runCtx         context.Context
+	cancel         context.CancelFunc
+	runDone        chan struct{}
+	fatal          chan error
+	walkers        sync.WaitGroup
+	saverDone      chan struct{}
+	backend        WatcherBackend
+	watcher        *backendRouter
+	deviceBackends map[string]string
+	pollingOptions PollingOptions
+	watchMutex     sync.Mutex
+	filterMutex    sync.Mutex
+	filterSnapshot atomic.Pointer[filterSet]
+	db             *Database
+	dbPath         string
+	eventsMutex    sync.Mutex
+	eventSub       *Subscription
+	subsMutex      sync.Mutex
+	subscribers    []*Subscription
+	subsClosed     bool
+	renames        renameTracker
+	writes         writeCoalescer
+	hasher         *hashPool
+	scanner        *scanPool
+	analyzer       *analyzePool
+	inventory      inventory
+	fileHashes     []FileHashes
+	recentHashes   eventResults[FileHashes] // WaitFileHashes로 조회할 최근 해시
+	recentTypes    eventResults[string]     // WaitDetectedType으로 조회할 최근 판별 유형
+	sigMatches     []SignatureMatch         // 저장 대기 중인 시그니처 일치 결과
+	eventMetadata  []EventMetadata          // 저장 대기 중인 파일 분석 결과
+	quarantine     quarantiner
+	lastEventID    int64
+	idRemap        map[int64]int64 // 다시 부여된 이벤트 ID (이전 ID → 새 ID)
+	bursts         burstDetector
+	alerts         []Alert // 저장 대기 중인 경고
+	lastAlertID    int64
+	processDone    chan struct{}
 }
 
 // NewMonitor는 새로운 모니터 인스턴스를 생성합니다.
 func NewMonitor(interval time.Duration) *Monitor {
-	return &Monitor{
-		interval:    interval,
-		devices:     []string{},
-		fileEvents:  []FileEvent{},
-		running:     false,
-		fileFilters: []string{".exe", ".dll"},  // 기본 필터
-		dbPath:      "monitor.db",              // 기본 데이터베이스 경로
-		eventChan:   make(chan FileEvent, 100), // 이벤트 채널 버퍼 크기 100
+	mon := &Monitor{
+		interval:       interval,
+		devices:        []string{},
+		deviceBackends: make(map[string]string),
+		fileEvents:     []FileEvent{},
+		running:        false,
+		dbPath:         "monitor.db", // 기본 데이터베이스 경로
+		renames:        renameTracker{window: defaultRenameWindow},
+		writes:         newWriteCoalescer(defaultWriteSettle),
+		hasher:         newHashPool(),
+		scanner:        newScanPool(),
+		analyzer:       newAnalyzePool(),
+		inventory:      inventory{enabled: true},
+		bursts:         newBurstDetector(),
 	}
-}
-
-// AddDevice는 모니터링할 장치를 추가합니다.
-func (m *Monitor) AddDevice(device string) {
-	m.devices = append(m.devices, device)
-	log.Printf("장치 추가됨: %s", device)
+	// EventChan으로 제공되는 기본 구독 (버퍼 100, 가득 차면 새 이벤트 버림)
+	mon.eventSub = &Subscription{
+		mon:  mon,
+		opts: SubscribeOptions{Buffer: defaultSubscriptionBuffer, Overflow: OverflowDropNewest},
+		ch:   make(chan FileEvent, defaultSubscriptionBuffer),
+	}
+	mon.subscribers = []*Subscription{mon.eventSub}
+	// 기본 필터 (.exe, .dll 생성/삭제/이름 변경)
+	filters, _ := compileFilterConfig(defaultFilterConfig())
+	mon.filterSnapshot.Store(filters)
+	return mon
 }
 
 // SetFileFilters는 모니터링할 파일 확장자 필터를 설정합니다.
-func (m *Monitor) SetFileFilters(filters []string) {
-	m.fileFilters = filters
+// 빈 목록을 설정하면 확장자와 관계없이 모든 파일이 대상이 됩니다.
+// 설정이 올바르지 않으면 기존 필터를 유지하고 오류를 반환합니다.
+func (m *Monitor) SetFileFilters(filters []string) error {
+	err := m.updateFilters(func(cfg *FilterConfig) { cfg.FileFilters = filters })
+	if err != nil {
+		return err
+	}
 	log.Printf("파일 필터 설정됨: %v", filters)
+	return nil
+}
+
+// SetTypeFilters는 매직 바이트로 감지된 콘텐츠 유형(예: "pe", "elf") 필터를 설정합니다.
+// 확장자 필터와 함께 사용되며, 둘 중 하나라도 일치하면 대상이 됩니다.
+// 따라서 확장자가 .dat인 PE 파일도 "pe" 유형 필터로 감지할 수 있습니다.
+// 설정이 올바르지 않으면 기존 필터를 유지하고 오류를 반환합니다.
+func (m *Monitor) SetTypeFilters(types []string) error {
+	err := m.updateFilters(func(cfg *FilterConfig) { cfg.TypeFilters = types })
+	if err != nil {
+		return err
+	}
+	log.Printf("유형 필터 설정됨: %v", m.GetTypeFilters())
+	return nil
+}
+
+// SetPathRule은 파일 유형 필터와 함께 적용할 경로 포함/제외 규칙을 설정합니다.
+// 이벤트는 파일 유형 필터와 경로 규칙을 모두 만족해야 기록됩니다. nil이면 경로 규칙을 사용하지 않습니다.
+// 규칙은 복사되어 저장되므로 이후에 rule을 수정해도 적용되지 않습니다.
+// 규칙이 올바르지 않으면 기존 규칙을 유지하고 오류를 반환합니다.
+func (m *Monitor) SetPathRule(rule *PathRule) error {
+	err := m.updateFilters(func(cfg *FilterConfig) { cfg.PathRule = rule })
+	if err != nil {
+		return err
+	}
+	log.Printf("경로 규칙 설정됨: %s", rule.String())
+	return nil
 }
 
 // SetDatabasePath는 데이터베이스 파일 경로를 설정합니다.
@@ -66,101 +168,312 @@ func (m *Monitor) SetDatabasePath(path string) {
 	m.dbPath = path
 }
 
+// SetBackend는 파일 시스템 이벤트를 공급할 감시 백엔드를 설정합니다.
+// 설정하지 않으면 Start 시 fsnotify 기반 백엔드가 사용됩니다.
+func (m *Monitor) SetBackend(backend WatcherBackend) {
+	m.backend = backend
+}
+
+// SetRenameWindow는 이름 변경(Rename)과 새 이름의 생성(Create)을 하나의 MOVE 이벤트로
+// 묶을 최대 시간 간격을 설정합니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetRenameWindow(window time.Duration) {
+	if window <= 0 {
+		window = defaultRenameWindow
+	}
+	m.renames.window = window
+}
+
+// SetWriteSettle는 같은 파일의 연속된 쓰기를 하나의 MODIFIED 이벤트로 묶기 위한
+// 대기 시간을 설정합니다. 마지막 쓰기 이후 이 시간 동안 쓰기가 없으면 이벤트가 기록됩니다.
+// Start 전에 호출해야 합니다.
+func (m *Monitor) SetWriteSettle(settle time.Duration) {
+	if settle <= 0 {
+		settle = defaultWriteSettle
+	}
+	m.writes.settle = settle
+}
+
 // watchRecursive는 디렉터리를 재귀적으로 watcher에 등록하는 함수입니다.
-func (m *Monitor) watchRecursive(path string) error {
-	log.Printf("재귀적 감시 시작: %s\n", path)
+// 감시 제외 패턴과 일치하는 하위 디렉토리는 그 아래 전체를 건너뜁니다.
+// visit가 nil이 아니면 탐색 중에 만난 일반 파일마다 호출하며, 하위 디렉토리까지 감시하는 백엔드도
+// 루트를 등록한 뒤 파일을 방문하기 위해 탐색합니다. ctx가 취소되면 탐색을 중단하고 ctx의 오류를 반환합니다.
+func (m *Monitor) watchRecursive(ctx context.Context, path string, visit func(path string, d fs.DirEntry)) error {
+	// 하위 디렉토리까지 감시하는 백엔드(fanotify)는 루트만 등록
+	recursive := m.watcher.recursive(path)
+	if recursive {
+		m.watchMutex.Lock()
+		err := m.watcher.Add(path)
+		if err == nil {
+			m.watched[path] = true
+			delete(m.uncovered, path)
+		} else {
+			m.uncovered[path] = true
+		}
+		m.watchMutex.Unlock()
+		if err != nil {
+			return err
+		}
+		log.Printf("재귀적 감시 설정 완료: %s (백엔드가 하위 디렉토리 포함 감시)\n", path)
+		if visit == nil {
+			return nil
+		}
+	} else {
+		log.Printf("재귀적 감시 시작: %s\n", path)
+	}
+
 	count := 0
+	skipped := 0
+	failed := 0
 
-	err := filepath.Walk(path, func(walkPath string, info os.FileInfo, err error) error {
+	err := filepath.WalkDir(path, func(walkPath string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			log.Printf("접근 권한 오류: %s - %v\n", walkPath, err)
 			// 접근 권한이 없는 폴더는 스킵
 			return nil
 		}
 
-		if info.IsDir() {
-			m.watchMutex.Lock()
-			err = m.watcher.Add(walkPath)
-			m.watchMutex.Unlock()
-			if err == nil {
-				count++
-				// 디렉토리 100개마다 로그 출력 (너무 많은 로그 방지)
-				if count%100 == 0 {
-					log.Printf("감시 디렉토리 %d개 추가됨 (최근: %s)\n", count, walkPath)
-				}
-			} else {
-				log.Printf("디렉토리 감시 추가 실패: %s - %v\n", walkPath, err)
+		if !d.IsDir() {
+			if visit != nil && d.Type().IsRegular() {
+				visit(walkPath, d)
+			}
+			return nil
+		}
+		if walkPath != path && m.isExcludedDir(walkPath) {
+			skipped++
+			return filepath.SkipDir
+		}
+		if recursive {
+			return nil
+		}
+
+		m.watchMutex.Lock()
+		err = m.watcher.Add(walkPath)
+		if err == nil {
+			m.watched[walkPath] = true
+			delete(m.uncovered, walkPath)
+		} else {
+			m.uncovered[walkPath] = true
+		}
+		m.watchMutex.Unlock()
+		if err == nil {
+			count++
+			// 디렉토리 100개마다 로그 출력 (너무 많은 로그 방지)
+			if count%100 == 0 {
+				log.Printf("감시 디렉토리 %d개 추가됨 (최근: %s)\n", count, walkPath)
 			}
+		} else {
+			failed++
+			log.Printf("디렉토리 감시 추가 실패: %s - %v\n", walkPath, err)
 		}
 		return nil
 	})
 
-	log.Printf("재귀적 감시 설정 완료: %s (총 %d개 디렉토리)\n", path, count)
+	if recursive {
+		return err
+	}
+	if failed > 0 {
+		log.Printf("[경고] 재귀적 감시 설정 완료: %s (총 %d개 디렉토리, 제외 %d개, 감시 실패 %d개)\n", path, count, skipped, failed)
+	} else {
+		log.Printf("재귀적 감시 설정 완료: %s (총 %d개 디렉토리, 제외 %d개)\n", path, count, skipped)
+	}
 	return err
 }
 
-// Start는 모니터링을 시작합니다.
+// Start는 모니터링을 백그라운드에서 시작하고 초기화가 끝나면 반환합니다.
+// 실행 중 치명적인 오류가 발생하면 로그를 남기고 스스로 중지합니다. Stop으로 중지합니다.
 func (m *Monitor) Start() error {
+	ready := make(chan error, 1)
+	go func() {
+		if err := m.run(context.Background(), ready); err != nil {
+			log.Printf("모니터링이 오류로 중지됨: %v", err)
+		}
+	}()
+	return <-ready
+}
+
+// Run은 ctx가 취소되거나 치명적인 오류가 발생할 때까지 모니터링을 실행합니다.
+// 반환하기 전에 모든 고루틴(장치 탐색, 이벤트 처리, 해시 작업자, 주기적 저장)의 종료를 기다리고
+// 수집된 이벤트를 저장합니다. ctx 취소나 Stop으로 중지되면 nil을, 그 외에는 처음 발생한
+// 치명적인 오류(초기화 실패, 감시 백엔드의 비정상 종료)를 반환합니다.
+func (m *Monitor) Run(ctx context.Context) error {
+	return m.run(ctx, nil)
+}
+
+// run은 모니터링을 초기화하고 종료될 때까지 실행합니다.
+// ready가 nil이 아니면 초기화 결과를 전달합니다.
+func (m *Monitor) run(parent context.Context, ready chan<- error) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	err := m.start(ctx, cancel)
+	if ready != nil {
+		ready <- err
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+	case err = <-m.fatal:
+		log.Printf("치명적인 오류 발생, 모니터링을 중지합니다: %v", err)
+	}
+	m.shutdown(cancel)
+	return err
+}
+
+// start는 데이터베이스와 감시 백엔드를 초기화하고 작업 고루틴을 시작합니다.
+func (m *Monitor) start(ctx context.Context, cancel context.CancelFunc) error {
+	m.runMutex.Lock()
+	defer m.runMutex.Unlock()
+
 	if m.running {
 		return fmt.Errorf("모니터링이 이미 실행 중입니다")
 	}
 
-	if len(m.devices) == 0 {
+	devices := m.GetDevices()
+	if len(devices) == 0 {
 		return fmt.Errorf("모니터링할 장치가 없습니다")
 	}
 
-	// fsnotify 워처 초기화
-	watcher, err := fsnotify.NewWatcher()
+	// 데이터베이스 초기화
+	db, err := NewDatabase(m.dbPath)
 	if err != nil {
-		return fmt.Errorf("파일 시스템 감시자 생성 실패: %v", err)
+		return fmt.Errorf("데이터베이스 초기화 실패: %v", err)
 	}
-	m.watcher = watcher
+	m.db = db
 
-	// 각 장치에 대해 재귀적 감시 설정
-	for _, device := range m.devices {
-		go func(dev string) {
-			log.Printf("%s 장치 모니터링 시작...\n", dev)
-			err := m.watchRecursive(dev)
-			if err != nil {
-				log.Printf("장치 %s 감시 설정 중 오류 발생: %v\n", dev, err)
-			}
-		}(device)
+	// 이벤트 ID는 데이터베이스에 저장된 마지막 ID 다음부터 부여
+	m.lastEventID, err = db.LastEventID()
+	if err != nil {
+		m.db.Close()
+		return fmt.Errorf("이벤트 ID 조회 실패: %v", err)
+	}
+	m.lastAlertID, err = db.LastAlertID()
+	if err != nil {
+		m.db.Close()
+		return fmt.Errorf("경고 ID 조회 실패: %v", err)
 	}
 
-	// 이벤트 처리 고루틴
-	go m.processEvents()
+	// 중지된 동안의 변경을 찾기 위한 인벤토리
+	if err := m.loadInventory(); err != nil {
+		m.db.Close()
+		return fmt.Errorf("인벤토리 불러오기 실패: %v", err)
+	}
 
-	// 데이터베이스 초기화
-	db, err := NewDatabase(m.dbPath)
-	if err != nil {
-		if m.watcher != nil {
-			m.watcher.Close()
+	// 감시 백엔드 초기화 (지정되지 않은 경우 fsnotify 사용)
+	if m.backend == nil {
+		backend, err := NewFsnotifyBackend()
+		if err != nil {
+			m.db.Close()
+			return fmt.Errorf("파일 시스템 감시자 생성 실패: %v", err)
 		}
-		return fmt.Errorf("데이터베이스 초기화 실패: %v", err)
+		m.backend = backend
 	}
-	m.db = db
+	// 장치별 감시 방식에 따라 알림 백엔드와 폴링 백엔드로 나누어 등록
+	m.watcher = newBackendRouter(m.backend, newPollingBackend(m.pollingOptions), m.usesPolling)
+
+	m.runCtx = ctx
+	m.cancel = cancel
+	m.runDone = make(chan struct{})
+	m.fatal = make(chan error, 1)
+	m.stopping = false
+	m.deviceWalks = make(map[string]*deviceWalk)
+	m.watched = make(map[string]bool)
+	m.uncovered = make(map[string]bool)
+
+	// 해시 작업자 시작 (중지된 동안의 변경 조정은 장치 탐색 중에 인벤토리 해시를 요청하므로 먼저 시작)
+	if m.hasher.enabled() || m.inventory.enabled {
+		m.hasher.start(m.recordHashes, m.recentHashes.finish)
+	}
+	if m.scanner.enabled() {
+		m.scanner.start(m.recordSignatureMatches)
+	}
+	m.analyzer.start(m.recordMetadata, m.recentTypes.finish)
+
+	// 각 장치에 대해 재귀적 감시 설정 (취소되면 탐색 중단)
+	for _, device := range devices {
+		m.startWalk(device)
+	}
+
+	// 이벤트 처리 고루틴
+	m.processDone = make(chan struct{})
+	go m.processEvents()
+
+	// 주기적으로 데이터베이스에 저장
+	m.saverDone = make(chan struct{})
+	go m.periodicSave(ctx)
 
 	m.running = true
 	log.Println("파일 모니터링 시작됨")
+	return nil
+}
 
-	// 타이머를 사용하여 주기적으로 데이터베이스에 저장
-	m.saveTimer = time.NewTicker(m.interval)
-	go m.periodicSave()
+// shutdown은 모든 고루틴을 순서대로 종료하고 남은 이벤트를 저장합니다.
+func (m *Monitor) shutdown(cancel context.CancelFunc) {
+	// 장치 탐색을 중단하고 더 이상 감시 대상이 추가되지 않을 때까지 대기
+	m.runMutex.Lock()
+	m.stopping = true
+	m.runMutex.Unlock()
+	cancel()
+	m.walkers.Wait()
+
+	// 감시 백엔드를 닫고 보류 중인 이벤트가 모두 기록될 때까지 대기
+	m.watcher.Close()
+	<-m.processDone
+
+	// 해시 계산과 시그니처 검사, 파일 분석 중인 작업이 모두 끝날 때까지 대기
+	m.hasher.close()
+	m.scanner.close()
+	m.analyzer.close()
+
+	// 주기적 저장이 끝난 뒤 마지막으로 데이터베이스에 저장
+	<-m.saverDone
+	m.saveEventsToDatabase()
+	m.saveInventory()
 
-	return nil
+	// 데이터베이스 연결 종료
+	m.db.Close()
+
+	// 모든 구독 채널 닫기
+	m.closeSubscribers()
+
+	m.runMutex.Lock()
+	m.running = false
+	close(m.runDone)
+	m.runMutex.Unlock()
+
+	log.Println("파일 모니터링 중지됨")
+}
+
+// reportFatal은 모니터링을 중지해야 하는 오류를 알립니다. 처음 발생한 오류만 보관됩니다.
+func (m *Monitor) reportFatal(err error) {
+	select {
+	case m.fatal <- err:
+	default:
+	}
 }
 
 // periodicSave는 주기적으로 수집된 이벤트를 데이터베이스에 저장합니다.
-func (m *Monitor) periodicSave() {
-	for range m.saveTimer.C {
-		if !m.running {
-			return
-		}
+func (m *Monitor) periodicSave(ctx context.Context) {
+	defer close(m.saverDone)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
 
-		// 새로운 이벤트 저장
-		m.saveEventsToDatabase()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// 새로운 이벤트 저장
+			m.saveEventsToDatabase()
 
-		log.Printf("데이터베이스에 저장 완료 (interval: %s)\n", m.interval)
+			log.Printf("데이터베이스에 저장 완료 (interval: %s)\n", m.interval)
+		}
 	}
 }
 
@@ -172,25 +485,140 @@ func (m *Monitor) saveEventsToDatabase() {
 
 	m.eventsMutex.Lock()
 	events := m.fileEvents
+	hashes := m.fileHashes
+	signatureMatches := m.sigMatches
+	metadata := m.eventMetadata
+	alerts := m.alerts
 	m.fileEvents = []FileEvent{} // 저장 후 이벤트 목록 초기화
+	m.fileHashes = nil
+	m.sigMatches = nil
+	m.eventMetadata = nil
+	m.alerts = nil
 	m.eventsMutex.Unlock()
 
-	if len(events) == 0 {
-		return
+	if len(alerts) > 0 {
+		if err := m.db.SaveAlerts(alerts); err != nil {
+			log.Printf("경고 저장 중 오류 발생: %v\n", err)
+			m.eventsMutex.Lock()
+			m.alerts = append(alerts, m.alerts...)
+			m.eventsMutex.Unlock()
+		}
 	}
 
-	// 일괄 저장
-	err := m.db.SaveBatchFileEvents(events)
+	if len(events) > 0 {
+		// 일괄 저장
+		err := m.db.SaveBatchFileEvents(events)
+		if isEventIDConflict(err) {
+			// 다른 프로세스나 가져오기가 같은 이벤트 ID를 먼저 사용한 경우 ID를 다시 부여하여 한 번 더 시도
+			log.Printf("이벤트 ID가 이미 사용 중입니다. ID를 다시 부여합니다: %v", err)
+			if err = m.reassignEventIDs(events, hashes, signatureMatches, metadata); err == nil {
+				err = m.db.SaveBatchFileEvents(events)
+			}
+		}
+		if err != nil {
+			log.Printf("이벤트 저장 중 오류 발생: %v\n", err)
+
+			// 오류 발생 시 이벤트와 해시, 시그니처 일치 결과, 파일 분석 결과 복원 (연결된 이벤트보다 먼저 저장하지 않음)
+			m.eventsMutex.Lock()
+			m.fileEvents = append(events, m.fileEvents...)
+			m.fileHashes = append(hashes, m.fileHashes...)
+			m.sigMatches = append(signatureMatches, m.sigMatches...)
+			m.eventMetadata = append(metadata, m.eventMetadata...)
+			m.eventsMutex.Unlock()
+			return
+		}
+		log.Printf("%d개의 이벤트가 데이터베이스에 저장되었습니다.\n", len(events))
+	}
+
+	if len(hashes) > 0 {
+		if err := m.db.SaveFileHashes(hashes); err != nil {
+			log.Printf("해시 저장 중 오류 발생: %v\n", err)
+			m.eventsMutex.Lock()
+			m.fileHashes = append(hashes, m.fileHashes...)
+			m.eventsMutex.Unlock()
+		}
+	}
+
+	if len(signatureMatches) > 0 {
+		if err := m.db.SaveSignatureMatches(signatureMatches); err != nil {
+			log.Printf("시그니처 일치 결과 저장 중 오류 발생: %v\n", err)
+			m.eventsMutex.Lock()
+			m.sigMatches = append(signatureMatches, m.sigMatches...)
+			m.eventsMutex.Unlock()
+		}
+	}
+
+	if len(metadata) > 0 {
+		if err := m.db.SaveEventMetadata(metadata); err != nil {
+			log.Printf("파일 분석 결과 저장 중 오류 발생: %v\n", err)
+			m.eventsMutex.Lock()
+			m.eventMetadata = append(metadata, m.eventMetadata...)
+			m.eventsMutex.Unlock()
+		}
+	}
+}
+
+// reassignEventIDs는 저장하지 못한 이벤트와 아직 저장 대기 중인 이벤트에 데이터베이스와 모니터가
+// 사용한 적 없는 ID를 다시 부여하고, 연결된 해시, 시그니처 일치 결과, 파일 분석 결과도 새 ID로 옮깁니다.
+// 작업자가 이전 ID로 나중에 전달하는 결과도 새 ID로 연결되도록 변경 내역을 idRemap에 보관합니다.
+// 이미 구독자에게 전달된 이벤트의 ID는 바뀌지 않습니다.
+func (m *Monitor) reassignEventIDs(events []FileEvent, hashes []FileHashes, matches []SignatureMatch, metadata []EventMetadata) error {
+	last, err := m.db.LastEventID()
 	if err != nil {
-		log.Printf("이벤트 저장 중 오류 발생: %v\n", err)
+		return err
+	}
 
-		// 오류 발생 시 이벤트 복원
-		m.eventsMutex.Lock()
-		m.fileEvents = append(events, m.fileEvents...)
-		m.eventsMutex.Unlock()
-	} else {
-		log.Printf("%d개의 이벤트가 데이터베이스에 저장되었습니다.\n", len(events))
+	m.eventsMutex.Lock()
+	defer m.eventsMutex.Unlock()
+
+	// 이전에 부여한 적 있는 ID와 겹치지 않도록 둘 중 큰 값 다음부터 부여
+	next := max(last, m.lastEventID)
+	round := make(map[int64]int64)
+	for _, pending := range [][]FileEvent{events, m.fileEvents} {
+		for i := range pending {
+			next++
+			round[pending[i].ID] = next
+			pending[i].ID = next
+		}
+	}
+	m.lastEventID = next
+
+	if m.idRemap == nil {
+		m.idRemap = make(map[int64]int64)
+	}
+	for old, id := range m.idRemap {
+		if newID, ok := round[id]; ok {
+			m.idRemap[old] = newID
+		}
 	}
+	for old, newID := range round {
+		m.idRemap[old] = newID
+	}
+
+	for _, pending := range [][]FileHashes{hashes, m.fileHashes} {
+		for i := range pending {
+			pending[i].EventID = m.remapEventID(pending[i].EventID)
+		}
+	}
+	for _, pending := range [][]SignatureMatch{matches, m.sigMatches} {
+		for i := range pending {
+			pending[i].EventID = m.remapEventID(pending[i].EventID)
+		}
+	}
+	for _, pending := range [][]EventMetadata{metadata, m.eventMetadata} {
+		for i := range pending {
+			pending[i].EventID = m.remapEventID(pending[i].EventID)
+		}
+	}
+	return nil
+}
+
+// remapEventID는 ID가 다시 부여된 이벤트이면 새 ID를 반환합니다. eventsMutex를 잡은 상태에서 호출해야 합니다.
+func (m *Monitor) remapEventID(id int64) int64 {
+	if newID, ok := m.idRemap[id]; ok {
+		return newID
+	}
+	return id
 }
 
 // isDirectory는 주어진 경로가 디렉토리인지 확인합니다.
@@ -202,150 +630,458 @@ func isDirectory(path string) bool {
 	return info.IsDir()
 }
 
-// processEvents는 파일 시스템 이벤트를 처리합니다.
+// processEvents는 감시 백엔드의 이벤트를 읽어 처리합니다.
+// 백엔드가 닫혀 채널이 닫히면 보류 중인 이벤트를 모두 기록한 뒤 종료합니다.
 func (m *Monitor) processEvents() {
 	log.Println("파일 이벤트 처리 고루틴 시작")
+	defer close(m.processDone)
+	defer m.flushPending()
+
+	events := m.watcher.Events()
+	errors := m.watcher.Errors()
+
+	// 짝을 찾지 못한 Rename과 안정된 쓰기 묶음을 주기적으로 정리
+	flushInterval := m.renames.window
+	if m.writes.settle < flushInterval {
+		flushInterval = m.writes.settle
+	}
+	flushTicker := time.NewTicker(flushInterval / 2)
+	defer flushTicker.Stop()
+
 	for {
 		select {
-		case event, ok := <-m.watcher.Events:
+		case event, ok := <-events:
+			if !ok {
+				m.backendClosed()
+				return
+			}
+			m.handleEvent(event)
+
+		case err, ok := <-errors:
 			if !ok {
+				m.backendClosed()
 				return
 			}
+			log.Printf("감시자 오류: %v", err)
 
-			// 이벤트 로깅 (디버깅)
-			log.Printf("원시 이벤트 감지됨: %s, 작업: %s", event.Name, event.Op.String())
+		case now := <-flushTicker.C:
+			for _, p := range m.renames.expire(now) {
+				m.handleOrphanRename(p)
+			}
+			for _, burst := range m.writes.settled(now) {
+				m.recordBurst(burst)
+			}
+			m.bursts.expire(now)
+		}
+	}
+}
 
-			// 파일 확장자 확인
-			ext := strings.ToLower(filepath.Ext(event.Name))
+// backendClosed는 감시 백엔드의 채널이 닫혔을 때 호출됩니다.
+// 종료 중이 아닌데 닫혔다면 더 이상 이벤트를 받을 수 없으므로 치명적인 오류입니다.
+func (m *Monitor) backendClosed() {
+	if m.runCtx.Err() == nil {
+		m.reportFatal(fmt.Errorf("감시 백엔드가 예기치 않게 종료되었습니다"))
+	}
+}
 
-			// 필터 로깅 (디버깅)
-			log.Printf("파일: %s, 확장자: %s, 필터: %v", event.Name, ext, m.fileFilters)
+// flushPending은 보류 중인 Rename과 쓰기 묶음을 시간과 관계없이 모두 기록합니다.
+func (m *Monitor) flushPending() {
+	for _, p := range m.renames.flush() {
+		m.handleOrphanRename(p)
+	}
+	for _, burst := range m.writes.flush() {
+		m.recordBurst(burst)
+	}
+}
 
-			// 이벤트 필터링 (확장자 기준)
-			matched := false
-			for _, filter := range m.fileFilters {
-				if ext == filter {
-					matched = true
-					break
-				}
-			}
+// handleEvent는 단일 파일 시스템 이벤트를 필터링하고 분류하여 기록합니다.
+func (m *Monitor) handleEvent(event WatchEvent) {
+	// 이벤트 로깅 (디버깅)
+	log.Printf("원시 이벤트 감지됨: %s, 작업: %s", event.Name, event.Op.String())
+	now := time.Now()
 
-			if !matched {
-				log.Printf("필터와 일치하지 않아 무시됨: %s (확장자: %s)", event.Name, ext)
-				continue
-			}
+	// 이벤트 하나는 같은 필터 설정으로 처리 (처리 중 설정이 교체되어도 섞이지 않음)
+	filters := m.filters().forPath(event.Name)
 
-			var operation string
-			var createEvent, removeEvent bool
-
-			// fsnotify 작업 처리
-			switch {
-			case event.Op&fsnotify.Create == fsnotify.Create:
-				operation = "CREATE"
-				createEvent = true
-				log.Printf("[중요] 파일 생성 감지됨: %s", event.Name)
-
-			case event.Op&fsnotify.Remove == fsnotify.Remove:
-				operation = "REMOVE"
-				removeEvent = true
-				log.Printf("[중요] 파일 삭제 감지됨: %s", event.Name)
-
-			case event.Op&fsnotify.Rename == fsnotify.Rename:
-				// Rename은 일반적으로 REMOVE와 CREATE로 처리됨
-				log.Printf("파일 이름 변경 감지됨: %s", event.Name)
-				operation = "RENAME"
-
-			case event.Op&fsnotify.Write == fsnotify.Write:
-				log.Printf("파일 쓰기 감지됨: %s", event.Name)
-				operation = "WRITE"
-
-			case event.Op&fsnotify.Chmod == fsnotify.Chmod:
-				log.Printf("파일 권한 변경 감지됨: %s", event.Name)
-				operation = "CHMOD"
-
-			default:
-				log.Printf("알 수 없는 작업 감지됨: %s (%s)", event.Name, event.Op.String())
-				continue
-			}
+	// 제거된 장치에서 감시 해제 전에 도착한 이벤트는 무시
+	if !m.isDevicePath(event.Name) {
+		return
+	}
 
-			// CREATE 또는 REMOVE 이벤트만 처리
-			if createEvent || removeEvent {
-				log.Printf("파일 %s: %s (타입: %s)\n", operation, event.Name, ext)
-
-				// 파일 이벤트 생성
-				fileEvent := FileEvent{
-					Path:      event.Name,
-					Operation: operation,
-					Timestamp: time.Now(),
-					FileType:  ext,
-				}
-
-				// 이벤트 기록
-				m.eventsMutex.Lock()
-				m.fileEvents = append(m.fileEvents, fileEvent)
-				m.eventsMutex.Unlock()
-
-				// 이벤트 채널로 전송
-				select {
-				case m.eventChan <- fileEvent:
-					// 이벤트 전송 성공
-				default:
-					// 채널이 가득 찬 경우 (논블로킹)
-					log.Printf("이벤트 채널이 가득 참: %s", event.Name)
-				}
-
-				// 새 디렉터리가 생성된 경우 감시 대상에 추가
-				if createEvent && isDirectory(event.Name) {
-					log.Printf("새 디렉터리 감지됨, 감시 대상에 추가: %s", event.Name)
-					m.watchMutex.Lock()
-					err := m.watchRecursive(event.Name)
-					if err != nil {
-						log.Printf("새 디렉터리 감시 설정 실패: %v", err)
-					}
-					m.watchMutex.Unlock()
-				}
-			}
+	// 격리 디렉토리 안의 변경과 격리 이동으로 생긴 이벤트는 기록하지 않음 (격리가 반복되지 않도록)
+	if m.quarantine.suppressed(event, now) {
+		log.Printf("격리로 인한 이벤트 무시됨: %s, 작업: %s", event.Name, event.Op.String())
+		m.writes.take(event.Name)
+		return
+	}
 
-		case err, ok := <-m.watcher.Errors:
-			if !ok {
-				return
-			}
-			log.Printf("감시자 오류: %v", err)
+	// 하위 디렉토리까지 감시하는 백엔드는 제외 디렉토리 아래의 이벤트도 보고하므로 여기서 버림
+	recursive := m.watcher.recursive(event.Name)
+	if recursive && m.inExcludedDir(filters, event.Name) {
+		return
+	}
+
+	// 대량 변경 감지는 파일 필터와 관계없이 모든 변경을 셈
+	m.observeBurst(event, now)
+
+	// 새 디렉터리가 생성된 경우 확장자 필터와 무관하게 감시 대상에 추가
+	if !recursive && event.Op.Has(WatchCreate) && isDirectory(event.Name) && !filters.isExcludedDir(event.Name) {
+		log.Printf("새 디렉터리 감지됨, 감시 대상에 추가: %s", event.Name)
+		if err := m.watchRecursive(m.runCtx, event.Name, nil); err != nil {
+			log.Printf("새 디렉터리 감시 설정 실패: %v", err)
+		}
+	}
+
+	// 실행은 다른 작업과 함께 보고되어도 별도 이벤트로 기록
+	if event.Op.Has(WatchExec) {
+		m.handleExec(filters, event, now)
+		if event.Op == WatchExec {
+			return
+		}
+	}
+
+	// 삭제되거나 이름이 바뀌는 파일의 진행 중인 쓰기 묶음은 먼저 기록
+	if event.Op.Has(WatchRemove) || event.Op.Has(WatchRename) {
+		if burst, ok := m.writes.take(event.Name); ok {
+			m.recordBurst(burst)
+		}
+	}
+
+	// Rename은 이전 이름에 대해 발생하므로, 새 이름의 Create가 올 때까지 보류
+	if event.Op.Has(WatchRename) {
+		log.Printf("파일 이름 변경 감지됨: %s", event.Name)
+		m.renames.add(event.Name, now, event.Process)
+		return
+	}
+
+	// 보류 중인 Rename과 짝이 맞는 Create는 MOVE로 처리
+	// (RENAME을 기록하지 않으면 짝짓지 않고 새 경로를 CREATE로 기록)
+	if event.Op.Has(WatchCreate) && filters.operations[OperationRename] {
+		if oldPath, ok := m.renames.match(event.Name, now); ok {
+			m.handleMove(oldPath, event.Name, now, event.Process)
+			return
 		}
 	}
+
+	// 내용을 확인하기 위해 보류 중인 새 파일의 쓰기는 WRITE 기록 여부와 관계없이 생성 묶음에 더함
+	if event.Op.Has(WatchWrite) && !event.Op.Has(WatchCreate) && m.writes.holding(event.Name) {
+		m.writes.add(event.Name, now, event.Process)
+		return
+	}
+
+	var operation string
+
+	// 감시 작업 처리
+	switch {
+	case event.Op.Has(WatchCreate):
+		operation = OperationCreate
+	case event.Op.Has(WatchRemove):
+		operation = OperationRemove
+	case event.Op.Has(WatchWrite):
+		operation = OperationWrite
+	case event.Op.Has(WatchChmod):
+		operation = OperationChmod
+	default:
+		log.Printf("알 수 없는 작업 감지됨: %s (%s)", event.Name, event.Op.String())
+		return
+	}
+
+	// 기록하도록 설정된 작업만 처리
+	if !filters.operations[operation] {
+		return
+	}
+
+	// 파일 확장자 확인
+	ext := strings.ToLower(filepath.Ext(event.Name))
+
+	// 확장자로는 필터와 일치하지 않고 유형 필터가 있는 새 파일은 막 생성되어 아직 비어 있을 수 있으므로,
+	// 쓰기가 끝나 파일이 안정된 뒤에 내용을 읽어 유형을 판별하고 필터를 확인
+	if operation == OperationCreate && m.needsContent(filters, event.Name) {
+		m.writes.hold(event.Name, "", now, event.Process)
+		return
+	}
+
+	// 필터 로깅 (디버깅)
+	log.Printf("파일: %s, 확장자: %s, 필터: %v %v", event.Name, ext, filters.fileFilters, filters.typeFilters)
+
+	// 이벤트 필터링 (확장자, 경로 규칙 기준). 기록된 이벤트의 유형은 파일이 안정된 뒤에 작업자가 판별
+	matched := filters.matches(event.Name, "")
+	if !matched && operation == OperationWrite && len(filters.typeFilters) > 0 {
+		// 쓰기는 MODIFIED로 묶인 뒤 내용을 확인하므로 여기서는 경로 규칙만 검사
+		matched = filters.pathRule.Match(event.Name)
+	}
+	if !matched {
+		log.Printf("필터와 일치하지 않아 무시됨: %s (확장자: %s)", event.Name, ext)
+		return
+	}
+
+	// 쓰기는 파일이 안정될 때까지 모아서 하나의 MODIFIED 이벤트로 기록
+	if operation == OperationWrite {
+		m.writes.add(event.Name, now, event.Process)
+		return
+	}
+
+	log.Printf("[중요] 파일 %s: %s (타입: %s)\n", operation, event.Name, ext)
+
+	m.recordEvent(FileEvent{
+		Path:      event.Name,
+		Operation: operation,
+		Timestamp: now,
+		FileType:  ext,
+		Process:   event.Process,
+	})
 }
 
-// Stop은 모니터링을 중지합니다.
-func (m *Monitor) Stop() {
-	if !m.running {
-		log.Println("모니터링이 실행 중이지 않습니다")
+// handleExec는 파일 실행을 EXEC 이벤트로 기록합니다.
+func (m *Monitor) handleExec(filters *filterSet, event WatchEvent, now time.Time) {
+	if !filters.operations[OperationExec] {
 		return
 	}
+	// 확장자로 판단할 수 없을 때만 내용을 읽음. 실행되는 파일은 쓰기가 끝난 상태이므로 기다리지 않음
+	detected := ""
+	if !filters.matches(event.Name, "") {
+		if !m.needsContent(filters, event.Name) {
+			return
+		}
+		if detected = m.detectType(filters, event.Name); !filters.matches(event.Name, detected) {
+			return
+		}
+	}
 
-	m.running = false
+	exe := ""
+	if event.Process != nil {
+		exe = event.Process.Exe
+	}
+	log.Printf("[중요] 파일 실행 감지됨: %s (프로세스: %s)", event.Name, exe)
+	m.recordEvent(FileEvent{
+		Path:         event.Name,
+		Operation:    OperationExec,
+		Timestamp:    now,
+		FileType:     strings.ToLower(filepath.Ext(event.Name)),
+		DetectedType: detected,
+		Process:      event.Process,
+	})
+}
 
-	// 저장 타이머 종료
-	if m.saveTimer != nil {
-		m.saveTimer.Stop()
+// inExcludedDir은 경로가 속한 장치 루트 아래에서 감시 제외 디렉토리 안에 있는지 확인합니다.
+func (m *Monitor) inExcludedDir(filters *filterSet, path string) bool {
+	root := m.deviceOf(path)
+	for dir := filepath.Dir(path); dir != root && isUnderPath(dir, root); dir = filepath.Dir(dir) {
+		if filters.isExcludedDir(dir) {
+			return true
+		}
 	}
+	return false
+}
 
-	// 마지막으로 데이터베이스에 저장
-	m.saveEventsToDatabase()
+// handleMove는 짝지어진 Rename/Create 이벤트를 하나의 MOVE 이벤트로 기록합니다.
+// 이전 경로나 새 경로 중 하나라도 필터와 일치하면 기록되므로,
+// payload.txt → payload.exe 같은 이름 변경도 실행 파일의 등장으로 감지됩니다.
+// 경로로는 일치하지 않고 유형 필터로 확인해야 하면 새 파일처럼 쓰기가 끝날 때까지 보류합니다.
+func (m *Monitor) handleMove(oldPath, newPath string, now time.Time, process *ProcessInfo) {
+	filters := m.filters()
+	oldFilters, newFilters := filters.forPath(oldPath), filters.forPath(newPath)
+	if !oldFilters.matches(oldPath, "") && !newFilters.matches(newPath, "") {
+		if m.needsContent(newFilters, newPath) {
+			m.writes.hold(newPath, oldPath, now, process)
+			return
+		}
+		log.Printf("필터와 일치하지 않아 무시됨: %s -> %s", oldPath, newPath)
+		return
+	}
 
-	// 리소스 정리
-	if m.watcher != nil {
-		m.watcher.Close()
+	log.Printf("[중요] 파일 이동 감지됨: %s -> %s", oldPath, newPath)
+	m.recordEvent(FileEvent{
+		Path:      newPath,
+		Operation: OperationMove,
+		Timestamp: now,
+		FileType:  strings.ToLower(filepath.Ext(newPath)),
+		OldPath:   oldPath,
+		NewPath:   newPath,
+		Process:   process,
+	})
+}
+
+// handleOrphanRename은 짝이 되는 Create 없이 만료된 Rename 이벤트를 기록합니다.
+// 감시 범위 밖으로 이동된 파일이 이에 해당합니다.
+func (m *Monitor) handleOrphanRename(p pendingRename) {
+	filters := m.filters().forPath(p.path)
+	if !filters.operations[OperationRename] || !filters.matches(p.path, "") {
+		return
 	}
 
-	// 데이터베이스 연결 종료
-	if m.db != nil {
-		m.db.Close()
+	log.Printf("[중요] 감시 범위 밖으로 이름 변경됨: %s", p.path)
+	m.recordEvent(FileEvent{
+		Path:      p.path,
+		Operation: OperationRename,
+		Timestamp: p.at,
+		FileType:  strings.ToLower(filepath.Ext(p.path)),
+		OldPath:   p.path,
+		Process:   p.process,
+	})
+}
+
+// needsContent는 생성된 파일이 필터와 일치하는지 판단하려면 내용을 읽어야 하는지 확인합니다.
+func (m *Monitor) needsContent(filters *filterSet, path string) bool {
+	return len(filters.typeFilters) > 0 && !filters.matchesType(path, "") &&
+		filters.pathRule.Match(path) && !isDirectory(path)
+}
+
+// recordBurst는 완료된 묶음을 기록합니다. 보류 중이던 CREATE는 먼저 CREATE로 기록하고,
+// WRITE를 기록하도록 설정되어 있고 생성 이후 쓰기가 있었으면 이어서 MODIFIED로 기록합니다.
+func (m *Monitor) recordBurst(burst *writeBurst) {
+	if !burst.created.IsZero() {
+		m.recordCreated(burst)
+		if burst.count == 0 || !m.filters().forPath(burst.path).operations[OperationWrite] {
+			return
+		}
+	}
+	m.recordModified(burst)
+}
+
+// recordCreated는 보류 중이던 CREATE(이동으로 생긴 파일이면 MOVE)를 파일이 안정된 뒤의 내용으로 유형을 판별하여 기록합니다.
+func (m *Monitor) recordCreated(burst *writeBurst) {
+	filters := m.filters().forPath(burst.path)
+	operation, selectOp := OperationCreate, OperationCreate
+	if burst.oldPath != "" {
+		operation, selectOp = OperationMove, OperationRename
+	}
+	if !filters.operations[selectOp] {
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(burst.path))
+	detected := ""
+	if !filters.matches(burst.path, "") {
+		detected = m.detectType(filters, burst.path)
+		if !filters.matches(burst.path, detected) {
+			log.Printf("필터와 일치하지 않아 무시됨: %s (확장자: %s, 감지 유형: %s)", burst.path, ext, detected)
+			return
+		}
+	}
+
+	log.Printf("[중요] 파일 %s: %s (타입: %s %s)\n", operation, burst.path, ext, detected)
+	event := FileEvent{
+		Path:         burst.path,
+		Operation:    operation,
+		Timestamp:    burst.created,
+		FileType:     ext,
+		DetectedType: detected,
+		Process:      burst.process,
+	}
+	if burst.oldPath != "" {
+		event.OldPath, event.NewPath = burst.oldPath, burst.path
 	}
+	m.recordEvent(event)
+}
 
-	// 이벤트 채널 닫기
-	close(m.eventChan)
+// recordModified는 완료된 쓰기 묶음을 MODIFIED 이벤트로 기록합니다.
+// 확장자로 필터와 일치하지 않으면 파일이 안정된 뒤이므로 이 시점에 내용을 읽어 유형을 판별하고 필터를 다시 확인합니다.
+func (m *Monitor) recordModified(burst *writeBurst) {
+	filters := m.filters().forPath(burst.path)
+	detected := ""
+	if !filters.matches(burst.path, "") {
+		detected = m.detectType(filters, burst.path)
+		if !filters.matches(burst.path, detected) {
+			log.Printf("필터와 일치하지 않아 무시됨: %s (감지 유형: %s)", burst.path, detected)
+			return
+		}
+	}
 
-	log.Println("파일 모니터링 중지됨")
+	log.Printf("[중요] 파일 수정 감지됨: %s (쓰기 %d회)", burst.path, burst.count)
+	m.recordEvent(FileEvent{
+		Path:         burst.path,
+		Operation:    OperationModified,
+		Timestamp:    burst.last,
+		FileType:     strings.ToLower(filepath.Ext(burst.path)),
+		DetectedType: detected,
+		FirstWrite:   burst.first,
+		LastWrite:    burst.last,
+		WriteCount:   burst.count,
+		Process:      burst.process,
+	})
+}
+
+// detectType은 필요한 경우에만 파일 내용을 읽어 콘텐츠 유형을 판별합니다.
+// 경로 규칙에서 제외되었거나, 유형 필터가 없고 확장자도 일치하지 않는 파일은 읽지 않습니다.
+func (m *Monitor) detectType(filters *filterSet, path string) string {
+	if !filters.pathRule.Match(path) {
+		return ""
+	}
+	if len(filters.typeFilters) == 0 && !filters.matchesType(path, "") {
+		return ""
+	}
+	if isDirectory(path) {
+		return ""
+	}
+
+	detected, err := SniffFile(path)
+	if err != nil {
+		log.Printf("파일 유형 판별 실패: %s - %v", path, err)
+		return ""
+	}
+	return detected
+}
+
+// recordEvent는 파일 이벤트를 분석하여 메모리에 기록하고 구독자에게 전송합니다.
+// 해시와 시그니처 검사 대상 이벤트는 기록 후 작업자에게 넘겨지며, 결과는 이벤트 ID로 연결되어 따로 저장됩니다.
+func (m *Monitor) recordEvent(fileEvent FileEvent) {
+	m.enrichEvent(&fileEvent)
+
+	// 이벤트 기록
+	m.eventsMutex.Lock()
+	m.lastEventID++
+	fileEvent.ID = m.lastEventID
+	m.fileEvents = append(m.fileEvents, fileEvent)
+	m.eventsMutex.Unlock()
+
+	// 조정 이벤트는 인벤토리 비교 시 이미 반영됨
+	if !fileEvent.Reconciled {
+		m.updateInventory(fileEvent)
+	}
+
+	if m.hasher.enabled() && shouldHash(fileEvent) {
+		job := hashJob{eventID: fileEvent.ID, path: fileEvent.Path}
+		m.recentHashes.expect(job.eventID)
+		if !m.hasher.submit(job) {
+			m.recentHashes.finish(job.eventID)
+			// 대기열이 가득 찬 경우 이벤트 수집을 멈추지 않도록 해시 계산을 건너뜀
+			log.Printf("해시 대기열이 가득 참, 해시 계산 건너뜀: %s", fileEvent.Path)
+		}
+	}
+	if m.scanner.enabled() && m.scanner.wants(fileEvent) {
+		job := scanJob{eventID: fileEvent.ID, path: fileEvent.Path}
+		if !m.scanner.submit(job) {
+			log.Printf("시그니처 검사 대기열이 가득 참, 검사 건너뜀: %s", fileEvent.Path)
+		}
+	}
+	if job, ok := m.analyzer.job(fileEvent); ok {
+		if job.detect {
+			m.recentTypes.expect(job.eventID)
+		}
+		if !m.analyzer.submit(job) {
+			m.recentTypes.finish(job.eventID)
+			log.Printf("파일 분석 대기열이 가득 참, 분석 건너뜀: %s", fileEvent.Path)
+		}
+	}
+
+	// 구독자에게 전송
+	m.publish(fileEvent)
+}
+
+// Stop은 모니터링을 중지하고 모든 고루틴이 종료될 때까지 기다립니다.
+// Start와 Run 어느 쪽으로 시작한 경우에도 사용할 수 있습니다.
+func (m *Monitor) Stop() {
+	m.runMutex.Lock()
+	if !m.running {
+		m.runMutex.Unlock()
+		log.Println("모니터링이 실행 중이지 않습니다")
+		return
+	}
+	cancel, done := m.cancel, m.runDone
+	m.runMutex.Unlock()
+
+	cancel()
+	<-done
 }
 
 // GetFileEvents는 현재까지 수집된 파일 이벤트를 반환합니다.
@@ -369,9 +1105,7 @@ func (m *Monitor) PrintStats() {
 	} else {
 		fmt.Println("\n===== 메모리 내 파일 이벤트 =====")
 		for _, event := range m.fileEvents {
-			fmt.Printf("[%s] %s\n", event.Timestamp.Format("2006-01-02 15:04:05"), event.Path)
-			fmt.Printf("  작업: %s, 파일 유형: %s\n", event.Operation, event.FileType)
-			fmt.Println("----------------------------")
+			printFileEvent(event)
 		}
 	}
 
@@ -396,26 +1130,57 @@ func (m *Monitor) PrintStats() {
 			}
 
 			for i := 0; i < displayCount; i++ {
-				event := events[i]
-				fmt.Printf("[%s] %s\n", event.Timestamp.Format("2006-01-02 15:04:05"), event.Path)
-				fmt.Printf("  작업: %s, 파일 유형: %s\n", event.Operation, event.FileType)
-				fmt.Println("----------------------------")
+				printFileEvent(events[i])
 			}
 		}
 	}
 }
 
-// GetDevices는 현재 모니터링 중인 장치 목록을 반환합니다.
-func (m *Monitor) GetDevices() []string {
-	return m.devices
+// printFileEvent는 단일 파일 이벤트를 출력합니다.
+func printFileEvent(event FileEvent) {
+	fmt.Printf("[%s] %s\n", event.Timestamp.Format("2006-01-02 15:04:05"), event.Path)
+	fmt.Printf("  작업: %s, 파일 유형: %s\n", event.Operation, event.FileType)
+	if event.Reconciled {
+		fmt.Printf("  중지된 동안의 변경 (시작 시 조정)\n")
+	}
+	if event.DetectedType != "" {
+		fmt.Printf("  감지 유형: %s\n", event.DetectedType)
+	}
+	if event.OldPath != "" {
+		fmt.Printf("  이전 경로: %s\n", event.OldPath)
+	}
+	if st := event.Stat; st != nil {
+		fmt.Printf("  크기: %d바이트, 권한: %s, 소유자: %s, inode: %d, 링크: %d\n",
+			st.Size, st.Mode, formatOwner(st), st.Inode, st.Links)
+	}
+	if p := event.Process; p != nil {
+		fmt.Printf("  프로세스: PID %d, %s (UID %d)\n", p.PID, p.Exe, p.UID)
+	}
+	if event.WriteCount > 0 {
+		fmt.Printf("  쓰기: %d회 (%s ~ %s)\n", event.WriteCount,
+			event.FirstWrite.Format("15:04:05"), event.LastWrite.Format("15:04:05"))
+	}
+
+	fmt.Println("----------------------------")
+}
+
+// GetTypeFilters는 현재 설정된 콘텐츠 유형 필터 목록을 반환합니다.
+func (m *Monitor) GetTypeFilters() []string {
+	return m.filters().config().TypeFilters
+}
+
+// GetPathRule은 현재 설정된 경로 포함/제외 규칙을 반환합니다.
+func (m *Monitor) GetPathRule() *PathRule {
+	return m.filters().config().PathRule
 }
 
 // GetFileFilters는 현재 설정된 파일 확장자 필터 목록을 반환합니다.
 func (m *Monitor) GetFileFilters() []string {
-	return m.fileFilters
+	return m.filters().config().FileFilters
 }
 
 // EventChan은 모니터가 감지한 파일 이벤트를 구독할 수 있는 채널을 반환합니다.
+// 모든 호출자가 같은 채널을 공유하므로, 여러 소비자가 각각 모든 이벤트를 받으려면 Subscribe를 사용하세요.
 func (m *Monitor) EventChan() <-chan FileEvent {
-	return m.eventChan
+	return m.eventSub.Events()
 }
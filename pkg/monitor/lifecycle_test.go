@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cancelingBackend는 첫 Add에서 컨텍스트를 취소하고, 닫힌 뒤의 Add 호출을 기록하는 백엔드입니다.
+type cancelingBackend struct {
+	*FakeBackend
+	cancel context.CancelFunc
+
+	mu             sync.Mutex
+	adds           int
+	addsAfterClose int
+	closed         bool
+}
+
+func (b *cancelingBackend) Add(path string) error {
+	b.mu.Lock()
+	b.adds++
+	if b.closed {
+		b.addsAfterClose++
+	}
+	b.mu.Unlock()
+	b.cancel()
+	return b.FakeBackend.Add(path)
+}
+
+func (b *cancelingBackend) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.FakeBackend.Close()
+}
+
+func newRunMonitor(t *testing.T, backend WatcherBackend) (*Monitor, string) {
+	t.Helper()
+	dir := t.TempDir()
+	mon := NewMonitor(time.Hour)
+	mon.SetBackend(backend)
+	mon.SetDatabasePath(filepath.Join(dir, "test.db"))
+	mon.AddDevice(dir)
+	return mon, dir
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	fake := NewFakeBackend()
+	mon, dir := newRunMonitor(t, fake)
+	sub, err := mon.Subscribe(SubscribeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- mon.Run(ctx) }()
+
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "a.exe"), Op: WatchCreate})
+	select {
+	case <-sub.Events():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected event before cancel")
+	}
+
+	cancel()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Expected nil error on cancel, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+
+	if mon.running {
+		t.Error("Expected monitor to be stopped")
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("Expected subscription to be closed")
+	}
+	mon.Stop() // 이미 중지된 경우에도 안전해야 함
+
+	db, err := NewDatabase(mon.dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if saved, _ := db.GetFileEvents(); len(saved) != 1 {
+		t.Errorf("Expected event to be saved on shutdown, got %v", saved)
+	}
+}
+
+func TestRunCancelsWalkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	backend := &cancelingBackend{FakeBackend: NewFakeBackend(), cancel: cancel}
+	mon, dir := newRunMonitor(t, backend)
+	for i := 0; i < 50; i++ {
+		os.MkdirAll(filepath.Join(dir, "d", string(rune('a'+i%26)), string(rune('a'+i/26))), 0755)
+	}
+
+	if err := mon.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if backend.adds != 1 {
+		t.Errorf("Expected walk to stop after cancel, got %d Add calls", backend.adds)
+	}
+	if backend.addsAfterClose != 0 {
+		t.Errorf("Expected no Add calls after Close, got %d", backend.addsAfterClose)
+	}
+}
+
+func TestRunReturnsFatalError(t *testing.T) {
+	fake := NewFakeBackend()
+	mon, _ := newRunMonitor(t, fake)
+
+	result := make(chan error, 1)
+	go func() { result <- mon.Run(context.Background()) }()
+
+	// 백엔드가 스스로 종료되면 더 이상 이벤트를 받을 수 없음
+	fake.Close()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("Expected fatal error when backend closes unexpectedly")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after backend closed")
+	}
+
+	empty := NewMonitor(time.Hour)
+	if err := empty.Run(context.Background()); err == nil {
+		t.Error("Expected error when no devices are configured")
+	}
+}
@@ -0,0 +1,231 @@
+package monitor
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration은 데이터베이스 스키마를 한 단계 올리는 마이그레이션입니다.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations는 바이너리에 포함된 모든 마이그레이션을 버전 순서대로 반환합니다.
+// 파일 이름은 "0001_설명.sql" 형식이어야 합니다.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		name := entry.Name()
+		base := strings.TrimSuffix(name, ".sql")
+		versionStr, desc, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("잘못된 마이그레이션 파일 이름: %s", name)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("잘못된 마이그레이션 버전: %s", name)
+		}
+
+		data, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: desc, SQL: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("마이그레이션 버전이 연속적이지 않습니다: %04d_%s", m.Version, m.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// PendingMigrations는 dbPath의 데이터베이스를 변경하지 않고
+// 현재 스키마 버전과 적용 대기 중인 마이그레이션 목록을 반환합니다.
+func PendingMigrations(dbPath string) (int, []Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 파일이 없으면 모든 마이그레이션이 대기 상태
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return 0, migrations, nil
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer db.Close()
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		return 0, nil, err
+	}
+	if current > len(migrations) {
+		return current, nil, newerSchemaError(current, len(migrations))
+	}
+	return current, migrations[current:], nil
+}
+
+// migrate는 적용되지 않은 마이그레이션을 순서대로 각각의 트랜잭션에서 실행합니다.
+func migrate(db *sql.DB) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		);
+	`); err != nil {
+		return fmt.Errorf("schema_version 테이블 생성 실패: %v", err)
+	}
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return newerSchemaError(current, len(migrations))
+	}
+
+	// 버전 기록이 없는 기존 데이터베이스는 감지된 버전을 기준점으로 기록
+	if err := recordLegacyVersion(db, current, migrations); err != nil {
+		return err
+	}
+
+	for _, m := range migrations[current:] {
+		log.Printf("마이그레이션 적용: %04d_%s", m.Version, m.Name)
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.SQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("마이그레이션 %04d_%s 실패: %v", m.Version, m.Name, err)
+		}
+		if err := insertSchemaVersion(tx, m); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaVersion은 데이터베이스의 현재 스키마 버전을 반환합니다.
+// schema_version 테이블이 없으면 기존 테이블 구조로부터 버전을 추정합니다.
+func schemaVersion(db *sql.DB) (int, error) {
+	exists, err := tableExists(db, "schema_version")
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		var version sql.NullInt64
+		if err := db.QueryRow(`SELECT MAX(version) FROM schema_version;`).Scan(&version); err != nil {
+			return 0, err
+		}
+		if version.Valid {
+			return int(version.Int64), nil
+		}
+	}
+	return detectLegacyVersion(db)
+}
+
+// detectLegacyVersion은 마이그레이션 도입 이전에 생성된 데이터베이스의 버전을 추정합니다.
+func detectLegacyVersion(db *sql.DB) (int, error) {
+	var tableSQL string
+	err := db.QueryRow(`
+		SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'file_events';
+	`).Scan(&tableSQL)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// 경로별 덮어쓰기 스키마는 버전 1, 이력 보존 스키마는 버전 2
+	if strings.Contains(strings.ToUpper(tableSQL), "UNIQUE") {
+		return 1, nil
+	}
+	return 2, nil
+}
+
+// recordLegacyVersion은 버전 기록 없이 추정된 버전까지의 마이그레이션을 적용된 것으로 기록합니다.
+func recordLegacyVersion(db *sql.DB, current int, migrations []Migration) error {
+	if current == 0 {
+		return nil
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_version;`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	log.Printf("버전 기록이 없는 기존 데이터베이스 감지됨 (추정 버전: %d)", current)
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations[:current] {
+		if err := insertSchemaVersion(tx, m); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertSchemaVersion(tx *sql.Tx, m Migration) error {
+	_, err := tx.Exec(
+		`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?);`,
+		m.Version, m.Name, time.Now().Format(timeLayout),
+	)
+	return err
+}
+
+func tableExists(db *sql.DB, name string) (bool, error) {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?;`, name,
+	).Scan(&count)
+	return count > 0, err
+}
+
+func newerSchemaError(current, latest int) error {
+	return fmt.Errorf("데이터베이스 스키마 버전(%d)이 지원하는 최신 버전(%d)보다 높습니다. 최신 버전의 프로그램을 사용하세요", current, latest)
+}
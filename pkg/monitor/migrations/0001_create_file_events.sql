@@ -0,0 +1,8 @@
+-- 최초 스키마: 경로별로 마지막 이벤트만 보관하는 file_events 테이블
+CREATE TABLE IF NOT EXISTS file_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    timestamp DATETIME NOT NULL,
+    path TEXT NOT NULL UNIQUE,
+    operation TEXT NOT NULL,
+    file_type TEXT NOT NULL
+);
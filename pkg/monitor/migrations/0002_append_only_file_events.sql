@@ -0,0 +1,23 @@
+-- file_events를 추가 전용 이력 테이블로 변환하고 경로별 현재 상태 뷰를 추가
+ALTER TABLE file_events RENAME TO file_events_legacy;
+
+CREATE TABLE file_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    timestamp DATETIME NOT NULL,
+    path TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    file_type TEXT NOT NULL
+);
+
+INSERT INTO file_events (timestamp, path, operation, file_type)
+SELECT timestamp, path, operation, file_type FROM file_events_legacy ORDER BY id;
+
+DROP TABLE file_events_legacy;
+
+CREATE INDEX idx_file_events_path ON file_events(path);
+CREATE INDEX idx_file_events_timestamp ON file_events(timestamp);
+
+CREATE VIEW file_states AS
+    SELECT e.timestamp, e.path, e.operation, e.file_type
+    FROM file_events e
+    WHERE e.id = (SELECT MAX(id) FROM file_events WHERE path = e.path);
@@ -0,0 +1,11 @@
+-- 이름 변경/이동 이벤트(MOVE)의 이전 경로와 새 경로 저장
+ALTER TABLE file_events ADD COLUMN old_path TEXT NOT NULL DEFAULT '';
+ALTER TABLE file_events ADD COLUMN new_path TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_file_events_old_path ON file_events(old_path);
+
+DROP VIEW file_states;
+CREATE VIEW file_states AS
+    SELECT e.timestamp, e.path, e.operation, e.file_type, e.old_path, e.new_path
+    FROM file_events e
+    WHERE e.id = (SELECT MAX(id) FROM file_events WHERE path = e.path);
@@ -0,0 +1,11 @@
+-- 연속된 쓰기를 묶은 MODIFIED 이벤트의 첫/마지막 쓰기 시간과 쓰기 횟수 저장
+ALTER TABLE file_events ADD COLUMN first_write TEXT NOT NULL DEFAULT '';
+ALTER TABLE file_events ADD COLUMN last_write TEXT NOT NULL DEFAULT '';
+ALTER TABLE file_events ADD COLUMN write_count INTEGER NOT NULL DEFAULT 0;
+
+-- 이후 컬럼이 추가되어도 뷰를 다시 만들지 않도록 모든 컬럼을 노출
+DROP VIEW file_states;
+CREATE VIEW file_states AS
+    SELECT e.*
+    FROM file_events e
+    WHERE e.id = (SELECT MAX(id) FROM file_events WHERE path = e.path);
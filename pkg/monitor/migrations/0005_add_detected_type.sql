@@ -0,0 +1,4 @@
+-- 매직 바이트로 판별한 파일 콘텐츠 유형 저장
+ALTER TABLE file_events ADD COLUMN detected_type TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_file_events_detected_type ON file_events(detected_type);
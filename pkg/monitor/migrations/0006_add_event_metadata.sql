@@ -0,0 +1,7 @@
+-- 이벤트별 부가 정보(PE 헤더 등)를 키/값으로 저장
+CREATE TABLE event_metadata (
+    event_id INTEGER NOT NULL REFERENCES file_events(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    PRIMARY KEY (event_id, key)
+);
@@ -0,0 +1,12 @@
+-- 이벤트 대상 파일의 해시 (비동기로 계산되어 이벤트 ID로 연결)
+CREATE TABLE file_hashes (
+    event_id INTEGER PRIMARY KEY REFERENCES file_events(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    md5 TEXT NOT NULL DEFAULT '',
+    sha1 TEXT NOT NULL DEFAULT '',
+    sha256 TEXT NOT NULL DEFAULT '',
+    hashed_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_file_hashes_sha256 ON file_hashes(sha256);
@@ -0,0 +1,2 @@
+-- 모니터가 중지된 동안의 변경을 시작 시 인벤토리와 비교하여 찾아낸 이벤트 표시
+ALTER TABLE file_events ADD COLUMN reconciled INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+-- 중지 시점의 필터 대상 파일 목록 (다음 시작 시 오프라인 변경을 찾는 기준)
+CREATE TABLE inventory (
+    path TEXT PRIMARY KEY,
+    device TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    mod_time INTEGER NOT NULL, -- 유닉스 시간 (나노초)
+    sha256 TEXT NOT NULL DEFAULT ''
+);
+
+-- 인벤토리를 만든 장치 (목록에 없는 장치는 처음 감시하는 것으로 보고 이벤트를 만들지 않음)
+CREATE TABLE inventory_devices (
+    device TEXT PRIMARY KEY,
+    saved_at TEXT NOT NULL
+);
@@ -0,0 +1,11 @@
+-- CREATE/MOVE/MODIFIED 이벤트 시점에 조회한 파일 상태 (조회하지 않은 이벤트는 NULL)
+ALTER TABLE file_events ADD COLUMN size INTEGER;
+ALTER TABLE file_events ADD COLUMN mode INTEGER;
+ALTER TABLE file_events ADD COLUMN uid INTEGER;
+ALTER TABLE file_events ADD COLUMN gid INTEGER;
+ALTER TABLE file_events ADD COLUMN owner TEXT;
+ALTER TABLE file_events ADD COLUMN group_name TEXT;
+ALTER TABLE file_events ADD COLUMN mtime TEXT;
+ALTER TABLE file_events ADD COLUMN ctime TEXT;
+ALTER TABLE file_events ADD COLUMN inode INTEGER;
+ALTER TABLE file_events ADD COLUMN nlink INTEGER;
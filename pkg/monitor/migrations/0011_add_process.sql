@@ -0,0 +1,6 @@
+-- 이벤트를 일으킨 프로세스 (fanotify 백엔드에서만 기록, 그 외에는 NULL)
+ALTER TABLE file_events ADD COLUMN pid INTEGER;
+ALTER TABLE file_events ADD COLUMN exe TEXT;
+ALTER TABLE file_events ADD COLUMN process_uid INTEGER;
+
+CREATE INDEX idx_file_events_exe ON file_events(exe);
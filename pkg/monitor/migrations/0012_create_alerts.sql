@@ -0,0 +1,16 @@
+-- 대량 파일 변경 경고
+CREATE TABLE alerts (
+    id INTEGER PRIMARY KEY,
+    timestamp TEXT NOT NULL,
+    scope TEXT NOT NULL,            -- global, directory, extension
+    key TEXT NOT NULL DEFAULT '',   -- 디렉토리 경로 또는 확장자
+    count INTEGER NOT NULL,
+    threshold INTEGER NOT NULL,
+    window_ms INTEGER NOT NULL,
+    first_seen TEXT NOT NULL DEFAULT '',
+    operations TEXT NOT NULL DEFAULT '{}', -- 작업별 변경 수 (JSON 객체)
+    directories INTEGER NOT NULL DEFAULT 0,
+    samples TEXT NOT NULL DEFAULT '[]'     -- 최근 변경된 경로 (JSON 배열)
+);
+
+CREATE INDEX idx_alerts_timestamp ON alerts(timestamp);
@@ -0,0 +1,12 @@
+-- 이벤트 대상 파일에서 일치한 바이트 시그니처 (비동기로 검사되어 이벤트 ID로 연결)
+CREATE TABLE signature_matches (
+    event_id INTEGER NOT NULL REFERENCES file_events(id) ON DELETE CASCADE,
+    signature TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL,
+    strings TEXT NOT NULL DEFAULT '[]', -- 발견된 문자열 ID, 첫 위치, 횟수 (JSON 배열)
+    scanned_at TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (event_id, signature)
+);
+
+CREATE INDEX idx_signature_matches_signature ON signature_matches(signature);
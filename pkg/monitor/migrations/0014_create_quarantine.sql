@@ -0,0 +1,20 @@
+-- 격리된 파일 (원래 위치와 상태를 기록하여 복원할 수 있게 함)
+CREATE TABLE quarantine (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id INTEGER NOT NULL DEFAULT 0,   -- 격리를 일으킨 이벤트 (직접 격리하면 0)
+    reason TEXT NOT NULL DEFAULT '',       -- 예: signature:eicar-test-file, rule:temp-exe-dropped
+    original_path TEXT NOT NULL,
+    quarantine_path TEXT NOT NULL DEFAULT '',
+    sha256 TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    mode INTEGER NOT NULL DEFAULT 0,       -- 격리 전 권한 비트 (os.FileMode)
+    uid INTEGER NOT NULL DEFAULT -1,
+    gid INTEGER NOT NULL DEFAULT -1,
+    owner TEXT NOT NULL DEFAULT '',
+    group_name TEXT NOT NULL DEFAULT '',
+    quarantined_at TEXT NOT NULL,
+    status TEXT NOT NULL,                  -- quarantined, restored, purged, dry-run
+    released_at TEXT NOT NULL DEFAULT ''   -- 복원 또는 삭제한 시간
+);
+
+CREATE INDEX idx_quarantine_original_path ON quarantine(original_path);
@@ -0,0 +1,11 @@
+-- 이동(MOVE)으로 옮겨진 이전 경로는 삭제된 것으로 보고 현재 상태에서 제외
+-- (이동 뒤에 같은 경로에 다시 생긴 파일은 이동보다 나중 이벤트이므로 그대로 나타남)
+DROP VIEW file_states;
+CREATE VIEW file_states AS
+    SELECT e.*
+    FROM file_events e
+    WHERE e.id = (SELECT MAX(id) FROM file_events WHERE path = e.path)
+      AND NOT EXISTS (
+          SELECT 1 FROM file_events m
+          WHERE m.operation = 'MOVE' AND m.old_path = e.path AND m.id > e.id
+      );
@@ -41,15 +41,41 @@ func TestAddDevice(t *testing.T) {
 func TestSetFileFilters(t *testing.T) {
 	mon := NewMonitor(5 * time.Second)
 	filters := []string{".exe", ".dll", ".sys"}
-	mon.SetFileFilters(filters)
+	if err := mon.SetFileFilters(filters); err != nil {
+		t.Fatalf("SetFileFilters failed: %v", err)
+	}
+	fileFilters := mon.GetFileFilters()
 
-	if len(mon.fileFilters) != len(filters) {
-		t.Fatalf("Expected %d filters, got %d", len(filters), len(mon.fileFilters))
+	if len(fileFilters) != len(filters) {
+		t.Fatalf("Expected %d filters, got %d", len(filters), len(fileFilters))
 	}
 
 	for i, filter := range filters {
-		if mon.fileFilters[i] != filter {
-			t.Errorf("Expected filter[%d] to be %s, got %s", i, filter, mon.fileFilters[i])
+		if fileFilters[i] != filter {
+			t.Errorf("Expected filter[%d] to be %s, got %s", i, filter, fileFilters[i])
 		}
 	}
 }
+
+func TestSetOperations(t *testing.T) {
+	mon := NewMonitor(5 * time.Second)
+
+	if got := mon.GetOperations(); len(got) != 3 {
+		t.Errorf("Expected 3 default operations, got %v", got)
+	}
+
+	if err := mon.SetOperations([]string{"write", " CHMOD "}); err != nil {
+		t.Fatalf("SetOperations failed: %v", err)
+	}
+	got := mon.GetOperations()
+	if len(got) != 2 || got[0] != "WRITE" || got[1] != "CHMOD" {
+		t.Errorf("Expected [WRITE CHMOD], got %v", got)
+	}
+
+	if err := mon.SetOperations([]string{"CREATE", "DELETE"}); err == nil {
+		t.Error("Expected error for unknown operation")
+	}
+	if got := mon.GetOperations(); len(got) != 2 {
+		t.Errorf("Expected operations to be unchanged after error, got %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// FileEvent.Operation에 기록되는 작업 이름입니다.
+const (
+	OperationCreate = "CREATE"
+	OperationRemove = "REMOVE"
+	OperationRename = "RENAME"
+	OperationWrite  = "WRITE"
+	OperationChmod  = "CHMOD"
+	OperationMove   = "MOVE"
+	// OperationExec는 파일의 실행입니다. 실행 감지를 지원하는 백엔드(fanotify)에서만 기록됩니다.
+	OperationExec = "EXEC"
+
+	// OperationModified는 연속된 쓰기(WRITE)를 묶은 이벤트입니다.
+	OperationModified = "MODIFIED"
+
+	// OperationAlert는 대량 변경 경고를 전달하는 이벤트입니다. 이벤트의 Alert 필드에 내용이 담깁니다.
+	OperationAlert = "ALERT"
+)
+
+// selectableOperations는 SetOperations로 선택할 수 있는 작업 목록입니다.
+// RENAME을 선택하면 짝지어진 이동(MOVE)과 감시 범위 밖으로의 이름 변경(RENAME)이 모두 기록되고,
+// WRITE를 선택하면 연속된 쓰기가 묶여 MODIFIED 이벤트로 기록됩니다.
+// EXEC는 실행 감지를 지원하는 백엔드(fanotify)에서만 발생합니다.
+var selectableOperations = []string{
+	OperationCreate,
+	OperationRemove,
+	OperationRename,
+	OperationWrite,
+	OperationChmod,
+	OperationExec,
+}
+
+// defaultOperations는 기본으로 기록되는 작업 목록입니다.
+var defaultOperations = []string{OperationCreate, OperationRemove, OperationRename}
+
+// parseOperations는 작업 이름 목록을 검증하여 집합으로 변환합니다.
+func parseOperations(ops []string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, op := range ops {
+		op = strings.ToUpper(strings.TrimSpace(op))
+		if op == "" {
+			continue
+		}
+		valid := false
+		for _, selectable := range selectableOperations {
+			if op == selectable {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("알 수 없는 작업: %s (가능한 값: %s)", op, strings.Join(selectableOperations, ", "))
+		}
+		set[op] = true
+	}
+	return set, nil
+}
+
+// SetOperations는 기록할 작업 종류(CREATE, REMOVE, RENAME, WRITE, CHMOD, EXEC)를 설정합니다.
+// 대소문자는 구분하지 않으며, 알 수 없는 작업이 포함되면 설정을 변경하지 않고 오류를 반환합니다.
+func (m *Monitor) SetOperations(ops []string) error {
+	err := m.updateFilters(func(cfg *FilterConfig) { cfg.Operations = ops })
+	if err != nil {
+		return err
+	}
+	log.Printf("기록 작업 설정됨: %v", m.GetOperations())
+	return nil
+}
+
+// GetOperations는 현재 기록하도록 설정된 작업 목록을 반환합니다.
+func (m *Monitor) GetOperations() []string {
+	return m.filters().config().Operations
+}
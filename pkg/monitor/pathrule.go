@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// regexPrefix가 붙은 패턴은 글롭 대신 정규식으로 해석됩니다.
+const regexPrefix = "re:"
+
+// pathPattern은 컴파일된 글롭 또는 정규식 경로 패턴입니다.
+type pathPattern struct {
+	include bool
+	source  string
+	glob    string
+	re      *regexp.Regexp
+}
+
+// compilePathPattern은 패턴 문자열을 검증하고 컴파일합니다.
+// "re:" 접두사가 있으면 정규식, 없으면 doublestar 글롭("**/Temp/**")으로 해석합니다.
+func compilePathPattern(pattern string) (pathPattern, error) {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return pathPattern{}, fmt.Errorf("잘못된 정규식 패턴 %q: %v", pattern, err)
+		}
+		return pathPattern{source: pattern, re: re}, nil
+	}
+
+	glob := filepath.ToSlash(pattern)
+	if !doublestar.ValidatePattern(glob) {
+		return pathPattern{}, fmt.Errorf("잘못된 글롭 패턴 %q", pattern)
+	}
+	return pathPattern{source: pattern, glob: glob}, nil
+}
+
+// match는 "/"로 정규화된 경로가 패턴과 일치하는지 확인합니다.
+func (p pathPattern) match(slashPath string) bool {
+	if p.re != nil {
+		return p.re.MatchString(slashPath)
+	}
+	return doublestar.MatchUnvalidated(p.glob, slashPath)
+}
+
+// PathRule은 순서대로 평가되는 경로 포함/제외 규칙 목록입니다.
+//
+// 패턴은 기본적으로 doublestar 글롭이며, "re:" 접두사를 붙이면 정규식으로 해석됩니다.
+// 경로 구분자는 운영체제와 무관하게 "/"로 정규화되어 비교되므로
+// Windows 경로에도 "**/Temp/**" 같은 패턴을 사용할 수 있습니다.
+//
+// 추가된 순서대로 검사하여 처음 일치하는 항목이 결과를 결정합니다.
+// 일치하는 항목이 없으면, 포함 규칙이 하나라도 있는 경우 제외되고 없는 경우 포함됩니다.
+// 예를 들어 Exclude("**/Temp/*.log") 다음 Include("**/Temp/**")는
+// Temp 아래의 .log 파일을 제외한 모든 파일을 포함합니다.
+type PathRule struct {
+	patterns   []pathPattern
+	hasInclude bool
+}
+
+// Include는 포함 패턴을 규칙 목록 끝에 추가합니다.
+func (r *PathRule) Include(pattern string) error {
+	return r.add(pattern, true)
+}
+
+// Exclude는 제외 패턴을 규칙 목록 끝에 추가합니다.
+func (r *PathRule) Exclude(pattern string) error {
+	return r.add(pattern, false)
+}
+
+func (r *PathRule) add(pattern string, include bool) error {
+	p, err := compilePathPattern(pattern)
+	if err != nil {
+		return err
+	}
+	p.include = include
+	r.patterns = append(r.patterns, p)
+	if include {
+		r.hasInclude = true
+	}
+	return nil
+}
+
+// Match는 경로가 규칙에 의해 포함되는지 확인합니다. 빈 규칙은 모든 경로를 포함합니다.
+func (r *PathRule) Match(path string) bool {
+	if r == nil || len(r.patterns) == 0 {
+		return true
+	}
+
+	slashPath := filepath.ToSlash(path)
+	for _, p := range r.patterns {
+		if p.match(slashPath) {
+			return p.include
+		}
+	}
+	return !r.hasInclude
+}
+
+// clone은 규칙의 복사본을 반환합니다. 컴파일된 패턴은 불변이므로 공유합니다.
+func (r *PathRule) clone() *PathRule {
+	if r == nil {
+		return nil
+	}
+	return &PathRule{patterns: append([]pathPattern(nil), r.patterns...), hasInclude: r.hasInclude}
+}
+
+// Empty는 규칙이 하나도 없는지 확인합니다.
+func (r *PathRule) Empty() bool {
+	return r == nil || len(r.patterns) == 0
+}
+
+// String은 규칙 목록을 "+패턴"(포함), "-패턴"(제외) 형식으로 반환합니다.
+func (r *PathRule) String() string {
+	if r == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(r.patterns))
+	for _, p := range r.patterns {
+		if p.include {
+			parts = append(parts, "+"+p.source)
+		} else {
+			parts = append(parts, "-"+p.source)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
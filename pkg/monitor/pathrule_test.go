@@ -0,0 +1,80 @@
+package monitor
+
+import "testing"
+
+func TestPathRulePrecedence(t *testing.T) {
+	rule := &PathRule{}
+	if err := rule.Exclude("**/Temp/*.log"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rule.Include("**/Temp/**"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rule.Exclude(`re:(?i)/winsxs/`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"C:/Users/me/AppData/Local/Temp/drop.exe", true},
+		{"C:/Users/me/AppData/Local/Temp/sub/drop.dll", true},
+		{"C:/Users/me/AppData/Local/Temp/install.log", false}, // 앞선 제외 규칙이 우선
+		{"C:/Windows/WinSxS/x86/a.dll", false},
+		{"C:/Program Files/app/app.exe", false}, // 포함 규칙이 있으므로 일치하지 않으면 제외
+	}
+	for _, tt := range tests {
+		if got := rule.Match(tt.path); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathRuleOrderMatters(t *testing.T) {
+	includeFirst := &PathRule{}
+	includeFirst.Include("**/Temp/**")
+	includeFirst.Exclude("**/*.log")
+
+	excludeFirst := &PathRule{}
+	excludeFirst.Exclude("**/*.log")
+	excludeFirst.Include("**/Temp/**")
+
+	path := "/tmp/Temp/a.log"
+	if !includeFirst.Match(path) {
+		t.Errorf("Expected first matching include rule to win for %s", path)
+	}
+	if excludeFirst.Match(path) {
+		t.Errorf("Expected first matching exclude rule to win for %s", path)
+	}
+}
+
+func TestPathRuleExcludeOnly(t *testing.T) {
+	rule := &PathRule{}
+	rule.Exclude("**/node_modules/**")
+
+	if !rule.Match("/home/me/project/main.exe") {
+		t.Error("Expected unmatched path to be included when there are no include rules")
+	}
+	if rule.Match("/home/me/project/node_modules/x/bin.exe") {
+		t.Error("Expected node_modules path to be excluded")
+	}
+
+	var empty *PathRule
+	if !empty.Match("/any/path") {
+		t.Error("Expected nil rule to include every path")
+	}
+}
+
+func TestPathRuleInvalidPatterns(t *testing.T) {
+	rule := &PathRule{}
+	if err := rule.Include("re:("); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+	if err := rule.Include("[a-"); err == nil {
+		t.Error("Expected error for invalid glob")
+	}
+	if !rule.Empty() {
+		t.Error("Invalid patterns should not be added")
+	}
+}
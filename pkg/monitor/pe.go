@@ -0,0 +1,233 @@
+package monitor
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// PESection은 PE 섹션의 이름, 원시 데이터 크기와 엔트로피(0~8 비트/바이트)입니다.
+type PESection struct {
+	Name    string  `json:"name"`
+	Size    uint32  `json:"size"`
+	Entropy float64 `json:"entropy"`
+}
+
+// PEInfo는 PE(EXE/DLL) 파일 헤더에서 추출한 메타데이터입니다.
+type PEInfo struct {
+	Machine   string
+	Subsystem string
+	IsDLL     bool
+	Timestamp time.Time
+	Sections  []PESection
+	Imports   []string
+}
+
+var peMachineNames = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_I386:  "i386",
+	pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+	pe.IMAGE_FILE_MACHINE_ARM:   "arm",
+	pe.IMAGE_FILE_MACHINE_ARMNT: "armnt",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+	pe.IMAGE_FILE_MACHINE_IA64:  "ia64",
+}
+
+var peSubsystemNames = map[uint16]string{
+	pe.IMAGE_SUBSYSTEM_NATIVE:                   "native",
+	pe.IMAGE_SUBSYSTEM_WINDOWS_GUI:              "windows-gui",
+	pe.IMAGE_SUBSYSTEM_WINDOWS_CUI:              "windows-cui",
+	pe.IMAGE_SUBSYSTEM_POSIX_CUI:                "posix-cui",
+	pe.IMAGE_SUBSYSTEM_WINDOWS_CE_GUI:           "windows-ce-gui",
+	pe.IMAGE_SUBSYSTEM_EFI_APPLICATION:          "efi-application",
+	pe.IMAGE_SUBSYSTEM_EFI_BOOT_SERVICE_DRIVER:  "efi-boot-service-driver",
+	pe.IMAGE_SUBSYSTEM_EFI_RUNTIME_DRIVER:       "efi-runtime-driver",
+	pe.IMAGE_SUBSYSTEM_XBOX:                     "xbox",
+	pe.IMAGE_SUBSYSTEM_WINDOWS_BOOT_APPLICATION: "windows-boot-application",
+}
+
+// ParsePE는 debug/pe를 사용하여 PE 파일의 헤더 메타데이터를 추출합니다.
+func ParsePE(path string) (*PEInfo, error) {
+	f, err := pe.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info := &PEInfo{
+		Machine:   lookupName(peMachineNames, f.Machine),
+		IsDLL:     f.Characteristics&pe.IMAGE_FILE_DLL != 0,
+		Timestamp: time.Unix(int64(f.TimeDateStamp), 0).UTC(),
+	}
+
+	switch oh := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		info.Subsystem = lookupName(peSubsystemNames, oh.Subsystem)
+	case *pe.OptionalHeader64:
+		info.Subsystem = lookupName(peSubsystemNames, oh.Subsystem)
+	}
+
+	for _, s := range f.Sections {
+		entropy, err := sectionEntropy(s)
+		if err != nil {
+			return nil, fmt.Errorf("섹션 %s 읽기 실패: %v", s.Name, err)
+		}
+		info.Sections = append(info.Sections, PESection{Name: s.Name, Size: s.Size, Entropy: entropy})
+	}
+
+	info.Imports, err = importedDLLs(f)
+	if err != nil {
+		return nil, fmt.Errorf("가져오기 테이블 읽기 실패: %v", err)
+	}
+	return info, nil
+}
+
+// Metadata는 PE 정보를 이벤트 메타데이터 키/값으로 변환합니다.
+// 섹션과 가져오기 목록은 JSON 배열로 저장됩니다.
+func (p *PEInfo) Metadata() map[string]string {
+	sections, _ := json.Marshal(p.Sections)
+	imports, _ := json.Marshal(p.Imports)
+	return map[string]string{
+		"pe.machine":   p.Machine,
+		"pe.subsystem": p.Subsystem,
+		"pe.dll":       strconv.FormatBool(p.IsDLL),
+		"pe.timestamp": p.Timestamp.Format(time.RFC3339),
+		"pe.sections":  string(sections),
+		"pe.imports":   string(imports),
+	}
+}
+
+// sectionEntropy는 섹션 원시 데이터의 섀넌 엔트로피를 계산합니다.
+// 압축되거나 암호화된 섹션은 8에 가까운 값을 가집니다.
+func sectionEntropy(s *pe.Section) (float64, error) {
+	var counts [256]int
+	var total int
+	buf := make([]byte, 32*1024)
+	r := s.Open()
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			counts[b]++
+		}
+		total += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	var entropy float64
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return math.Round(entropy*1000) / 1000, nil
+}
+
+// importedDLLs는 가져오기 디렉토리에서 DLL 이름 목록을 읽습니다.
+// debug/pe의 ImportedLibraries는 구현되어 있지 않고 ImportedSymbols는 서수로만
+// 가져오는 DLL을 누락하므로, 가져오기 디스크립터를 직접 읽습니다.
+func importedDLLs(f *pe.File) ([]string, error) {
+	var dir pe.DataDirectory
+	switch oh := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		if oh.NumberOfRvaAndSizes <= pe.IMAGE_DIRECTORY_ENTRY_IMPORT {
+			return nil, nil
+		}
+		dir = oh.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_IMPORT]
+	case *pe.OptionalHeader64:
+		if oh.NumberOfRvaAndSizes <= pe.IMAGE_DIRECTORY_ENTRY_IMPORT {
+			return nil, nil
+		}
+		dir = oh.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_IMPORT]
+	default:
+		return nil, nil
+	}
+	if dir.VirtualAddress == 0 {
+		return nil, nil
+	}
+
+	section := sectionForRVA(f, dir.VirtualAddress)
+	if section == nil {
+		return nil, nil
+	}
+	data, err := section.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := map[*pe.Section][]byte{section: data}
+	var dlls []string
+	for off := dir.VirtualAddress - section.VirtualAddress; int(off)+20 <= len(data); off += 20 {
+		desc := data[off : off+20]
+		nameRVA := binary.LittleEndian.Uint32(desc[12:16])
+		firstThunk := binary.LittleEndian.Uint32(desc[16:20])
+		if nameRVA == 0 && firstThunk == 0 {
+			break
+		}
+
+		name, err := readCString(f, nameRVA, cache)
+		if err != nil {
+			return nil, err
+		}
+		dlls = append(dlls, name)
+	}
+	return dlls, nil
+}
+
+// sectionForRVA는 RVA를 포함하는 섹션을 찾습니다.
+func sectionForRVA(f *pe.File, rva uint32) *pe.Section {
+	for _, s := range f.Sections {
+		if s.VirtualAddress <= rva && rva-s.VirtualAddress < s.VirtualSize {
+			return s
+		}
+	}
+	return nil
+}
+
+// readCString은 RVA 위치의 NUL로 끝나는 문자열을 읽습니다.
+// 읽은 섹션 데이터는 cache에 보관하여 재사용합니다.
+func readCString(f *pe.File, rva uint32, cache map[*pe.Section][]byte) (string, error) {
+	section := sectionForRVA(f, rva)
+	if section == nil {
+		return "", fmt.Errorf("RVA 0x%x를 포함하는 섹션이 없습니다", rva)
+	}
+	data, ok := cache[section]
+	if !ok {
+		var err error
+		if data, err = section.Data(); err != nil {
+			return "", err
+		}
+		cache[section] = data
+	}
+
+	off := rva - section.VirtualAddress
+	if int(off) >= len(data) {
+		return "", fmt.Errorf("RVA 0x%x가 섹션 데이터 범위를 벗어났습니다", rva)
+	}
+	end := bytes.IndexByte(data[off:], 0)
+	if end < 0 {
+		return "", fmt.Errorf("RVA 0x%x의 문자열이 끝나지 않습니다", rva)
+	}
+	return string(data[off : int(off)+end]), nil
+}
+
+func lookupName(names map[uint16]string, v uint16) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", v)
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePE32(t *testing.T) {
+	info, err := ParsePE(filepath.Join("testdata", "tiny32.exe"))
+	if err != nil {
+		t.Fatalf("ParsePE failed: %v", err)
+	}
+
+	if info.Machine != "i386" || info.Subsystem != "windows-cui" || info.IsDLL {
+		t.Errorf("Unexpected header info: %+v", info)
+	}
+	if want := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC); !info.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, info.Timestamp)
+	}
+	if want := []string{"KERNEL32.dll", "USER32.dll"}; !reflect.DeepEqual(info.Imports, want) {
+		t.Errorf("Expected imports %v, got %v", want, info.Imports)
+	}
+
+	if len(info.Sections) != 3 {
+		t.Fatalf("Expected 3 sections, got %+v", info.Sections)
+	}
+	if info.Sections[0].Name != ".text" || info.Sections[0].Entropy < 7 {
+		t.Errorf("Expected high entropy .text section, got %+v", info.Sections[0])
+	}
+	if info.Sections[2].Name != ".data" || info.Sections[2].Entropy != 0 {
+		t.Errorf("Expected zero entropy .data section, got %+v", info.Sections[2])
+	}
+}
+
+func TestParsePE64DLL(t *testing.T) {
+	info, err := ParsePE(filepath.Join("testdata", "tiny64.dll"))
+	if err != nil {
+		t.Fatalf("ParsePE failed: %v", err)
+	}
+
+	if info.Machine != "amd64" || info.Subsystem != "windows-gui" || !info.IsDLL {
+		t.Errorf("Unexpected header info: %+v", info)
+	}
+	// WS2_32.dll은 서수로만 가져오므로 이름 기반 심볼 목록에는 나타나지 않음
+	if want := []string{"KERNEL32.dll", "WS2_32.dll"}; !reflect.DeepEqual(info.Imports, want) {
+		t.Errorf("Expected imports %v, got %v", want, info.Imports)
+	}
+
+	meta := info.Metadata()
+	if meta["pe.dll"] != "true" || meta["pe.imports"] != `["KERNEL32.dll","WS2_32.dll"]` {
+		t.Errorf("Unexpected metadata: %v", meta)
+	}
+}
+
+func TestParsePEInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fake.exe")
+	os.WriteFile(path, []byte("MZ not really a PE file"), 0644)
+	if _, err := ParsePE(path); err == nil {
+		t.Error("Expected error for truncated PE file")
+	}
+}
+
+func TestFakeBackendPEMetadata(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.analyzer.retryDelay = 100 * time.Millisecond
+		if err := m.SetOperations([]string{"CREATE", "WRITE"}); err != nil {
+			t.Fatal(err)
+		}
+		m.SetWriteSettle(50 * time.Millisecond)
+	})
+	dbPath := mon.dbPath
+
+	data, err := os.ReadFile(filepath.Join("testdata", "tiny64.dll"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 빈 파일로 생성된 뒤 내용이 쓰이는 경우에도 CREATE와 MODIFIED 모두 분석되어야 함
+	path := filepath.Join(dir, "payload.dll")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchWrite})
+
+	events := waitEvents(t, mon, 2)
+	if events[0].Operation != OperationCreate || events[1].Operation != OperationModified {
+		t.Fatalf("Expected CREATE and MODIFIED, got %v", events)
+	}
+	for _, event := range events {
+		if len(event.Metadata) != 0 {
+			t.Errorf("Expected metadata to be attached later, got %v", event.Metadata)
+		}
+	}
+
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	want, err := ParsePE(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range events {
+		meta, err := db.GetEventMetadata(event.ID)
+		if err != nil {
+			t.Fatalf("GetEventMetadata failed: %v", err)
+		}
+		if meta["pe.machine"] != "amd64" || !reflect.DeepEqual(meta, want.Metadata()) {
+			t.Errorf("Expected stored metadata %v for %s event, got %v", want.Metadata(), event.Operation, meta)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval       = 10 * time.Second
+	defaultPollDirsPerSecond  = 500
+	defaultPollFilesPerSecond = 20000
+)
+
+// PollingOptions는 폴링 감시 백엔드의 스캔 주기와 속도 제한입니다.
+// 0인 값은 기본값(10초, 초당 500개 디렉토리, 초당 20000개 항목)이 사용되고,
+// DirsPerSecond와 FilesPerSecond가 음수이면 제한하지 않습니다.
+// 스캔 한 번이 Interval보다 오래 걸리면 다음 스캔은 이전 스캔이 끝난 직후에 시작됩니다.
+type PollingOptions struct {
+	Interval       time.Duration
+	DirsPerSecond  int
+	FilesPerSecond int
+}
+
+// withDefaults는 0인 값을 기본값으로 채운 옵션을 반환합니다.
+func (o PollingOptions) withDefaults() PollingOptions {
+	if o.Interval <= 0 {
+		o.Interval = defaultPollInterval
+	}
+	if o.DirsPerSecond == 0 {
+		o.DirsPerSecond = defaultPollDirsPerSecond
+	}
+	if o.FilesPerSecond == 0 {
+		o.FilesPerSecond = defaultPollFilesPerSecond
+	}
+	return o
+}
+
+// fileState는 폴링 스냅샷에 기록되는 항목 하나의 상태입니다.
+type fileState struct {
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	inode   uint64
+}
+
+// pollingBackend는 등록된 디렉토리의 항목을 주기적으로 읽어 이전 스냅샷과 비교하는 감시 백엔드입니다.
+// inotify 이벤트가 전달되지 않는 네트워크 공유, 일부 FUSE 마운트, 컨테이너의 바인드 마운트에서 사용합니다.
+// 새 항목은 CREATE, 사라진 항목은 REMOVE, 크기나 수정 시간이 바뀐 파일은 WRITE, 권한 변경은 CHMOD로 보고하며,
+// 같은 이름의 inode가 바뀌면(교체) REMOVE 후 CREATE로 보고합니다.
+type pollingBackend struct {
+	opts PollingOptions
+
+	mu   sync.Mutex
+	dirs map[string]map[string]fileState // 감시 디렉토리별 항목 스냅샷
+	// fresh는 스캔에서 새로 발견된 디렉토리입니다. 그 아래를 등록할 때는 빈 스냅샷에서 시작하여
+	// 등록 전에 만들어진 항목도 다음 스캔에서 CREATE로 보고되게 합니다.
+	fresh map[string]time.Time
+
+	events    chan WatchEvent
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPollingBackend는 폴링 기반 감시 백엔드를 생성합니다.
+// SetBackend로 모든 장치에 사용하거나, SetDeviceBackend로 특정 장치에만 사용할 수 있습니다.
+func NewPollingBackend(opts PollingOptions) WatcherBackend {
+	return newPollingBackend(opts)
+}
+
+func newPollingBackend(opts PollingOptions) *pollingBackend {
+	b := &pollingBackend{
+		opts:   opts.withDefaults(),
+		dirs:   make(map[string]map[string]fileState),
+		fresh:  make(map[string]time.Time),
+		events: make(chan WatchEvent),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Add는 디렉토리를 감시 대상에 추가하고 현재 항목을 기준 스냅샷으로 기록합니다.
+func (b *pollingBackend) Add(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("디렉토리가 아닙니다: %s", path)
+	}
+	snapshot, err := readSnapshot(path)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.dirs[path]; !ok {
+		if b.isFresh(path) {
+			snapshot = map[string]fileState{}
+		}
+		b.dirs[path] = snapshot
+	}
+	return nil
+}
+
+// isFresh는 경로나 그 상위 디렉토리가 최근 스캔에서 새로 발견되었는지 확인합니다. mu를 잡은 상태에서 호출해야 합니다.
+func (b *pollingBackend) isFresh(path string) bool {
+	for {
+		if found, ok := b.fresh[path]; ok && time.Since(found) < b.freshTTL() {
+			return true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		path = parent
+	}
+}
+
+// freshTTL은 새로 발견된 디렉토리를 기억하는 시간입니다.
+func (b *pollingBackend) freshTTL() time.Duration {
+	return 2 * b.opts.Interval
+}
+
+// Remove는 디렉토리를 감시 대상에서 제거합니다.
+func (b *pollingBackend) Remove(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.dirs[path]; !ok {
+		return fmt.Errorf("감시 중이 아닌 디렉토리입니다: %s", path)
+	}
+	delete(b.dirs, path)
+	return nil
+}
+
+func (b *pollingBackend) Events() <-chan WatchEvent { return b.events }
+func (b *pollingBackend) Errors() <-chan error      { return b.errors }
+
+// Close는 스캔을 중지하고 이벤트 채널을 닫습니다.
+func (b *pollingBackend) Close() error {
+	b.closeOnce.Do(func() { close(b.done) })
+	return nil
+}
+
+// run은 Interval마다 등록된 모든 디렉토리를 스캔합니다.
+func (b *pollingBackend) run() {
+	defer close(b.events)
+	defer close(b.errors)
+
+	dirLimit := newRateLimiter(b.opts.DirsPerSecond)
+	fileLimit := newRateLimiter(b.opts.FilesPerSecond)
+	for {
+		started := time.Now()
+		if !b.scan(dirLimit, fileLimit) {
+			return
+		}
+		select {
+		case <-time.After(time.Until(started.Add(b.opts.Interval))):
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// scan은 등록된 디렉토리를 한 번씩 읽어 변경 사항을 이벤트로 보냅니다. 닫히면 false를 반환합니다.
+func (b *pollingBackend) scan(dirLimit, fileLimit *rateLimiter) bool {
+	b.mu.Lock()
+	dirs := make([]string, 0, len(b.dirs))
+	for dir := range b.dirs {
+		dirs = append(dirs, dir)
+	}
+	for dir, found := range b.fresh {
+		if time.Since(found) >= b.freshTTL() {
+			delete(b.fresh, dir)
+		}
+	}
+	b.mu.Unlock()
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if !dirLimit.wait(1, b.done) {
+			return false
+		}
+		current, err := readSnapshot(dir)
+		if err != nil && !os.IsNotExist(err) {
+			if !b.send(nil, err) {
+				return false
+			}
+			continue
+		}
+		if !fileLimit.wait(len(current), b.done) {
+			return false
+		}
+
+		b.mu.Lock()
+		previous, ok := b.dirs[dir]
+		if !ok {
+			// 스캔 중에 감시가 해제됨
+			b.mu.Unlock()
+			continue
+		}
+		if os.IsNotExist(err) {
+			// 디렉토리가 사라지면 inotify처럼 감시가 자동으로 해제되고 남은 항목은 삭제로 보고
+			delete(b.dirs, dir)
+			current = nil
+		} else {
+			b.dirs[dir] = current
+		}
+		events := diffSnapshots(dir, previous, current)
+		for _, event := range events {
+			if event.Op.Has(WatchCreate) && current[filepath.Base(event.Name)].mode.IsDir() {
+				b.fresh[event.Name] = time.Now()
+			}
+		}
+		b.mu.Unlock()
+
+		for _, event := range events {
+			if !b.send(&event, nil) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// send는 이벤트나 오류를 전달합니다. 백엔드가 닫히면 false를 반환합니다.
+func (b *pollingBackend) send(event *WatchEvent, err error) bool {
+	if event != nil {
+		select {
+		case b.events <- *event:
+			return true
+		case <-b.done:
+			return false
+		}
+	}
+	select {
+	case b.errors <- err:
+		return true
+	case <-b.done:
+		return false
+	}
+}
+
+// readSnapshot은 디렉토리의 항목별 상태를 읽습니다. 읽는 사이에 사라진 항목은 건너뜁니다.
+func readSnapshot(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshot[entry.Name()] = fileState{
+			size:    info.Size(),
+			mode:    info.Mode(),
+			modTime: info.ModTime(),
+			inode:   fileInode(info),
+		}
+	}
+	return snapshot, nil
+}
+
+// diffSnapshots는 두 스냅샷을 비교하여 이름순으로 정렬된 이벤트 목록을 반환합니다.
+func diffSnapshots(dir string, previous, current map[string]fileState) []WatchEvent {
+	var events []WatchEvent
+	for name, before := range previous {
+		path := filepath.Join(dir, name)
+		after, ok := current[name]
+		switch {
+		case !ok:
+			events = append(events, WatchEvent{Name: path, Op: WatchRemove})
+		case before.mode.Type() != after.mode.Type() || (before.inode != 0 && after.inode != 0 && before.inode != after.inode):
+			// 같은 이름의 다른 파일로 교체됨
+			events = append(events, WatchEvent{Name: path, Op: WatchRemove}, WatchEvent{Name: path, Op: WatchCreate})
+		case !after.mode.IsDir() && (before.size != after.size || !before.modTime.Equal(after.modTime)):
+			events = append(events, WatchEvent{Name: path, Op: WatchWrite})
+		case before.mode.Perm() != after.mode.Perm():
+			events = append(events, WatchEvent{Name: path, Op: WatchChmod})
+		}
+	}
+	for name := range current {
+		if _, ok := previous[name]; !ok {
+			events = append(events, WatchEvent{Name: filepath.Join(dir, name), Op: WatchCreate})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	return events
+}
+
+// rateLimiter는 초당 처리량을 제한합니다. nil이면 제한하지 않습니다.
+type rateLimiter struct {
+	every time.Duration
+	next  time.Time
+}
+
+// newRateLimiter는 초당 perSecond개를 허용하는 제한기를 만듭니다. perSecond가 0 이하이면 nil을 반환합니다.
+func newRateLimiter(perSecond int) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{every: time.Second / time.Duration(perSecond)}
+}
+
+// wait는 n개를 처리할 수 있을 때까지 기다립니다. done이 닫히면 false를 반환합니다.
+func (l *rateLimiter) wait(n int, done <-chan struct{}) bool {
+	if l == nil || n == 0 {
+		select {
+		case <-done:
+			return false
+		default:
+			return true
+		}
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(n) * l.every)
+	if delay <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(delay):
+		return true
+	case <-done:
+		return false
+	}
+}
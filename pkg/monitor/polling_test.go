@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fastPolling은 테스트용 폴링 옵션입니다.
+var fastPolling = PollingOptions{Interval: 20 * time.Millisecond, DirsPerSecond: -1, FilesPerSecond: -1}
+
+// expectWatchEvent는 백엔드에서 지정한 이벤트가 올 때까지 기다립니다.
+func expectWatchEvent(t *testing.T, b WatcherBackend, name string, op WatchOp) {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-b.Events():
+			if event.Name == name && event.Op == op {
+				return
+			}
+		case err := <-b.Errors():
+			t.Fatalf("Unexpected error: %v", err)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s %s", op, name)
+		}
+	}
+}
+
+func TestPollingBackend(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.exe")
+	os.WriteFile(existing, []byte("old"), 0644)
+
+	b := NewPollingBackend(fastPolling)
+	defer b.Close()
+	if err := b.Add(dir); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	path := filepath.Join(dir, "a.exe")
+	os.WriteFile(path, []byte("MZ"), 0644)
+	expectWatchEvent(t, b, path, WatchCreate)
+
+	os.WriteFile(existing, []byte("changed"), 0644)
+	expectWatchEvent(t, b, existing, WatchWrite)
+
+	os.Remove(path)
+	expectWatchEvent(t, b, path, WatchRemove)
+
+	if err := b.Remove(dir); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := b.Remove(dir); err == nil {
+		t.Error("Expected error when removing an unwatched directory")
+	}
+}
+
+func TestPollingDevice(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.SetPollingOptions(fastPolling)
+		if err := m.SetDeviceBackend(m.GetDevices()[0], BackendPolling); err != nil {
+			t.Fatalf("SetDeviceBackend failed: %v", err)
+		}
+	})
+	defer mon.Stop()
+
+	// 폴링 장치의 디렉토리는 기본 백엔드에 등록되지 않음
+	if watched := fake.Watched(); len(watched) != 0 {
+		t.Errorf("Expected no directories on the notify backend, got %v", watched)
+	}
+
+	// 장치 루트의 감시 등록이 끝난 뒤에 파일을 만들어야 기준 스냅샷에 포함되지 않음
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mon.watchMutex.Lock()
+		ready := mon.watched[dir]
+		mon.watchMutex.Unlock()
+		if ready {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the device to be watched")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 새 디렉토리 안에 함께 만들어진 파일도 다음 스캔에서 보고됨
+	sub := filepath.Join(dir, "sub")
+	os.MkdirAll(filepath.Join(sub, "nested"), 0755)
+	os.WriteFile(filepath.Join(sub, "b.exe"), []byte("MZ"), 0644)
+	os.WriteFile(filepath.Join(sub, "nested", "c.dll"), []byte("MZ"), 0644)
+
+	events := waitEvents(t, mon, 2)
+	got := map[string]string{events[0].Path: events[0].Operation, events[1].Path: events[1].Operation}
+	for _, path := range []string{filepath.Join(sub, "b.exe"), filepath.Join(sub, "nested", "c.dll")} {
+		if got[path] != OperationCreate {
+			t.Errorf("Expected CREATE for %s, got %v", path, got)
+		}
+	}
+
+	os.Remove(filepath.Join(sub, "b.exe"))
+	if events := waitEvents(t, mon, 1); events[0].Operation != OperationRemove {
+		t.Errorf("Expected REMOVE, got %+v", events[0])
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(100)
+	done := make(chan struct{})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		limiter.wait(5, done)
+	}
+	// 처음 5개는 바로 처리되고 나머지 10개는 초당 100개로 제한됨
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected rate limit to delay at least 100ms, took %v", elapsed)
+	}
+
+	close(done)
+	if limiter.wait(1000, done) {
+		t.Error("Expected wait to return false after done is closed")
+	}
+	if newRateLimiter(0) != nil {
+		t.Error("Expected nil limiter for unlimited rate")
+	}
+}
+
+func TestWatchLimitFallback(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.SetPollingOptions(fastPolling)
+		m.backend.(*FakeBackend).SetWatchLimit(2)
+		os.MkdirAll(filepath.Join(m.GetDevices()[0], "a", "sub"), 0755)
+		os.Mkdir(filepath.Join(m.GetDevices()[0], "b"), 0755)
+	})
+	defer mon.Stop()
+
+	// 한도를 넘은 디렉토리(a/sub, b)는 폴링으로 감시됨
+	var status Status
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status = mon.Status()
+		if status.NotifyDirs+status.PollingDirs == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the walk, status %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !status.Running || !status.WatchLimitReached || status.NotifyDirs != 2 || status.FallbackDirs != 2 || status.UncoveredDirs != 0 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if watched := fake.Watched(); len(watched) != 2 {
+		t.Errorf("Expected 2 directories on the notify backend, got %v", watched)
+	}
+
+	path := filepath.Join(dir, "b", "payload.exe")
+	os.WriteFile(path, []byte("MZ"), 0644)
+	events := waitEvents(t, mon, 1)
+	if events[0].Operation != OperationCreate || events[0].Path != path {
+		t.Errorf("Expected CREATE %s from the fallback poller, got %+v", path, events[0])
+	}
+
+	// 폴링으로 전환된 하위 트리에 새로 생긴 디렉토리도 폴링으로 감시
+	os.Mkdir(filepath.Join(dir, "b", "new"), 0755)
+	deadline = time.Now().Add(2 * time.Second)
+	for mon.Status().FallbackDirs != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected new directory under the fallback subtree to be polled, status %+v", mon.Status())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mon.Stop()
+	if status := mon.Status(); status.Running || status.PollingDirs != 0 {
+		t.Errorf("Expected empty status after Stop, got %+v", status)
+	}
+}
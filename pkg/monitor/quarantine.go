@@ -0,0 +1,374 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// QuarantineEntry.Status에 사용되는 격리 상태입니다.
+const (
+	QuarantineStatusActive   = "quarantined" // 격리 디렉토리에 보관 중
+	QuarantineStatusRestored = "restored"    // 원래 위치로 복원됨
+	QuarantineStatusPurged   = "purged"      // 격리 디렉토리에서 삭제됨
+	QuarantineStatusDryRun   = "dry-run"     // dry-run 모드에서 격리 대상으로 기록만 함
+)
+
+const (
+	// quarantineSuffix는 격리된 파일 이름 뒤에 붙이는 확장자입니다.
+	// 권한 비트가 없는 Windows에서도 실수로 실행되거나 확장자 필터에 걸리지 않게 합니다.
+	quarantineSuffix = ".quarantine"
+	// quarantineSuppressWindow 안에 도착한 원래 경로의 이름 변경, 삭제 이벤트는 격리 이동으로 보고 무시합니다.
+	quarantineSuppressWindow = 5 * time.Second
+)
+
+// QuarantineOptions는 의심 파일 격리 설정입니다.
+type QuarantineOptions struct {
+	Dir    string // 격리 디렉토리 (비어 있으면 격리하지 않음)
+	DryRun bool   // 파일을 옮기지 않고 격리 대상만 quarantine 테이블에 기록
+}
+
+// QuarantineEntry는 quarantine 테이블에 기록되는 격리 파일 하나입니다.
+// Mode, UID, GID, Owner, Group은 격리 전 파일의 상태이며, 복원할 때 다시 적용됩니다.
+type QuarantineEntry struct {
+	ID             int64
+	EventID        int64  // 격리를 일으킨 이벤트 (직접 격리하면 0)
+	Reason         string // 예: "signature:eicar-test-file", "rule:temp-exe-dropped"
+	OriginalPath   string
+	QuarantinePath string
+	SHA256         string
+	Size           int64
+	Mode           os.FileMode
+	UID            int // Windows에서는 -1
+	GID            int // Windows에서는 -1
+	Owner          string
+	Group          string
+	QuarantinedAt  time.Time
+	Status         string
+	ReleasedAt     time.Time // 복원 또는 삭제한 시간
+}
+
+// String은 격리 기록을 한 줄로 표시합니다.
+func (e QuarantineEntry) String() string {
+	text := fmt.Sprintf("#%d [%s] %s (%s, sha256: %s)", e.ID, e.Status, e.OriginalPath, e.Reason, e.SHA256)
+	if e.QuarantinePath != "" {
+		text += " -> " + e.QuarantinePath
+	}
+	return text
+}
+
+// quarantiner는 파일 격리와 격리 이동으로 생긴 이벤트의 억제를 담당합니다.
+type quarantiner struct {
+	options QuarantineOptions
+	// moveMutex는 같은 파일을 두 번 옮기지 않도록 격리 작업을 하나씩 실행합니다.
+	moveMutex sync.Mutex
+	mu        sync.Mutex
+	expected  map[string]time.Time // 격리로 옮긴 원래 경로와 그 이벤트를 무시할 기한
+}
+
+// enabled는 격리 디렉토리가 설정되어 있는지 확인합니다.
+func (q *quarantiner) enabled() bool {
+	return q.options.Dir != ""
+}
+
+// expect는 path에서 격리 이동으로 생길 이벤트를 무시하도록 등록합니다.
+func (q *quarantiner) expect(path string, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.expected == nil {
+		q.expected = make(map[string]time.Time)
+	}
+	q.expected[path] = now.Add(quarantineSuppressWindow)
+}
+
+// forget은 격리 이동에 실패한 path의 등록을 지웁니다.
+func (q *quarantiner) forget(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.expected, path)
+}
+
+// suppressed는 격리 디렉토리 안의 변경이거나 격리로 옮긴 원래 경로의 이름 변경, 삭제이면 true를 반환합니다.
+// 원래 경로의 이벤트는 한 번만 무시하므로, 같은 경로에 다시 생성된 파일은 그대로 기록됩니다.
+func (q *quarantiner) suppressed(event WatchEvent, now time.Time) bool {
+	if !q.enabled() {
+		return false
+	}
+	if isUnderPath(event.Name, q.options.Dir) {
+		return true
+	}
+	if !event.Op.Has(WatchRename) && !event.Op.Has(WatchRemove) {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	until, ok := q.expected[event.Name]
+	if !ok {
+		return false
+	}
+	delete(q.expected, event.Name)
+	return !now.After(until)
+}
+
+// SetQuarantine은 의심 파일을 옮겨 둘 격리 디렉토리를 설정합니다. Start 전에 호출해야 합니다.
+// 디렉토리는 소유자만 접근할 수 있도록(0700) 만들어지며, 그 안의 변경은 기록하지 않습니다.
+// 빈 Dir을 설정하면 격리하지 않습니다.
+func (m *Monitor) SetQuarantine(opts QuarantineOptions) error {
+	if opts.Dir != "" {
+		dir, err := filepath.Abs(opts.Dir)
+		if err != nil {
+			return err
+		}
+		opts.Dir = dir
+		if !opts.DryRun {
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return fmt.Errorf("격리 디렉토리 생성 실패: %v", err)
+			}
+			if err := os.Chmod(dir, 0700); err != nil {
+				return fmt.Errorf("격리 디렉토리 권한 설정 실패: %v", err)
+			}
+		}
+		mode := ""
+		if opts.DryRun {
+			mode = " (dry-run)"
+		}
+		log.Printf("격리 디렉토리 설정됨: %s%s", dir, mode)
+	}
+	m.quarantine.options = opts
+	return nil
+}
+
+// GetQuarantine은 현재 격리 설정을 반환합니다.
+func (m *Monitor) GetQuarantine() QuarantineOptions {
+	return m.quarantine.options
+}
+
+// Quarantine은 파일을 격리 디렉토리로 옮기고 실행 권한을 제거한 뒤, 원래 경로와 해시, 권한, 소유자를
+// quarantine 테이블에 기록합니다. eventID와 reason은 격리를 일으킨 이벤트와 이유입니다.
+// dry-run 모드에서는 파일을 옮기지 않고 dry-run 상태로 기록만 합니다.
+// 사용자가 복원한 파일과 내용이 같은 파일은 다시 격리하지 않고 그 복원 기록을 반환합니다.
+// 해시 최대 크기(SetHashMaxSize)보다 큰 파일은 격리하지 않습니다. 모니터가 실행 중일 때만 사용할 수 있습니다.
+func (m *Monitor) Quarantine(path, reason string, eventID int64) (*QuarantineEntry, error) {
+	q := &m.quarantine
+	if !q.enabled() {
+		return nil, fmt.Errorf("격리 디렉토리가 설정되지 않았습니다")
+	}
+	m.runMutex.Lock()
+	db := m.db
+	running := m.running
+	m.runMutex.Unlock()
+	if !running || db == nil {
+		return nil, fmt.Errorf("모니터가 실행 중이 아닙니다")
+	}
+
+	q.moveMutex.Lock()
+	defer q.moveMutex.Unlock()
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("일반 파일이 아닙니다: %s", path)
+	}
+	// 잠금을 잡은 채 큰 파일을 끝까지 읽지 않도록 해시 최대 크기를 넘는 파일은 격리하지 않음
+	if info.Size() > m.hasher.maxSize {
+		return nil, fmt.Errorf("파일 크기 %d바이트가 해시 제한(%d바이트)을 초과합니다: %s", info.Size(), m.hasher.maxSize, path)
+	}
+	st := statFile(path)
+	digest := sha256File(path, m.hasher.maxSize)
+	if st == nil || digest == "" {
+		return nil, fmt.Errorf("파일을 읽을 수 없습니다: %s", path)
+	}
+
+	latest, err := db.LatestQuarantineEntry(path, digest)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		switch {
+		case latest.Status == QuarantineStatusRestored:
+			log.Printf("복원된 파일이므로 격리하지 않음: %s (격리 기록 #%d)", path, latest.ID)
+			return latest, nil
+		case latest.Status == QuarantineStatusDryRun && q.options.DryRun:
+			return latest, nil
+		}
+	}
+
+	now := time.Now()
+	entry := &QuarantineEntry{
+		EventID:       eventID,
+		Reason:        reason,
+		OriginalPath:  path,
+		SHA256:        digest,
+		Size:          st.Size,
+		Mode:          st.Mode,
+		UID:           st.UID,
+		GID:           st.GID,
+		Owner:         st.Owner,
+		Group:         st.Group,
+		QuarantinedAt: now,
+		Status:        QuarantineStatusActive,
+	}
+	if q.options.DryRun {
+		entry.Status = QuarantineStatusDryRun
+		if err := db.SaveQuarantineEntry(entry); err != nil {
+			return nil, err
+		}
+		log.Printf("[격리] (dry-run) 격리 대상: %s (%s)", path, reason)
+		return entry, nil
+	}
+
+	dest := filepath.Join(q.options.Dir, fmt.Sprintf("%d-%s%s", now.UnixNano(), filepath.Base(path), quarantineSuffix))
+	// 이동으로 생기는 이벤트보다 먼저 등록하고, 이동하지 못하면 원래 경로의 이벤트를 다시 기록하도록 지움
+	q.expect(path, now)
+	if err := moveFile(path, dest); err != nil {
+		q.forget(path)
+		return nil, fmt.Errorf("격리 이동 실패: %v", err)
+	}
+	if err := os.Chmod(dest, st.Mode.Perm()&^0111); err != nil {
+		log.Printf("격리 파일 실행 권한 제거 실패: %s - %v", dest, err)
+	}
+	entry.QuarantinePath = dest
+	if err := db.SaveQuarantineEntry(entry); err != nil {
+		// 원래 위치로 되돌리면 다시 감지되어 격리가 반복될 수 있으므로 격리된 상태로 둠
+		return nil, fmt.Errorf("격리 기록 저장 실패 (파일은 %s에 있습니다): %v", dest, err)
+	}
+	log.Printf("[격리] 파일 격리됨: %s -> %s (%s)", path, dest, reason)
+	return entry, nil
+}
+
+// quarantineMatches는 quarantine: true인 시그니처와 일치한 파일을 격리합니다.
+func (m *Monitor) quarantineMatches(matches []SignatureMatch) {
+	if !m.quarantine.enabled() {
+		return
+	}
+	for _, match := range matches {
+		if !match.Quarantine {
+			continue
+		}
+		if _, err := m.Quarantine(match.Path, "signature:"+match.Signature, match.EventID); err != nil {
+			log.Printf("파일 격리 실패: %s - %v", match.Path, err)
+		}
+		// 파일 하나는 한 번만 격리
+		return
+	}
+}
+
+// RestoreQuarantined는 격리된 파일을 원래 경로로 복원하고 격리 전 권한과 소유자를 다시 적용합니다.
+// 원래 경로에 파일이 있거나 격리된 파일의 해시가 기록과 다르면 복원하지 않습니다.
+// dryRun이면 확인만 하고 파일과 기록을 바꾸지 않습니다. 복원한 파일은 모니터가 다시 격리하지 않습니다.
+func RestoreQuarantined(db *Database, id int64, dryRun bool) (*QuarantineEntry, error) {
+	entry, err := activeQuarantineEntry(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(entry.OriginalPath); err == nil {
+		return nil, fmt.Errorf("원래 경로에 이미 파일이 있습니다: %s", entry.OriginalPath)
+	}
+	// 격리 후 커진 파일은 읽지 않고 변경된 것으로 봄
+	if digest := sha256File(entry.QuarantinePath, entry.Size); digest != entry.SHA256 {
+		return nil, fmt.Errorf("격리된 파일이 없거나 변경되었습니다: %s", entry.QuarantinePath)
+	}
+	if dryRun {
+		return entry, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.OriginalPath), 0755); err != nil {
+		return nil, err
+	}
+	if err := moveFile(entry.QuarantinePath, entry.OriginalPath); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(entry.OriginalPath, entry.Mode.Perm()); err != nil {
+		log.Printf("복원한 파일의 권한 설정 실패: %s - %v", entry.OriginalPath, err)
+	}
+	if entry.UID >= 0 && entry.GID >= 0 {
+		if err := os.Lchown(entry.OriginalPath, entry.UID, entry.GID); err != nil {
+			log.Printf("복원한 파일의 소유자 설정 실패: %s - %v", entry.OriginalPath, err)
+		}
+	}
+
+	entry.Status = QuarantineStatusRestored
+	entry.ReleasedAt = time.Now()
+	if err := db.UpdateQuarantineStatus(entry.ID, entry.Status, entry.ReleasedAt); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// PurgeQuarantined는 격리된 파일을 영구히 삭제하고 기록을 purged 상태로 바꿉니다.
+// dryRun이면 확인만 하고 파일과 기록을 바꾸지 않습니다.
+func PurgeQuarantined(db *Database, id int64, dryRun bool) (*QuarantineEntry, error) {
+	entry, err := activeQuarantineEntry(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return entry, nil
+	}
+	if err := os.Remove(entry.QuarantinePath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	entry.Status = QuarantineStatusPurged
+	entry.ReleasedAt = time.Now()
+	if err := db.UpdateQuarantineStatus(entry.ID, entry.Status, entry.ReleasedAt); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// activeQuarantineEntry는 현재 격리 중인 기록을 조회합니다.
+func activeQuarantineEntry(db *Database, id int64) (*QuarantineEntry, error) {
+	entry, err := db.GetQuarantineEntry(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("격리 기록이 없습니다: %d", id)
+	}
+	if entry.Status != QuarantineStatusActive {
+		return nil, fmt.Errorf("격리 중인 파일이 아닙니다: #%d (상태: %s)", id, entry.Status)
+	}
+	return entry, nil
+}
+
+// moveFile은 파일을 옮깁니다. 다른 파일 시스템이라 이름을 바꿀 수 없으면 복사한 뒤 원본을 삭제합니다.
+func moveFile(src, dst string) error {
+	renameErr := os.Rename(src, dst)
+	if renameErr == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return renameErr
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return renameErr
+	}
+	_, err = io.Copy(out, in)
+	if syncErr := out.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("%v (복사 실패: %v)", renameErr, err)
+	}
+	in.Close()
+	if err := os.Remove(src); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
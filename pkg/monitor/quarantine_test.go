@@ -0,0 +1,219 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/signature"
+)
+
+// quarantineSignatures는 "EVIL-MARKER"가 포함된 파일을 격리하는 시그니처입니다.
+func quarantineSignatures(t *testing.T) *signature.Set {
+	t.Helper()
+	set, err := signature.Parse([]byte("signatures:\n  - id: marker\n    quarantine: true\n    strings: {$a: EVIL-MARKER}\n"))
+	if err != nil {
+		t.Fatalf("signature.Parse failed: %v", err)
+	}
+	return set
+}
+
+// waitGone은 파일이 사라질 때까지 기다립니다.
+func waitGone(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s to be quarantined", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQuarantineSignatureMatch(t *testing.T) {
+	var qdir string
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.scanner.retryDelay = 20 * time.Millisecond
+		m.SetSignatures(quarantineSignatures(t))
+		// 감시 대상 안의 격리 디렉토리에서 생기는 이벤트도 무시되어야 함
+		qdir = filepath.Join(m.GetDevices()[0], "quarantine")
+		if err := m.SetQuarantine(QuarantineOptions{Dir: qdir}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	dbPath := mon.dbPath
+
+	path := filepath.Join(dir, "drop.exe")
+	if err := os.WriteFile(path, []byte("MZ EVIL-MARKER"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	created := waitEvents(t, mon, 1)[0]
+	waitGone(t, path)
+
+	// 격리 이동으로 생긴 이벤트는 기록되지 않고, 그 뒤의 이벤트는 그대로 기록됨
+	moved, _ := filepath.Glob(filepath.Join(qdir, "*"))
+	if len(moved) != 1 {
+		t.Fatalf("Expected 1 file in quarantine, got %v", moved)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchRename})
+	fake.Push(WatchEvent{Name: moved[0], Op: WatchCreate})
+	fake.Push(WatchEvent{Name: moved[0], Op: WatchChmod})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "other.exe"), Op: WatchRemove})
+	if next := waitEvents(t, mon, 1)[0]; next.Operation != OperationRemove || filepath.Base(next.Path) != "other.exe" {
+		t.Errorf("Expected quarantine events to be suppressed, got %+v", next)
+	}
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	entries, err := db.GetQuarantineEntries(false)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected 1 quarantine entry, got %v (err %v)", entries, err)
+	}
+	e := entries[0]
+	if e.EventID != created.ID || e.Reason != "signature:marker" || e.OriginalPath != path || e.QuarantinePath != moved[0] ||
+		e.Size != 14 || e.SHA256 == "" || e.Status != QuarantineStatusActive {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+	if runtime.GOOS != "windows" {
+		if e.Mode.Perm() != 0755 || e.UID != os.Getuid() {
+			t.Errorf("Expected original mode and owner to be recorded, got %v uid %d", e.Mode, e.UID)
+		}
+		if info, err := os.Stat(e.QuarantinePath); err != nil || info.Mode().Perm()&0111 != 0 {
+			t.Errorf("Expected execute permission to be stripped, got %v (err %v)", info.Mode(), err)
+		}
+	}
+
+	// dry-run 복원은 파일을 옮기지 않음
+	if _, err := RestoreQuarantined(db, e.ID, true); err != nil {
+		t.Fatalf("RestoreQuarantined dry-run failed: %v", err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected dry-run restore to leave the file in quarantine")
+	}
+
+	restored, err := RestoreQuarantined(db, e.ID, false)
+	if err != nil {
+		t.Fatalf("RestoreQuarantined failed: %v", err)
+	}
+	if restored.Status != QuarantineStatusRestored {
+		t.Errorf("Expected restored status, got %+v", restored)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected file to be restored: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+		t.Errorf("Expected original mode to be restored, got %v", info.Mode())
+	}
+	if _, err := PurgeQuarantined(db, e.ID, false); err == nil {
+		t.Errorf("Expected purge of a restored entry to fail")
+	}
+	if active, _ := db.GetQuarantineEntries(false); len(active) != 0 {
+		t.Errorf("Expected no active entries after restore, got %v", active)
+	}
+}
+
+func TestQuarantineDryRunAndPurge(t *testing.T) {
+	mon, _, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetQuarantine(QuarantineOptions{Dir: filepath.Join(t.TempDir(), "q"), DryRun: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	path := filepath.Join(dir, "tool.exe")
+	if err := os.WriteFile(path, []byte("payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := mon.Quarantine(path, "manual", 0)
+	if err != nil {
+		t.Fatalf("Quarantine failed: %v", err)
+	}
+	if entry.Status != QuarantineStatusDryRun || entry.QuarantinePath != "" {
+		t.Errorf("Expected dry-run entry, got %+v", entry)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected dry-run to leave the file in place: %v", err)
+	}
+	// 같은 파일은 다시 기록하지 않음
+	if again, err := mon.Quarantine(path, "manual", 0); err != nil || again.ID != entry.ID {
+		t.Errorf("Expected existing dry-run entry, got %+v (err %v)", again, err)
+	}
+
+	// dry-run을 끄면 실제로 격리
+	mon.quarantine.options.DryRun = false
+	qdir := filepath.Join(t.TempDir(), "q")
+	if err := os.MkdirAll(qdir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	mon.quarantine.options.Dir = qdir
+	entry, err = mon.Quarantine(path, "manual", 0)
+	if err != nil || entry.Status != QuarantineStatusActive {
+		t.Fatalf("Expected active entry, got %+v (err %v)", entry, err)
+	}
+	db := mon.db
+	if _, err := PurgeQuarantined(db, entry.ID, true); err != nil {
+		t.Fatalf("PurgeQuarantined dry-run failed: %v", err)
+	}
+	if _, err := os.Stat(entry.QuarantinePath); err != nil {
+		t.Fatalf("Expected dry-run purge to keep the file: %v", err)
+	}
+	if _, err := PurgeQuarantined(db, entry.ID, false); err != nil {
+		t.Fatalf("PurgeQuarantined failed: %v", err)
+	}
+	if _, err := os.Stat(entry.QuarantinePath); !os.IsNotExist(err) {
+		t.Errorf("Expected quarantined file to be deleted, got %v", err)
+	}
+	if got, _ := db.GetQuarantineEntry(entry.ID); got == nil || got.Status != QuarantineStatusPurged || got.ReleasedAt.IsZero() {
+		t.Errorf("Expected purged entry, got %+v", got)
+	}
+	mon.Stop()
+}
+
+func TestQuarantineFailureKeepsEvents(t *testing.T) {
+	mon, _, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetQuarantine(QuarantineOptions{Dir: filepath.Join(t.TempDir(), "q")}); err != nil {
+			t.Fatal(err)
+		}
+		m.SetHashMaxSize(16)
+	})
+	defer mon.Stop()
+
+	// 해시 최대 크기를 넘는 파일은 읽지 않고 격리하지 않음
+	big := filepath.Join(dir, "big.exe")
+	if err := os.WriteFile(big, make([]byte, 32), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mon.Quarantine(big, "manual", 0); err == nil {
+		t.Errorf("Expected quarantine of a file over the hash limit to fail")
+	}
+
+	// 이동에 실패하면 원래 경로의 이벤트를 무시하도록 등록한 것을 지움
+	path := filepath.Join(dir, "tool.exe")
+	if err := os.WriteFile(path, []byte("payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mon.quarantine.options.Dir = filepath.Join(t.TempDir(), "missing")
+	if _, err := mon.Quarantine(path, "manual", 0); err == nil {
+		t.Fatalf("Expected quarantine into a missing directory to fail")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the file to stay in place: %v", err)
+	}
+	if mon.quarantine.suppressed(WatchEvent{Name: path, Op: WatchRemove}, time.Now()) {
+		t.Errorf("Expected events for %s not to be suppressed after a failed move", path)
+	}
+}
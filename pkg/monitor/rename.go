@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// defaultRenameWindow는 Rename 이벤트와 뒤따르는 Create 이벤트를 같은 이동으로 판단하는 기본 시간입니다.
+const defaultRenameWindow = 500 * time.Millisecond
+
+// pendingRename은 짝이 되는 Create 이벤트를 기다리는 Rename 이벤트입니다.
+type pendingRename struct {
+	path    string
+	at      time.Time
+	process *ProcessInfo
+}
+
+// renameTracker는 이전 이름의 Rename 이벤트와 새 이름의 Create 이벤트를 짝지어 줍니다.
+// processEvents 고루틴에서만 사용되므로 잠금이 필요 없습니다.
+type renameTracker struct {
+	window  time.Duration
+	pending []pendingRename
+}
+
+// add는 짝을 기다리는 Rename 이벤트를 추가합니다.
+func (r *renameTracker) add(path string, now time.Time, process *ProcessInfo) {
+	r.pending = append(r.pending, pendingRename{path: path, at: now, process: process})
+}
+
+// match는 newPath의 Create 이벤트와 짝이 되는 Rename 이벤트를 찾아 이전 경로를 반환합니다.
+// 파일 이름이 같은 항목(다른 디렉토리로의 이동)을 우선하고, 없으면 같은 디렉토리에서의
+// 가장 최근 이름 변경을 사용합니다. 둘 다 아니면 관계없는 새 파일로 보고 짝짓지 않습니다.
+func (r *renameTracker) match(newPath string, now time.Time) (string, bool) {
+	best := -1
+	for i := len(r.pending) - 1; i >= 0; i-- {
+		p := r.pending[i]
+		if now.Sub(p.at) > r.window {
+			continue
+		}
+		if filepath.Base(p.path) == filepath.Base(newPath) {
+			best = i
+			break
+		}
+		if best == -1 && filepath.Dir(p.path) == filepath.Dir(newPath) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return "", false
+	}
+
+	oldPath := r.pending[best].path
+	r.pending = append(r.pending[:best], r.pending[best+1:]...)
+	return oldPath, true
+}
+
+// expire는 시간 내에 짝을 찾지 못한 Rename 이벤트를 제거하여 반환합니다.
+func (r *renameTracker) expire(now time.Time) []pendingRename {
+	var expired []pendingRename
+	kept := r.pending[:0]
+	for _, p := range r.pending {
+		if now.Sub(p.at) > r.window {
+			expired = append(expired, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	r.pending = kept
+	return expired
+}
+
+// flush는 짝을 기다리는 모든 Rename 이벤트를 시간과 관계없이 제거하여 반환합니다.
+func (r *renameTracker) flush() []pendingRename {
+	pending := r.pending
+	r.pending = nil
+	return pending
+}
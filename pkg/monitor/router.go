@@ -0,0 +1,269 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// 장치별 감시 방식으로 SetDeviceBackend에 사용할 수 있는 값입니다.
+const (
+	// BackendNotify는 운영체제의 변경 알림(fsnotify 또는 SetBackend로 지정한 백엔드)을 사용합니다. (기본값)
+	BackendNotify = "notify"
+	// BackendPolling은 디렉토리를 주기적으로 스캔하여 변경을 찾습니다.
+	BackendPolling = "poll"
+)
+
+// backendRouter는 장치별로 선택된 감시 백엔드에 Add/Remove를 나누어 전달하고,
+// 모든 백엔드의 이벤트를 하나의 채널로 합칩니다. 어느 한 백엔드라도 닫히면 합쳐진 채널도 닫힙니다.
+//
+// 알림 백엔드가 운영체제의 감시 한도(Linux의 inotify max_user_watches)에 걸려 등록을 거부하면
+// 그 디렉토리를 폴링 백엔드에 대신 등록하고, 이후 그 아래의 디렉토리도 폴링으로 감시합니다.
+type backendRouter struct {
+	backends   []WatcherBackend
+	primary    WatcherBackend
+	poller     WatcherBackend
+	usePolling func(path string) bool
+
+	mu           sync.Mutex
+	owners       map[string]WatcherBackend // 감시 경로별로 등록된 백엔드
+	fallback     map[string]bool           // 감시 한도 때문에 폴링으로 전환된 하위 트리의 루트
+	limitReached bool
+
+	events    chan WatchEvent
+	errors    chan error
+	quit      chan struct{}
+	quitOnce  sync.Once
+	forwarder sync.WaitGroup
+}
+
+func newBackendRouter(primary, poller WatcherBackend, usePolling func(path string) bool) *backendRouter {
+	r := &backendRouter{
+		backends:   []WatcherBackend{primary, poller},
+		primary:    primary,
+		poller:     poller,
+		usePolling: usePolling,
+		owners:     make(map[string]WatcherBackend),
+		fallback:   make(map[string]bool),
+		events:     make(chan WatchEvent),
+		errors:     make(chan error),
+		quit:       make(chan struct{}),
+	}
+	for _, b := range r.backends {
+		r.forwarder.Add(1)
+		go r.forward(b)
+	}
+	go func() {
+		r.forwarder.Wait()
+		close(r.events)
+		close(r.errors)
+	}()
+	return r
+}
+
+// forward는 백엔드 하나의 이벤트와 오류를 합쳐진 채널로 전달합니다.
+func (r *backendRouter) forward(b WatcherBackend) {
+	defer r.forwarder.Done()
+	// 백엔드 하나가 닫히면 나머지 전달도 중단하여 합쳐진 채널이 닫히게 함
+	defer r.stop()
+
+	// 재귀 백엔드는 폴링 장치의 하위 트리 이벤트도 보고하므로 중복되지 않게 버림
+	skipPolled := b == r.primary && isRecursiveBackend(b)
+
+	events, errors := b.Events(), b.Errors()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if skipPolled && r.usePolling(event.Name) {
+				continue
+			}
+			select {
+			case r.events <- event:
+			case <-r.quit:
+				return
+			}
+
+		case err, ok := <-errors:
+			if !ok {
+				return
+			}
+			select {
+			case r.errors <- err:
+			case <-r.quit:
+				return
+			}
+
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+func (r *backendRouter) stop() {
+	r.quitOnce.Do(func() { close(r.quit) })
+}
+
+// Add는 경로가 속한 장치의 감시 방식에 맞는 백엔드에 디렉토리를 등록합니다.
+// 알림 백엔드가 감시 한도 초과로 실패하면 폴링 백엔드에 대신 등록합니다.
+func (r *backendRouter) Add(path string) error {
+	backend := r.primary
+	if r.usePolling(path) || r.inFallback(path) {
+		backend = r.poller
+	}
+	err := backend.Add(path)
+	if err != nil && backend == r.primary && isWatchLimitError(err) {
+		if pollErr := r.poller.Add(path); pollErr != nil {
+			return fmt.Errorf("%w (폴링 전환 실패: %v)", err, pollErr)
+		}
+		r.mu.Lock()
+		first := !r.limitReached
+		r.limitReached = true
+		r.fallback[path] = true
+		r.owners[path] = r.poller
+		r.mu.Unlock()
+		if first {
+			logWatchLimitReached(path)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.owners[path] = backend
+	r.mu.Unlock()
+	return nil
+}
+
+// recursive는 경로가 하위 디렉토리까지 감시하는 백엔드에 등록되는지 확인합니다.
+// 그렇다면 하위 디렉토리를 따로 등록할 필요가 없습니다.
+func (r *backendRouter) recursive(path string) bool {
+	return isRecursiveBackend(r.primary) && !r.usePolling(path) && !r.inFallback(path)
+}
+
+// inFallback은 경로가 폴링으로 전환된 하위 트리에 속하는지 확인합니다.
+func (r *backendRouter) inFallback(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inFallbackLocked(path)
+}
+
+func (r *backendRouter) inFallbackLocked(path string) bool {
+	for root := range r.fallback {
+		if isUnderPath(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// counts는 백엔드별 감시 디렉토리 수와 감시 한도 때문에 폴링으로 전환된 디렉토리 수를 반환합니다.
+func (r *backendRouter) counts() (notify, polling, fallback int, limitReached bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for path, backend := range r.owners {
+		if backend == r.primary {
+			notify++
+			continue
+		}
+		polling++
+		if r.inFallbackLocked(path) {
+			fallback++
+		}
+	}
+	return notify, polling, fallback, r.limitReached
+}
+
+// isWatchLimitError는 감시 등록이 운영체제의 감시 한도 때문에 실패했는지 확인합니다.
+func isWatchLimitError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
+
+// logWatchLimitReached는 감시 한도에 처음 도달했을 때 경고를 남깁니다.
+func logWatchLimitReached(path string) {
+	if limit := watchLimit(); limit > 0 {
+		log.Printf("[경고] 파일 감시 한도(max_user_watches=%d)에 도달했습니다. %s 아래의 새 감시는 폴링으로 대체합니다. "+
+			"sysctl fs.inotify.max_user_watches로 한도를 늘릴 수 있습니다", limit, path)
+		return
+	}
+	log.Printf("[경고] 파일 감시 한도에 도달했습니다. %s 아래의 새 감시는 폴링으로 대체합니다", path)
+}
+
+// Remove는 디렉토리를 등록한 백엔드에서 감시를 해제합니다.
+func (r *backendRouter) Remove(path string) error {
+	r.mu.Lock()
+	backend, ok := r.owners[path]
+	delete(r.owners, path)
+	delete(r.fallback, path)
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("감시 중이 아닌 디렉토리입니다: %s", path)
+	}
+	return backend.Remove(path)
+}
+
+func (r *backendRouter) Events() <-chan WatchEvent { return r.events }
+func (r *backendRouter) Errors() <-chan error      { return r.errors }
+
+// Close는 모든 백엔드를 닫습니다.
+func (r *backendRouter) Close() error {
+	r.stop()
+	var firstErr error
+	for _, b := range r.backends {
+		if err := b.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SetDeviceBackend는 장치의 감시 방식(BackendNotify 또는 BackendPolling)을 설정합니다.
+// 변경 알림이 전달되지 않는 네트워크 공유, FUSE 마운트, 컨테이너의 바인드 마운트에는 BackendPolling을 사용하세요.
+// 장치가 겹치면 가장 깊은 장치의 설정이 적용됩니다. 이미 등록된 감시에는 적용되지 않으므로
+// Start 전이나 AddDevice 전에 호출해야 합니다.
+func (m *Monitor) SetDeviceBackend(device, backend string) error {
+	switch backend {
+	case BackendNotify, BackendPolling:
+	default:
+		return fmt.Errorf("알 수 없는 감시 방식: %s (가능한 값: %s, %s)", backend, BackendNotify, BackendPolling)
+	}
+	m.devicesMutex.Lock()
+	defer m.devicesMutex.Unlock()
+	m.deviceBackends[filepath.Clean(device)] = backend
+	return nil
+}
+
+// GetDeviceBackend는 장치에 설정된 감시 방식을 반환합니다.
+func (m *Monitor) GetDeviceBackend(device string) string {
+	m.devicesMutex.RLock()
+	defer m.devicesMutex.RUnlock()
+	if backend, ok := m.deviceBackends[filepath.Clean(device)]; ok {
+		return backend
+	}
+	return BackendNotify
+}
+
+// SetPollingOptions는 BackendPolling 장치의 스캔 주기와 속도 제한을 설정합니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetPollingOptions(opts PollingOptions) {
+	m.pollingOptions = opts
+}
+
+// usesPolling은 경로를 폴링으로 감시해야 하는지 확인합니다.
+// 경로를 포함하는 장치 중 가장 깊은 장치의 감시 방식을 따릅니다.
+func (m *Monitor) usesPolling(path string) bool {
+	m.devicesMutex.RLock()
+	defer m.devicesMutex.RUnlock()
+	root, backend := "", BackendNotify
+	for device, b := range m.deviceBackends {
+		if len(device) > len(root) && isUnderPath(path, device) {
+			root, backend = device, b
+		}
+	}
+	return backend == BackendPolling
+}
@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/signature"
+)
+
+const (
+	// defaultScanMaxSize는 시그니처 검사 시 파일 앞부분에서 읽는 최대 크기입니다.
+	defaultScanMaxSize = 32 << 20
+	defaultScanWorkers = 1
+	// defaultScanQueue는 검사 대기열 크기로, 가득 차면 이벤트는 검사 없이 기록됩니다.
+	defaultScanQueue = 256
+)
+
+// SignatureMatch는 이벤트 대상 파일에서 일치한 바이트 시그니처입니다.
+// EventID는 검사를 요청한 FileEvent의 ID입니다.
+type SignatureMatch struct {
+	EventID   int64
+	Path      string
+	ScannedAt time.Time
+	signature.Match
+}
+
+// scanJob은 시그니처를 검사할 이벤트의 ID와 파일 경로입니다.
+type scanJob struct {
+	eventID int64
+	path    string
+}
+
+// scanPool은 이벤트 대상 파일을 시그니처로 검사하는 작업자 풀입니다.
+// 일치한 시그니처는 result로 전달됩니다.
+type scanPool struct {
+	fileWorkers[scanJob]
+	set     *signature.Set
+	maxSize int64
+	result  func([]SignatureMatch)
+}
+
+func newScanPool() *scanPool {
+	return &scanPool{
+		fileWorkers: newFileWorkers[scanJob](defaultScanWorkers, defaultScanQueue),
+		maxSize:     defaultScanMaxSize,
+	}
+}
+
+// enabled는 검사할 시그니처가 설정되어 있는지 확인합니다.
+func (p *scanPool) enabled() bool {
+	return p.set.Len() > 0
+}
+
+// wants는 이벤트 대상 파일을 검사해야 하는지 확인합니다. 새로 생기거나(CREATE) 내용이 바뀐(MODIFIED) 파일이 대상이며,
+// 이벤트에는 유형이 아직 없을 수 있으므로 시그니처 유형은 작업자가 파일이 안정된 뒤에 확인합니다.
+func (p *scanPool) wants(event FileEvent) bool {
+	switch event.Operation {
+	case OperationCreate, OperationModified:
+		return true
+	}
+	return false
+}
+
+// start는 작업자 고루틴을 시작합니다.
+func (p *scanPool) start(result func([]SignatureMatch)) {
+	p.result = result
+	p.fileWorkers.start(p.run)
+}
+
+func (p *scanPool) run(job scanJob) {
+	matches, err := p.scanFile(job.path)
+	if err != nil {
+		log.Printf("시그니처 검사 실패: %s - %v", job.path, err)
+		return
+	}
+	if len(matches) == 0 {
+		return
+	}
+	now := time.Now()
+	results := make([]SignatureMatch, len(matches))
+	for i, match := range matches {
+		results[i] = SignatureMatch{EventID: job.eventID, Path: job.path, ScannedAt: now, Match: match}
+	}
+	p.result(results)
+}
+
+// scanFile은 파일이 안정된 뒤에 시그니처로 검사합니다.
+func (p *scanPool) scanFile(path string) ([]signature.Match, error) {
+	var matches []signature.Match
+	err := p.attempt(func(final bool) (retry bool, err error) {
+		matches, retry, err = p.tryScan(path, final)
+		return retry, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// tryScan은 검사를 한 번 시도합니다. retry는 다시 시도할 가치가 있는 오류인지를 나타냅니다.
+// 파일이 안정된 뒤의 내용으로 유형을 다시 판별하여, 적용되는 시그니처가 없으면 검사하지 않습니다.
+func (p *scanPool) tryScan(path string, final bool) (matches []signature.Match, retry bool, err error) {
+	before, retry, err := p.stableStat(path, final)
+	if err != nil {
+		return nil, retry, err
+	}
+	types := []string{strings.ToLower(filepath.Ext(path))}
+	if detected, err := SniffFile(path); err == nil && detected != "" {
+		types = append(types, detected)
+	}
+	if !p.set.Applies(types...) {
+		return nil, false, nil
+	}
+
+	matches, err = p.set.ScanFile(path, p.maxSize, types...)
+	if err != nil {
+		return nil, !os.IsNotExist(err), err
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
+		return nil, !os.IsNotExist(err), err
+	}
+	if changedSince(before, after) {
+		return nil, true, fmt.Errorf("검사 중 파일이 변경되었습니다")
+	}
+	return matches, false, nil
+}
+
+// recordSignatureMatches는 일치한 시그니처를 다음 저장 시점까지 메모리에 보관하고,
+// quarantine: true인 시그니처와 일치한 파일은 격리합니다.
+func (m *Monitor) recordSignatureMatches(matches []SignatureMatch) {
+	for _, match := range matches {
+		log.Printf("[탐지] 시그니처 일치: %s - %s", match.Path, match.Match.String())
+	}
+	m.eventsMutex.Lock()
+	for i := range matches {
+		matches[i].EventID = m.remapEventID(matches[i].EventID)
+	}
+	m.sigMatches = append(m.sigMatches, matches...)
+	m.eventsMutex.Unlock()
+	m.quarantineMatches(matches)
+}
+
+// SetSignatures는 새 파일과 수정된 파일의 내용을 검사할 바이트 시그니처를 설정합니다.
+// 일치한 시그니처는 이벤트 ID로 연결되어 signature_matches 테이블에 저장됩니다.
+// nil이거나 빈 목록이면 검사하지 않습니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetSignatures(set *signature.Set) {
+	m.scanner.set = set
+	log.Printf("시그니처 설정됨: %d개", set.Len())
+}
+
+// SetSignatureMaxSize는 시그니처 검사 시 파일 앞부분에서 읽을 최대 크기(바이트)를 설정합니다.
+// 이보다 큰 파일은 앞부분만 검사합니다. Start 전에 호출해야 합니다.
+func (m *Monitor) SetSignatureMaxSize(size int64) {
+	if size <= 0 {
+		size = defaultScanMaxSize
+	}
+	m.scanner.maxSize = size
+}
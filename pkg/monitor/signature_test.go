@@ -0,0 +1,127 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/signature"
+)
+
+func TestFakeBackendSignatureScan(t *testing.T) {
+	set, err := signature.Parse([]byte(`
+signatures:
+  - id: test-marker
+    description: 테스트용 표식
+    types: [.exe]
+    strings:
+      $marker: EVIL-MARKER
+      $mz: {hex: "4D 5A"}
+    condition: $mz at 0 and $marker
+`))
+	if err != nil {
+		t.Fatalf("signature.Parse failed: %v", err)
+	}
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.scanner.retryDelay = 20 * time.Millisecond
+		m.SetSignatures(set)
+	})
+	dbPath := mon.dbPath
+
+	files := map[string]string{
+		"drop.exe":  "MZ\x90\x00 EVIL-MARKER",
+		"clean.exe": "MZ\x90\x00 nothing here",
+		"drop.dll":  "MZ\x90\x00 EVIL-MARKER", // 시그니처 유형(.exe)과 다름
+	}
+	for _, name := range []string{"drop.exe", "clean.exe", "drop.dll"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	}
+	events := waitEvents(t, mon, 3)
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, event := range events {
+		matches, err := db.GetSignatureMatches(event.ID)
+		if err != nil {
+			t.Fatalf("GetSignatureMatches failed: %v", err)
+		}
+		if filepath.Base(event.Path) != "drop.exe" {
+			if len(matches) != 0 {
+				t.Errorf("Expected no matches for %s, got %+v", event.Path, matches)
+			}
+			continue
+		}
+		if len(matches) != 1 {
+			t.Fatalf("Expected 1 match for %s, got %+v", event.Path, matches)
+		}
+		m := matches[0]
+		if m.EventID != event.ID || m.Path != event.Path || m.Signature != "test-marker" || m.Description != "테스트용 표식" || m.ScannedAt.IsZero() {
+			t.Errorf("Unexpected match: %+v", m)
+		}
+		want := []signature.StringMatch{{ID: "$marker", Offset: 5, Count: 1}, {ID: "$mz", Offset: 0, Count: 1}}
+		if len(m.Strings) != 2 || m.Strings[0] != want[0] || m.Strings[1] != want[1] {
+			t.Errorf("Expected strings %+v, got %+v", want, m.Strings)
+		}
+	}
+}
+
+func TestSignatureScanResniffsType(t *testing.T) {
+	set, err := signature.Parse([]byte("signatures:\n  - id: pe-marker\n    types: [pe]\n    strings: {$a: EVIL-MARKER}\n"))
+	if err != nil {
+		t.Fatalf("signature.Parse failed: %v", err)
+	}
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.scanner.retryDelay = 200 * time.Millisecond
+		if err := m.SetFileFilters(nil); err != nil {
+			t.Fatal(err)
+		}
+		m.SetSignatures(set)
+	})
+	dbPath := mon.dbPath
+
+	// 빈 파일로 생성되어 CREATE 시점에는 유형을 알 수 없고, 확장자로도 PE인지 알 수 없는 파일
+	path := filepath.Join(dir, "payload.bin")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	created := waitEvents(t, mon, 1)[0]
+	if err := os.WriteFile(path, []byte("MZ\x90\x00 EVIL-MARKER"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mon.eventsMutex.Lock()
+		found := len(mon.sigMatches)
+		mon.eventsMutex.Unlock()
+		if found > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the PE written after CREATE to be scanned")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+	matches, err := db.GetSignatureMatches(created.ID)
+	if err != nil || len(matches) != 1 || matches[0].Signature != "pe-marker" {
+		t.Errorf("Expected pe-marker match for CREATE event, got %+v (err %v)", matches, err)
+	}
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// 매직 바이트로 감지되는 콘텐츠 유형입니다. FileEvent.DetectedType에 기록됩니다.
+const (
+	ContentTypePE     = "pe"
+	ContentTypeELF    = "elf"
+	ContentTypeMachO  = "macho"
+	ContentTypeScript = "script"
+	ContentTypeZIP    = "zip"
+	ContentTypeOOXML  = "ooxml"
+	ContentTypeOLE    = "ole"
+	ContentTypePDF    = "pdf"
+	ContentTypeGzip   = "gzip"
+	ContentTypeRAR    = "rar"
+	ContentType7z     = "7z"
+	ContentTypeCAB    = "cab"
+	ContentTypeLNK    = "lnk"
+	ContentTypeClass  = "java-class"
+)
+
+// sniffLen은 콘텐츠 유형 감지를 위해 읽는 파일 앞부분의 최대 크기입니다.
+const sniffLen = 4096
+
+var (
+	magicELF    = []byte{0x7F, 'E', 'L', 'F'}
+	magicOLE    = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	magicZIP    = []byte{'P', 'K', 0x03, 0x04}
+	magicPDF    = []byte("%PDF-")
+	magicGzip   = []byte{0x1F, 0x8B}
+	magicRAR    = []byte("Rar!\x1A\x07")
+	magic7z     = []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}
+	magicCAB    = []byte("MSCF")
+	magicLNK    = []byte{0x4C, 0x00, 0x00, 0x00, 0x01, 0x14, 0x02, 0x00}
+	magicMachO  = [][]byte{{0xFE, 0xED, 0xFA, 0xCE}, {0xFE, 0xED, 0xFA, 0xCF}, {0xCE, 0xFA, 0xED, 0xFE}, {0xCF, 0xFA, 0xED, 0xFE}}
+	magicCafe   = []byte{0xCA, 0xFE, 0xBA, 0xBE}
+	ooxmlMarker = []byte("[Content_Types].xml")
+)
+
+// DetectContentType은 파일 앞부분의 매직 바이트로 콘텐츠 유형을 판별합니다.
+// 알 수 없는 형식이면 빈 문자열을 반환합니다.
+func DetectContentType(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("MZ")):
+		// PE 서명("PE\0\0")이 확인되지 않아도 MZ 헤더를 가진 실행 파일로 간주
+		return ContentTypePE
+	case bytes.HasPrefix(header, magicELF):
+		return ContentTypeELF
+	case hasAnyPrefix(header, magicMachO):
+		return ContentTypeMachO
+	case bytes.HasPrefix(header, magicCafe):
+		// CAFEBABE는 Mach-O 유니버설 바이너리와 Java 클래스 파일이 공유하며,
+		// 뒤따르는 값이 작으면 아키텍처 수(유니버설), 크면 클래스 파일 버전입니다.
+		if len(header) >= 8 && binary.BigEndian.Uint32(header[4:8]) < 45 {
+			return ContentTypeMachO
+		}
+		return ContentTypeClass
+	case bytes.HasPrefix(header, []byte("#!")):
+		return ContentTypeScript
+	case bytes.HasPrefix(header, magicZIP):
+		if bytes.Contains(header, ooxmlMarker) {
+			return ContentTypeOOXML
+		}
+		return ContentTypeZIP
+	case bytes.HasPrefix(header, magicOLE):
+		return ContentTypeOLE
+	case bytes.HasPrefix(header, magicPDF):
+		return ContentTypePDF
+	case bytes.HasPrefix(header, magicGzip):
+		return ContentTypeGzip
+	case bytes.HasPrefix(header, magicRAR):
+		return ContentTypeRAR
+	case bytes.HasPrefix(header, magic7z):
+		return ContentType7z
+	case bytes.HasPrefix(header, magicCAB):
+		return ContentTypeCAB
+	case bytes.HasPrefix(header, magicLNK):
+		return ContentTypeLNK
+	}
+	return ""
+}
+
+// SniffFile은 파일 앞부분을 읽어 콘텐츠 유형을 판별합니다.
+func SniffFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return DetectContentType(header[:n]), nil
+}
+
+func hasAnyPrefix(b []byte, prefixes [][]byte) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(b, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"pe", []byte("MZ\x90\x00\x03\x00\x00\x00"), ContentTypePE},
+		{"elf", []byte("\x7fELF\x02\x01\x01"), ContentTypeELF},
+		{"macho64", []byte{0xCF, 0xFA, 0xED, 0xFE, 0x07, 0x00, 0x00, 0x01}, ContentTypeMachO},
+		{"macho-universal", []byte{0xCA, 0xFE, 0xBA, 0xBE, 0x00, 0x00, 0x00, 0x02}, ContentTypeMachO},
+		{"java-class", []byte{0xCA, 0xFE, 0xBA, 0xBE, 0x00, 0x00, 0x00, 0x34}, ContentTypeClass},
+		{"script", []byte("#!/bin/sh\necho hi\n"), ContentTypeScript},
+		{"zip", []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00data.txt"), ContentTypeZIP},
+		{"ooxml", []byte("PK\x03\x04\x14\x00\x06\x00\x08\x00[Content_Types].xml"), ContentTypeOOXML},
+		{"ole", []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1, 0x00}, ContentTypeOLE},
+		{"pdf", []byte("%PDF-1.7\n"), ContentTypePDF},
+		{"gzip", []byte{0x1F, 0x8B, 0x08, 0x00}, ContentTypeGzip},
+		{"rar", []byte("Rar!\x1A\x07\x01\x00"), ContentTypeRAR},
+		{"7z", []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C, 0x00, 0x04}, ContentType7z},
+		{"cab", []byte("MSCF\x00\x00\x00\x00"), ContentTypeCAB},
+		{"lnk", []byte{0x4C, 0x00, 0x00, 0x00, 0x01, 0x14, 0x02, 0x00, 0x00}, ContentTypeLNK},
+		{"text", []byte("hello world"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := DetectContentType(tt.header); got != tt.want {
+			t.Errorf("%s: DetectContentType = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFakeBackendTypeFilter(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetTypeFilters([]string{"PE"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	defer mon.Stop()
+
+	// 확장자로는 감지되지 않는 실행 파일
+	disguised := filepath.Join(dir, "report.dat")
+	if err := os.WriteFile(disguised, []byte("MZ\x90\x00 disguised executable"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "notes.dat")
+	if err := os.WriteFile(plain, []byte("just text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.Push(WatchEvent{Name: plain, Op: WatchCreate})
+	fake.Push(WatchEvent{Name: disguised, Op: WatchCreate})
+
+	// 유형으로 판별해야 하는 CREATE는 파일이 안정된 뒤에 기록되므로 REMOVE는 그 뒤에 보냄
+	events := waitEvents(t, mon, 1)
+	if events[0].Path != disguised || events[0].DetectedType != ContentTypePE || events[0].FileType != ".dat" {
+		t.Errorf("Expected disguised PE event, got %+v", events[0])
+	}
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "tool.exe"), Op: WatchRemove})
+	events = waitEvents(t, mon, 1)
+	if events[0].Operation != "REMOVE" {
+		t.Errorf("Expected extension filter to still apply, got %+v", events[0])
+	}
+
+	select {
+	case event := <-mon.EventChan():
+		t.Errorf("Unexpected event: %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestFakeBackendTypeFilterWaitsForContent(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		if err := m.SetTypeFilters([]string{"pe"}); err != nil {
+			t.Fatal(err)
+		}
+		m.SetWriteSettle(100 * time.Millisecond)
+	})
+	defer mon.Stop()
+
+	// 기본 작업(WRITE 제외)에서도 빈 파일로 생성된 뒤 내용이 쓰인 PE를 감지해야 함
+	path := filepath.Join(dir, "report.dat")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Now()
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("MZ\x90\x00 payload written after create"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchWrite})
+
+	events := waitEvents(t, mon, 1)
+	event := events[0]
+	if event.Operation != OperationCreate || event.Path != path || event.DetectedType != ContentTypePE {
+		t.Fatalf("Expected CREATE with detected PE type, got %+v", event)
+	}
+	if event.Timestamp.Before(created) || event.Timestamp.After(created.Add(50*time.Millisecond)) {
+		t.Errorf("Expected CREATE timestamp to be the creation time, got %v (created %v)", event.Timestamp, created)
+	}
+
+	// 끝내 필터와 일치하지 않는 내용이면 기록하지 않음
+	text := filepath.Join(dir, "notes.dat")
+	if err := os.WriteFile(text, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: text, Op: WatchCreate})
+	if err := os.WriteFile(text, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: text, Op: WatchWrite})
+	select {
+	case event := <-mon.EventChan():
+		t.Errorf("Unexpected event: %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestFakeBackendDetectedTypeAfterSettle(t *testing.T) {
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		m.analyzer.retryDelay = 100 * time.Millisecond
+	})
+	dbPath := mon.dbPath
+
+	// 확장자 필터만 있는 기본 설정에서 빈 파일로 생성된 뒤 내용이 쓰이는 실행 파일
+	path := filepath.Join(dir, "setup.exe")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.Push(WatchEvent{Name: path, Op: WatchCreate})
+
+	// CREATE는 기다리지 않고 유형 없이 기록됨
+	event := waitEvents(t, mon, 1)[0]
+	if event.Operation != OperationCreate || event.DetectedType != "" {
+		t.Fatalf("Expected CREATE without detected type, got %+v", event)
+	}
+	if err := os.WriteFile(path, []byte("MZ\x90\x00 payload written after create"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 유형은 파일이 안정된 뒤에 작업자가 판별
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if detected, ok := mon.WaitDetectedType(ctx, event.ID); !ok || detected != ContentTypePE {
+		t.Errorf("Expected detected type %q, got %q (%v)", ContentTypePE, detected, ok)
+	}
+	mon.Stop()
+
+	db, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer db.Close()
+	stored, err := db.GetFileEvents()
+	if err != nil {
+		t.Fatalf("GetFileEvents failed: %v", err)
+	}
+	if len(stored) != 1 || stored[0].DetectedType != ContentTypePE {
+		t.Errorf("Expected stored event with detected type %q, got %+v", ContentTypePE, stored)
+	}
+}
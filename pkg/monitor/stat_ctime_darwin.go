@@ -0,0 +1,13 @@
+//go:build darwin
+
+package monitor
+
+import (
+	"syscall"
+	"time"
+)
+
+// statChangeTime은 파일 메타데이터가 마지막으로 바뀐 시간(ctime)을 반환합니다.
+func statChangeTime(sys *syscall.Stat_t) time.Time {
+	return time.Unix(sys.Ctimespec.Unix())
+}
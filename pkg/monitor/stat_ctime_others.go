@@ -0,0 +1,13 @@
+//go:build unix && !linux && !darwin
+
+package monitor
+
+import (
+	"syscall"
+	"time"
+)
+
+// statChangeTime은 ctime 필드 이름이 플랫폼마다 달라 지원하지 않는 플랫폼에서 0을 반환합니다.
+func statChangeTime(sys *syscall.Stat_t) time.Time {
+	return time.Time{}
+}
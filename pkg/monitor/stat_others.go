@@ -0,0 +1,19 @@
+//go:build !unix && !windows
+
+package monitor
+
+import "os"
+
+// fileInode는 inode를 제공하지 않는 플랫폼에서 항상 0을 반환합니다.
+// 이 경우 폴링 백엔드는 크기와 수정 시간만으로 변경을 판단합니다.
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
+
+// executableExt는 확장자로 실행 여부가 정해지지 않는 플랫폼에서 항상 false를 반환합니다.
+func executableExt(path string) bool {
+	return false
+}
+
+// fillPlatformStat은 추가 정보를 제공하지 않는 플랫폼에서 아무것도 하지 않습니다.
+func fillPlatformStat(path string, info os.FileInfo, st *FileStat) {}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package monitor
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode는 파일의 inode 번호를 반환합니다.
+func fileInode(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino)
+	}
+	return 0
+}
+
+// executableExt는 확장자로 실행 여부가 정해지지 않는 플랫폼에서 항상 false를 반환합니다.
+func executableExt(path string) bool {
+	return false
+}
+
+// fillPlatformStat은 소유자, inode, 링크 수, ctime을 채웁니다.
+func fillPlatformStat(path string, info os.FileInfo, st *FileStat) {
+	sys, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	st.UID = int(sys.Uid)
+	st.GID = int(sys.Gid)
+	st.Inode = uint64(sys.Ino)
+	st.Links = uint64(sys.Nlink)
+	st.ChangeTime = statChangeTime(sys)
+}
@@ -0,0 +1,103 @@
+//go:build windows
+
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// windowsExecutableExts는 확장자만으로 실행되는 파일 형식입니다 (PATHEXT 기본값).
+var windowsExecutableExts = map[string]bool{
+	".com": true, ".exe": true, ".bat": true, ".cmd": true, ".vbs": true, ".vbe": true,
+	".js": true, ".jse": true, ".wsf": true, ".wsh": true, ".msc": true,
+}
+
+// fileInode는 Windows에서 항상 0을 반환합니다. 파일 인덱스를 얻으려면 파일을 열어야 하므로
+// 폴링 백엔드는 크기와 수정 시간만으로 변경을 판단합니다.
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
+
+// executableExt는 확장자만으로 실행되는 파일인지 확인합니다.
+// Windows에는 실행 권한 비트가 없으므로 FileStat.Executable은 이 확장자로 판단합니다.
+func executableExt(path string) bool {
+	return windowsExecutableExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// fillPlatformStat은 파일을 열어 파일 인덱스, 링크 수, 소유자와 그룹(SID의 계정 이름)을 채웁니다.
+// Windows에는 UID/GID가 없으므로 -1로 둡니다.
+func fillPlatformStat(path string, info os.FileInfo, st *FileStat) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return
+	}
+	// 속성과 보안 설명자만 읽으므로 다른 프로세스가 쓰는 중이거나 삭제 대기 중인 파일도 열 수 있음
+	h, err := syscall.CreateFile(name, windows.READ_CONTROL,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS|syscall.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		return
+	}
+	defer syscall.CloseHandle(h)
+
+	var data syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(h, &data); err == nil {
+		st.Inode = uint64(data.FileIndexHigh)<<32 | uint64(data.FileIndexLow)
+		st.Links = uint64(data.NumberOfLinks)
+	}
+	st.Owner, st.Group = fileOwner(windows.Handle(h))
+}
+
+// fileOwner는 보안 설명자의 소유자와 그룹 SID를 "도메인\계정" 형식의 이름으로 반환합니다.
+func fileOwner(h windows.Handle) (owner, group string) {
+	sd, err := windows.GetSecurityInfo(h, windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.GROUP_SECURITY_INFORMATION)
+	if err != nil {
+		return "", ""
+	}
+	if sid, _, err := sd.Owner(); err == nil && sid != nil {
+		owner = accountNames.lookup(sid)
+	}
+	if sid, _, err := sd.Group(); err == nil && sid != nil {
+		group = accountNames.lookup(sid)
+	}
+	return owner, group
+}
+
+// sidCache는 SID를 계정 이름으로 바꾼 결과를 기억합니다.
+// 계정을 찾을 수 없는 SID(삭제된 계정 등)는 SID 문자열(S-1-5-...)로 기억하여 이벤트마다 다시 조회하지 않습니다.
+type sidCache struct {
+	mu    sync.Mutex
+	names map[string]string
+}
+
+var accountNames = &sidCache{names: make(map[string]string)}
+
+func (c *sidCache) lookup(sid *windows.SID) string {
+	key := sid.String()
+	c.mu.Lock()
+	name, ok := c.names[key]
+	c.mu.Unlock()
+	if ok {
+		return name
+	}
+
+	// 도메인 계정 조회는 느릴 수 있으므로 잠금 없이 조회 (같은 SID를 동시에 조회하면 결과가 같으므로 나중 것이 덮어씀)
+	name = key
+	if account, domain, _, err := sid.LookupAccount(""); err == nil {
+		name = account
+		if domain != "" {
+			name = domain + `\` + account
+		}
+	}
+	c.mu.Lock()
+	c.names[key] = name
+	c.mu.Unlock()
+	return name
+}
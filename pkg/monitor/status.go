@@ -0,0 +1,41 @@
+package monitor
+
+// Status는 모니터의 감시 상태 요약입니다.
+type Status struct {
+	Running bool
+	Devices int
+
+	NotifyDirs  int // 변경 알림으로 감시 중인 디렉토리
+	PollingDirs int // 폴링으로 감시 중인 디렉토리 (FallbackDirs 포함)
+
+	// FallbackDirs는 운영체제의 감시 한도를 넘어 변경 알림을 받지 못하고 폴링으로 대신 감시 중인 디렉토리 수입니다.
+	FallbackDirs int
+	// UncoveredDirs는 감시 등록에 실패하여 어떤 방식으로도 감시되지 않는 디렉토리 수입니다.
+	UncoveredDirs int
+
+	WatchLimitReached bool // 감시 한도(Linux의 inotify max_user_watches)에 도달했는지 여부
+	WatchLimit        int  // 감시 한도 (Linux에서만 제공, 알 수 없으면 0)
+}
+
+// Status는 현재 감시 상태를 반환합니다. 모니터링 중이 아니면 디렉토리 수는 모두 0입니다.
+// 감시 한도에 도달하면 이후 등록되는 디렉토리는 그 하위 트리 전체가 폴링으로 감시되며,
+// 그 수가 FallbackDirs에 반영됩니다.
+func (m *Monitor) Status() Status {
+	m.runMutex.Lock()
+	defer m.runMutex.Unlock()
+
+	status := Status{
+		Running:    m.running,
+		Devices:    len(m.GetDevices()),
+		WatchLimit: watchLimit(),
+	}
+	if !m.running || m.watcher == nil {
+		return status
+	}
+	status.NotifyDirs, status.PollingDirs, status.FallbackDirs, status.WatchLimitReached = m.watcher.counts()
+
+	m.watchMutex.Lock()
+	status.UncoveredDirs = len(m.uncovered)
+	m.watchMutex.Unlock()
+	return status
+}
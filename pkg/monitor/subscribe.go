@@ -0,0 +1,228 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy는 구독자의 버퍼가 가득 찼을 때 새 이벤트를 처리하는 방식입니다.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest는 버퍼가 가득 차면 새 이벤트를 버립니다. (기본값)
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest는 버퍼에서 가장 오래된 이벤트를 버리고 새 이벤트를 넣습니다.
+	OverflowDropOldest
+	// OverflowBlock은 버퍼에 자리가 날 때까지 BlockTimeout 동안 기다린 뒤, 그래도 가득 차 있으면 새 이벤트를 버립니다.
+	// 기다리는 동안 이벤트 처리가 멈추므로 다른 구독자에게도 전달이 지연됩니다.
+	OverflowBlock
+)
+
+const (
+	defaultSubscriptionBuffer = 100
+	defaultBlockTimeout       = time.Second
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowBlock:
+		return "block"
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+}
+
+// SubscribeOptions는 구독 설정입니다.
+// Buffer가 0이면 100, OverflowBlock의 BlockTimeout이 0이면 1초가 사용됩니다.
+// Filter가 nil이 아니면 true를 반환한 이벤트만 전달됩니다.
+type SubscribeOptions struct {
+	Buffer       int
+	Filter       func(FileEvent) bool
+	Overflow     OverflowPolicy
+	BlockTimeout time.Duration
+}
+
+// SubscriptionStats는 구독자별 전달 및 유실 횟수입니다.
+type SubscriptionStats struct {
+	Delivered     uint64
+	DroppedNewest uint64 // 버퍼가 가득 차 버려진 새 이벤트 (OverflowBlock의 대기 시간 초과 포함)
+	DroppedOldest uint64 // 새 이벤트를 위해 버퍼에서 밀려난 이벤트
+}
+
+// Subscription은 Subscribe로 생성된 독립적인 이벤트 스트림입니다.
+// 각 구독은 자신만의 버퍼를 가지므로 다른 구독자와 이벤트를 나눠 갖지 않습니다.
+type Subscription struct {
+	mon  *Monitor
+	opts SubscribeOptions
+	ch   chan FileEvent
+
+	// mu는 전달 중에 채널이 닫히지 않도록 보호합니다.
+	mu     sync.Mutex
+	closed bool
+
+	delivered     atomic.Uint64
+	droppedNewest atomic.Uint64
+	droppedOldest atomic.Uint64
+}
+
+// Subscribe는 새 이벤트 스트림을 생성합니다.
+// 모니터가 중지되면 모든 구독의 채널이 닫힙니다.
+func (m *Monitor) Subscribe(opts SubscribeOptions) (*Subscription, error) {
+	if opts.Buffer < 0 {
+		return nil, fmt.Errorf("잘못된 버퍼 크기: %d", opts.Buffer)
+	}
+	if opts.Buffer == 0 {
+		opts.Buffer = defaultSubscriptionBuffer
+	}
+	switch opts.Overflow {
+	case OverflowDropNewest, OverflowDropOldest:
+	case OverflowBlock:
+		if opts.BlockTimeout <= 0 {
+			opts.BlockTimeout = defaultBlockTimeout
+		}
+	default:
+		return nil, fmt.Errorf("알 수 없는 오버플로 정책: %s", opts.Overflow)
+	}
+
+	sub := &Subscription{
+		mon:  m,
+		opts: opts,
+		ch:   make(chan FileEvent, opts.Buffer),
+	}
+
+	m.subsMutex.Lock()
+	defer m.subsMutex.Unlock()
+	if m.subsClosed {
+		return nil, fmt.Errorf("모니터링이 이미 중지되었습니다")
+	}
+	m.subscribers = append(m.subscribers, sub)
+	return sub, nil
+}
+
+// Events는 구독한 이벤트를 받을 채널을 반환합니다.
+// Unsubscribe를 호출하거나 모니터가 중지되면 채널이 닫힙니다.
+func (s *Subscription) Events() <-chan FileEvent {
+	return s.ch
+}
+
+// Stats는 현재까지의 전달 및 유실 횟수를 반환합니다.
+func (s *Subscription) Stats() SubscriptionStats {
+	return SubscriptionStats{
+		Delivered:     s.delivered.Load(),
+		DroppedNewest: s.droppedNewest.Load(),
+		DroppedOldest: s.droppedOldest.Load(),
+	}
+}
+
+// Dropped는 유실된 이벤트의 총 개수를 반환합니다.
+func (s *Subscription) Dropped() uint64 {
+	return s.droppedNewest.Load() + s.droppedOldest.Load()
+}
+
+// Unsubscribe는 구독을 해지하고 채널을 닫습니다. 여러 번 호출해도 안전합니다.
+func (s *Subscription) Unsubscribe() {
+	s.mon.removeSubscriber(s)
+	s.close()
+}
+
+func (s *Subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// deliver는 구독의 필터와 오버플로 정책에 따라 이벤트를 전달합니다.
+func (s *Subscription) deliver(event FileEvent) {
+	if s.opts.Filter != nil && !s.opts.Filter(event) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- event:
+		s.delivered.Add(1)
+		return
+	default:
+	}
+
+	switch s.opts.Overflow {
+	case OverflowDropOldest:
+		// 소비자가 동시에 읽을 수 있으므로 자리가 날 때까지 가장 오래된 이벤트를 버림
+		for {
+			select {
+			case <-s.ch:
+				s.droppedOldest.Add(1)
+			default:
+			}
+			select {
+			case s.ch <- event:
+				s.delivered.Add(1)
+				return
+			default:
+			}
+		}
+
+	case OverflowBlock:
+		timer := time.NewTimer(s.opts.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case s.ch <- event:
+			s.delivered.Add(1)
+			return
+		case <-timer.C:
+		}
+	}
+
+	s.droppedNewest.Add(1)
+	log.Printf("구독자 버퍼가 가득 참 (%s): %s", s.opts.Overflow, event.Path)
+}
+
+// publish는 이벤트를 모든 구독자에게 전달합니다.
+func (m *Monitor) publish(event FileEvent) {
+	m.subsMutex.Lock()
+	subs := append([]*Subscription(nil), m.subscribers...)
+	m.subsMutex.Unlock()
+
+	for _, sub := range subs {
+		sub.deliver(event)
+	}
+}
+
+// removeSubscriber는 구독자 목록에서 구독을 제거합니다.
+func (m *Monitor) removeSubscriber(s *Subscription) {
+	m.subsMutex.Lock()
+	defer m.subsMutex.Unlock()
+	for i, sub := range m.subscribers {
+		if sub == s {
+			m.subscribers = append(m.subscribers[:i:i], m.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// closeSubscribers는 모든 구독을 닫고 이후의 구독을 거부합니다.
+func (m *Monitor) closeSubscribers() {
+	m.subsMutex.Lock()
+	subs := m.subscribers
+	m.subscribers = nil
+	m.subsClosed = true
+	m.subsMutex.Unlock()
+
+	for _, sub := range subs {
+		sub.close()
+	}
+}
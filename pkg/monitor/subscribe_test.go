@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSubscribeIndependentStreams(t *testing.T) {
+	var all, removes *Subscription
+	mon, fake, dir := startFakeMonitor(t, func(m *Monitor) {
+		var err error
+		if all, err = m.Subscribe(SubscribeOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		removes, err = m.Subscribe(SubscribeOptions{
+			Filter: func(e FileEvent) bool { return e.Operation == OperationRemove },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "a.exe"), Op: WatchCreate})
+	fake.Push(WatchEvent{Name: filepath.Join(dir, "b.exe"), Op: WatchRemove})
+
+	// 기본 채널을 읽어도 다른 구독자의 이벤트가 줄어들지 않아야 함
+	waitEvents(t, mon, 2)
+	mon.Stop()
+
+	var got []string
+	for e := range all.Events() {
+		got = append(got, e.Operation)
+	}
+	if len(got) != 2 || got[0] != OperationCreate || got[1] != OperationRemove {
+		t.Errorf("Expected CREATE and REMOVE on unfiltered subscription, got %v", got)
+	}
+
+	got = nil
+	for e := range removes.Events() {
+		got = append(got, e.Operation)
+	}
+	if len(got) != 1 || got[0] != OperationRemove {
+		t.Errorf("Expected only REMOVE on filtered subscription, got %v", got)
+	}
+
+	if _, err := mon.Subscribe(SubscribeOptions{}); err == nil {
+		t.Error("Expected error when subscribing to a stopped monitor")
+	}
+}
+
+func TestSubscriptionOverflowPolicies(t *testing.T) {
+	event := func(name string) FileEvent { return FileEvent{Path: name} }
+	drain := func(s *Subscription) []string {
+		var paths []string
+		for len(s.ch) > 0 {
+			paths = append(paths, (<-s.ch).Path)
+		}
+		return paths
+	}
+
+	mon := NewMonitor(time.Hour)
+	newest, _ := mon.Subscribe(SubscribeOptions{Buffer: 2, Overflow: OverflowDropNewest})
+	oldest, _ := mon.Subscribe(SubscribeOptions{Buffer: 2, Overflow: OverflowDropOldest})
+	block, _ := mon.Subscribe(SubscribeOptions{Buffer: 2, Overflow: OverflowBlock, BlockTimeout: 20 * time.Millisecond})
+
+	for _, name := range []string{"1", "2", "3"} {
+		mon.publish(event(name))
+	}
+
+	if got := drain(newest); len(got) != 2 || got[0] != "1" || got[1] != "2" {
+		t.Errorf("drop-newest: expected [1 2], got %v", got)
+	}
+	if stats := newest.Stats(); stats.Delivered != 2 || stats.DroppedNewest != 1 {
+		t.Errorf("drop-newest: unexpected stats %+v", stats)
+	}
+
+	if got := drain(oldest); len(got) != 2 || got[0] != "2" || got[1] != "3" {
+		t.Errorf("drop-oldest: expected [2 3], got %v", got)
+	}
+	if stats := oldest.Stats(); stats.Delivered != 3 || stats.DroppedOldest != 1 {
+		t.Errorf("drop-oldest: unexpected stats %+v", stats)
+	}
+
+	// 대기 시간 안에 자리가 나지 않으면 새 이벤트를 버림
+	if got := drain(block); len(got) != 2 || block.Dropped() != 1 {
+		t.Errorf("block: expected timeout drop, got %v (dropped %d)", got, block.Dropped())
+	}
+
+	// 소비자가 대기 시간 안에 읽으면 전달됨
+	block.opts.BlockTimeout = time.Second
+	mon.publish(event("4"))
+	mon.publish(event("5"))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-block.ch
+	}()
+	mon.publish(event("6"))
+	if stats := block.Stats(); stats.Delivered != 5 || stats.DroppedNewest != 1 {
+		t.Errorf("block: unexpected stats %+v", stats)
+	}
+
+	block.Unsubscribe()
+	newest.Unsubscribe()
+	newest.Unsubscribe()
+	mon.publish(event("7"))
+	for e := range newest.Events() {
+		if e.Path == "7" {
+			t.Error("Expected no delivery after Unsubscribe")
+		}
+	}
+
+	if _, err := mon.Subscribe(SubscribeOptions{Overflow: OverflowPolicy(9)}); err == nil {
+		t.Error("Expected error for unknown overflow policy")
+	}
+}
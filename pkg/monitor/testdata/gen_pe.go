@@ -0,0 +1,211 @@
+//go:build ignore
+
+// gen_pe.go는 PE 파서 테스트에 사용하는 작은 PE 픽스처 파일을 생성합니다.
+//
+//	go run testdata/gen_pe.go
+package main
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	fileAlign    = 0x200
+	sectionAlign = 0x1000
+	timestamp    = 0x5E0BE100 // 2020-01-01 00:00:00 UTC
+)
+
+type importSpec struct {
+	dll   string
+	names []string // 비어 있으면 서수(ordinal) 1번으로 가져옴
+}
+
+type fixture struct {
+	file      string
+	pe64      bool
+	dll       bool
+	subsystem uint16
+	imports   []importSpec
+}
+
+func main() {
+	fixtures := []fixture{
+		{
+			file:      "tiny32.exe",
+			subsystem: pe.IMAGE_SUBSYSTEM_WINDOWS_CUI,
+			imports: []importSpec{
+				{dll: "KERNEL32.dll", names: []string{"ExitProcess", "GetStdHandle"}},
+				{dll: "USER32.dll", names: []string{"MessageBoxA"}},
+			},
+		},
+		{
+			file:      "tiny64.dll",
+			pe64:      true,
+			dll:       true,
+			subsystem: pe.IMAGE_SUBSYSTEM_WINDOWS_GUI,
+			imports: []importSpec{
+				{dll: "KERNEL32.dll", names: []string{"VirtualAlloc"}},
+				{dll: "WS2_32.dll"},
+			},
+		},
+	}
+
+	for _, f := range fixtures {
+		data := build(f)
+		if err := os.WriteFile(filepath.Join("testdata", f.file), data, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%s: %d bytes", f.file, len(data))
+	}
+}
+
+func build(f fixture) []byte {
+	text := make([]byte, fileAlign)
+	seed := uint32(12345)
+	for i := range text {
+		seed = seed*1103515245 + 12345
+		text[i] = byte(seed >> 16)
+	}
+	rdata := buildImports(f, 0x2000)
+	data := make([]byte, fileAlign)
+
+	var buf bytes.Buffer
+
+	// DOS 헤더
+	dos := make([]byte, 0x40)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3C:], 0x40)
+	buf.Write(dos)
+	buf.WriteString("PE\x00\x00")
+
+	machine := uint16(pe.IMAGE_FILE_MACHINE_I386)
+	optSize := uint16(binary.Size(pe.OptionalHeader32{}))
+	characteristics := uint16(pe.IMAGE_FILE_EXECUTABLE_IMAGE | pe.IMAGE_FILE_32BIT_MACHINE)
+	if f.pe64 {
+		machine = pe.IMAGE_FILE_MACHINE_AMD64
+		optSize = uint16(binary.Size(pe.OptionalHeader64{}))
+		characteristics = pe.IMAGE_FILE_EXECUTABLE_IMAGE | pe.IMAGE_FILE_LARGE_ADDRESS_AWARE
+	}
+	if f.dll {
+		characteristics |= pe.IMAGE_FILE_DLL
+	}
+
+	write(&buf, pe.FileHeader{
+		Machine:              machine,
+		NumberOfSections:     3,
+		TimeDateStamp:        timestamp,
+		SizeOfOptionalHeader: optSize,
+		Characteristics:      characteristics,
+	})
+
+	var dirs [16]pe.DataDirectory
+	dirs[pe.IMAGE_DIRECTORY_ENTRY_IMPORT] = pe.DataDirectory{
+		VirtualAddress: 0x2000,
+		Size:           uint32(20 * (len(f.imports) + 1)),
+	}
+
+	if f.pe64 {
+		write(&buf, pe.OptionalHeader64{
+			Magic: 0x20b, AddressOfEntryPoint: 0x1000, BaseOfCode: 0x1000,
+			ImageBase: 0x180000000, SectionAlignment: sectionAlign, FileAlignment: fileAlign,
+			MajorOperatingSystemVersion: 6, MajorSubsystemVersion: 6,
+			SizeOfImage: 0x4000, SizeOfHeaders: fileAlign, Subsystem: f.subsystem,
+			SizeOfCode: fileAlign, NumberOfRvaAndSizes: 16, DataDirectory: dirs,
+		})
+	} else {
+		write(&buf, pe.OptionalHeader32{
+			Magic: 0x10b, AddressOfEntryPoint: 0x1000, BaseOfCode: 0x1000, BaseOfData: 0x2000,
+			ImageBase: 0x400000, SectionAlignment: sectionAlign, FileAlignment: fileAlign,
+			MajorOperatingSystemVersion: 4, MajorSubsystemVersion: 4,
+			SizeOfImage: 0x4000, SizeOfHeaders: fileAlign, Subsystem: f.subsystem,
+			SizeOfCode: fileAlign, NumberOfRvaAndSizes: 16, DataDirectory: dirs,
+		})
+	}
+
+	sections := []struct {
+		name  string
+		data  []byte
+		flags uint32
+	}{
+		{".text", text, pe.IMAGE_SCN_CNT_CODE | pe.IMAGE_SCN_MEM_EXECUTE | pe.IMAGE_SCN_MEM_READ},
+		{".rdata", rdata, pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ},
+		{".data", data, pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ | pe.IMAGE_SCN_MEM_WRITE},
+	}
+	for i, s := range sections {
+		var name [8]uint8
+		copy(name[:], s.name)
+		write(&buf, pe.SectionHeader32{
+			Name:             name,
+			VirtualSize:      uint32(len(s.data)),
+			VirtualAddress:   uint32(sectionAlign * (i + 1)),
+			SizeOfRawData:    uint32(len(s.data)),
+			PointerToRawData: uint32(fileAlign * (i + 1)),
+			Characteristics:  s.flags,
+		})
+	}
+
+	out := make([]byte, fileAlign*(len(sections)+1))
+	copy(out, buf.Bytes())
+	for i, s := range sections {
+		copy(out[fileAlign*(i+1):], s.data)
+	}
+	return out
+}
+
+// buildImports는 rva에 위치하는 가져오기 디렉토리 섹션을 구성합니다.
+func buildImports(f fixture, rva uint32) []byte {
+	section := make([]byte, fileAlign)
+	thunkOff := uint32(0x60)
+	nameOff := uint32(0x100)
+	dllOff := uint32(0x180)
+	thunkSize := uint32(4)
+	if f.pe64 {
+		thunkSize = 8
+	}
+
+	putThunk := func(off uint32, v uint64) {
+		if f.pe64 {
+			binary.LittleEndian.PutUint64(section[off:], v)
+		} else {
+			binary.LittleEndian.PutUint32(section[off:], uint32(v))
+		}
+	}
+
+	for i, imp := range f.imports {
+		desc := section[20*i:]
+		binary.LittleEndian.PutUint32(desc[0:], rva+thunkOff)
+		binary.LittleEndian.PutUint32(desc[12:], rva+dllOff)
+		binary.LittleEndian.PutUint32(desc[16:], rva+thunkOff)
+
+		copy(section[dllOff:], imp.dll)
+		dllOff += uint32(len(imp.dll)) + 1
+
+		if len(imp.names) == 0 {
+			ordinal := uint64(0x80000000)
+			if f.pe64 {
+				ordinal = 0x8000000000000000
+			}
+			putThunk(thunkOff, ordinal|1)
+			thunkOff += thunkSize
+		}
+		for _, name := range imp.names {
+			putThunk(thunkOff, uint64(rva+nameOff))
+			thunkOff += thunkSize
+			copy(section[nameOff+2:], name)
+			nameOff += uint32(len(name)) + 3
+		}
+		thunkOff += thunkSize // 종료 항목
+	}
+	return section
+}
+
+func write(buf *bytes.Buffer, v interface{}) {
+	if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package monitor
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// watchLimit은 사용자당 inotify 감시 한도(fs.inotify.max_user_watches)를 반환합니다. 알 수 없으면 0입니다.
+func watchLimit() int {
+	data, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		return 0
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return limit
+}
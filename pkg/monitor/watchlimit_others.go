@@ -0,0 +1,8 @@
+//go:build !linux
+
+package monitor
+
+// watchLimit은 감시 한도를 반환합니다. Linux 외의 플랫폼에서는 알 수 없으므로 0입니다.
+func watchLimit() int {
+	return 0
+}
@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// 파일이 잠겨 있거나 아직 쓰이는 중이면 defaultWorkerRetryDelay 간격으로 최대 defaultWorkerRetries번 다시 시도합니다.
+	defaultWorkerRetries    = 5
+	defaultWorkerRetryDelay = 500 * time.Millisecond
+
+	// recentResultLimit는 WaitFileHashes, WaitDetectedType으로 조회할 수 있도록 보관하는 최근 작업 결과의 개수입니다.
+	recentResultLimit = 1024
+)
+
+// fileWorkers는 이벤트 대상 파일을 읽는 작업(해시 계산, 시그니처 검사 등)을 처리하는 작업자 풀입니다.
+// processEvents가 파일을 읽느라 멈추지 않도록 작업은 대기열에 넣고 바로 반환하며,
+// 파일이 잠겨 있거나 아직 쓰이는 중이면 retryDelay 간격으로 최대 retries번 다시 시도합니다.
+type fileWorkers[J any] struct {
+	workers    int
+	queueSize  int
+	retries    int
+	retryDelay time.Duration
+
+	jobs chan J
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newFileWorkers[J any](workers, queueSize int) fileWorkers[J] {
+	return fileWorkers[J]{
+		workers:    workers,
+		queueSize:  queueSize,
+		retries:    defaultWorkerRetries,
+		retryDelay: defaultWorkerRetryDelay,
+	}
+}
+
+// start는 작업자 고루틴을 시작합니다. 각 작업은 handle로 처리됩니다.
+func (w *fileWorkers[J]) start(handle func(J)) {
+	w.jobs = make(chan J, w.queueSize)
+	w.quit = make(chan struct{})
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for job := range w.jobs {
+				handle(job)
+			}
+		}()
+	}
+}
+
+// submit은 작업을 대기열에 넣습니다. 대기열이 가득 차면 false를 반환합니다.
+func (w *fileWorkers[J]) submit(job J) bool {
+	select {
+	case w.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// submitWait는 대기열에 빈자리가 생길 때까지 기다려 작업을 넣습니다. ctx가 취소되면 false를 반환합니다.
+func (w *fileWorkers[J]) submitWait(ctx context.Context, job J) bool {
+	select {
+	case w.jobs <- job:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// close는 대기열을 닫고 남은 작업이 모두 끝날 때까지 기다립니다.
+// 종료 중에는 재시도 대기 없이 한 번씩만 시도합니다.
+func (w *fileWorkers[J]) close() {
+	if w.jobs == nil {
+		return
+	}
+	close(w.quit)
+	close(w.jobs)
+	w.wg.Wait()
+}
+
+// attempt는 try가 성공하거나 다시 시도할 수 없는 오류를 반환할 때까지 반복하고 마지막 오류를 반환합니다.
+// 마지막 시도에는 final이 true로 전달되며, 종료 중에는 기다리지 않고 마지막으로 한 번만 더 시도합니다.
+func (w *fileWorkers[J]) attempt(try func(final bool) (retry bool, err error)) error {
+	var lastErr error
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 && !w.wait() {
+			attempt = w.retries
+		}
+
+		retry, err := try(attempt == w.retries)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// wait는 재시도 간격만큼 기다립니다. 풀이 종료 중이면 즉시 false를 반환합니다.
+func (w *fileWorkers[J]) wait() bool {
+	select {
+	case <-time.After(w.retryDelay):
+		return true
+	case <-w.quit:
+		return false
+	}
+}
+
+// stableStat은 작업 전에 파일 상태를 조회합니다. 파일을 찾을 수 없거나(잠금 등) 최근에 수정되어
+// 아직 쓰이는 중으로 보이면 다시 시도하도록 retry를 설정합니다. final이면 최근 수정 여부는 확인하지 않습니다.
+func (w *fileWorkers[J]) stableStat(path string, final bool) (info os.FileInfo, retry bool, err error) {
+	info, err = os.Stat(path)
+	if err != nil {
+		return nil, !os.IsNotExist(err), err
+	}
+	if info.IsDir() {
+		return nil, false, fmt.Errorf("디렉토리입니다")
+	}
+	if !final && time.Since(info.ModTime()) < w.retryDelay {
+		return nil, true, fmt.Errorf("파일이 아직 쓰이는 중입니다")
+	}
+	return info, false, nil
+}
+
+// changedSince는 작업하는 동안 파일의 크기나 수정 시간이 바뀌었는지 확인합니다.
+func changedSince(before, after os.FileInfo) bool {
+	return after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime())
+}
+
+// eventResults는 작업 중이거나 최근에 끝난 작업의 결과를 이벤트 ID로 찾을 수 있도록 보관합니다.
+// 이벤트 ID는 작업을 요청할 때의 ID이며, 저장 시 다시 부여된 ID로 바뀌지 않습니다.
+type eventResults[T any] struct {
+	mu      sync.Mutex
+	pending map[int64]chan struct{} // 작업 중인 이벤트 (작업이 끝나면 닫힘)
+	recent  map[int64]T
+	order   []int64 // recent에 추가된 순서 (오래된 것부터 버림)
+}
+
+// expect는 이벤트의 작업이 시작되었음을 기록합니다. 이벤트를 구독자에게 전달하기 전에 호출해야 합니다.
+func (r *eventResults[T]) expect(eventID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = make(map[int64]chan struct{})
+	}
+	r.pending[eventID] = make(chan struct{})
+}
+
+// add는 작업 결과를 보관합니다. 개수가 제한을 넘으면 가장 오래된 결과를 버립니다.
+func (r *eventResults[T]) add(eventID int64, result T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recent == nil {
+		r.recent = make(map[int64]T)
+	}
+	r.recent[eventID] = result
+	r.order = append(r.order, eventID)
+	if len(r.order) > recentResultLimit {
+		delete(r.recent, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// finish는 이벤트의 작업이 끝났음을 알려 기다리는 쪽을 깨웁니다.
+func (r *eventResults[T]) finish(eventID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if done, ok := r.pending[eventID]; ok {
+		close(done)
+		delete(r.pending, eventID)
+	}
+}
+
+// wait는 이벤트의 결과를 반환합니다. 작업 중이면 끝나거나 ctx가 취소될 때까지 기다립니다.
+func (r *eventResults[T]) wait(ctx context.Context, eventID int64) (T, bool) {
+	r.mu.Lock()
+	done, ok := r.pending[eventID]
+	r.mu.Unlock()
+	if ok {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			var zero T
+			return zero, false
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.recent[eventID]
+	return result, ok
+}
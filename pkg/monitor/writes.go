@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultWriteSettle는 마지막 쓰기 이후 파일이 안정된 것으로 판단하는 기본 대기 시간입니다.
+const defaultWriteSettle = 2 * time.Second
+
+// writeBurst는 같은 경로에 연속해서 발생한 쓰기 이벤트 묶음입니다.
+// created가 설정된 묶음은 내용을 확인한 뒤 기록하기 위해 보류 중인 CREATE(oldPath가 있으면 MOVE)이며,
+// count가 0이면 생성 이후 쓰기가 없었던 것입니다.
+type writeBurst struct {
+	path    string
+	oldPath string
+	created time.Time
+	first   time.Time
+	last    time.Time
+	count   int
+
+	process *ProcessInfo // 마지막으로 쓴 프로세스 (백엔드가 제공하는 경우)
+}
+
+// writeCoalescer는 같은 경로의 연속된 쓰기 이벤트를 하나로 묶습니다.
+// 마지막 쓰기 이후 settle 시간 동안 추가 쓰기가 없으면 묶음이 완료됩니다.
+// processEvents 고루틴에서만 사용되므로 잠금이 필요 없습니다.
+type writeCoalescer struct {
+	settle time.Duration
+	bursts map[string]*writeBurst
+}
+
+func newWriteCoalescer(settle time.Duration) writeCoalescer {
+	return writeCoalescer{settle: settle, bursts: make(map[string]*writeBurst)}
+}
+
+// add는 쓰기 이벤트를 해당 경로의 묶음에 추가합니다.
+func (c *writeCoalescer) add(path string, now time.Time, process *ProcessInfo) {
+	burst, ok := c.bursts[path]
+	if !ok {
+		burst = &writeBurst{path: path}
+		c.bursts[path] = burst
+	}
+	if burst.count == 0 {
+		burst.first = now
+	}
+	burst.last = now
+	burst.count++
+	if process != nil {
+		burst.process = process
+	}
+}
+
+// hold는 새로 생성된 파일의 CREATE를 쓰기가 끝날 때까지 보류합니다. oldPath가 있으면 이동으로 생긴 파일입니다.
+// 이후의 쓰기는 같은 묶음에 더해지며, 묶음이 완료되면 CREATE(또는 MOVE)로 기록됩니다.
+func (c *writeCoalescer) hold(path, oldPath string, now time.Time, process *ProcessInfo) {
+	burst, ok := c.bursts[path]
+	if !ok {
+		burst = &writeBurst{path: path}
+		c.bursts[path] = burst
+	}
+	burst.oldPath = oldPath
+	burst.created = now
+	burst.last = now
+	if process != nil {
+		burst.process = process
+	}
+}
+
+// holding은 경로에 보류 중인 CREATE가 있는지 확인합니다.
+func (c *writeCoalescer) holding(path string) bool {
+	burst, ok := c.bursts[path]
+	return ok && !burst.created.IsZero()
+}
+
+// take는 경로의 진행 중인 묶음을 완료 여부와 관계없이 꺼냅니다.
+func (c *writeCoalescer) take(path string) (*writeBurst, bool) {
+	burst, ok := c.bursts[path]
+	if ok {
+		delete(c.bursts, path)
+	}
+	return burst, ok
+}
+
+// settled는 settle 시간 동안 조용했던 묶음을 꺼내 마지막 쓰기 시간 순으로 반환합니다.
+func (c *writeCoalescer) settled(now time.Time) []*writeBurst {
+	var done []*writeBurst
+	for path, burst := range c.bursts {
+		if now.Sub(burst.last) >= c.settle {
+			done = append(done, burst)
+			delete(c.bursts, path)
+		}
+	}
+	sortBursts(done)
+	return done
+}
+
+// flush는 진행 중인 모든 묶음을 꺼내 반환합니다.
+func (c *writeCoalescer) flush() []*writeBurst {
+	var done []*writeBurst
+	for path, burst := range c.bursts {
+		done = append(done, burst)
+		delete(c.bursts, path)
+	}
+	sortBursts(done)
+	return done
+}
+
+func sortBursts(bursts []*writeBurst) {
+	sort.Slice(bursts, func(i, j int) bool {
+		return bursts[i].last.Before(bursts[j].last)
+	})
+}
@@ -0,0 +1,280 @@
+package rules
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// maxHashSize는 해시 제공자 없이 hash 조건을 확인하기 위해 읽을 최대 파일 크기입니다. 이보다 큰 파일은 일치하지 않습니다.
+const maxHashSize = 100 << 20
+
+// HashSource는 이벤트 대상 파일의 해시를 제공합니다. 해시가 없으면 false를 반환합니다.
+// 모니터가 계산한 해시를 사용하려면 monitor.Monitor.WaitFileHashes를 감싸서 지정합니다.
+type HashSource func(event monitor.FileEvent) (monitor.FileHashes, bool)
+
+// TypeSource는 유형 없이 전달된 이벤트 대상 파일의 매직 바이트 유형을 제공합니다. 유형이 없으면 false를 반환합니다.
+// 모니터는 파일이 안정된 뒤에 유형을 판별하므로, 그 결과를 사용하려면 monitor.Monitor.WaitDetectedType을 감싸서 지정합니다.
+type TypeSource func(event monitor.FileEvent) (string, bool)
+
+// matcher는 컴파일된 Condition입니다.
+type matcher struct {
+	paths   *monitor.PathRule // nil이면 모든 경로
+	ops     map[string]bool
+	exts    map[string]bool
+	types   map[string]bool
+	minSize int64
+	maxSize int64
+	hashes  map[string]string // 소문자 digest -> 알고리즘
+	process *monitor.PathRule
+}
+
+// compileCondition은 조건을 검증하고 컴파일합니다.
+func compileCondition(c Condition) (*matcher, error) {
+	m := &matcher{minSize: int64(c.MinSize), maxSize: int64(c.MaxSize)}
+
+	if len(c.Path) > 0 || len(c.NotPath) > 0 {
+		m.paths = &monitor.PathRule{}
+		// 먼저 일치하는 항목이 결과를 결정하므로 제외 패턴을 앞에 둠
+		for _, pattern := range c.NotPath {
+			if err := m.paths.Exclude(pattern); err != nil {
+				return nil, err
+			}
+		}
+		for _, pattern := range c.Path {
+			if err := m.paths.Include(pattern); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(c.Operation) > 0 {
+		m.ops = make(map[string]bool)
+		for _, op := range c.Operation {
+			op = strings.ToUpper(strings.TrimSpace(op))
+			if !slices.Contains(operations, op) {
+				return nil, fmt.Errorf("알 수 없는 작업: %s (가능한 값: %s)", op, strings.Join(operations, ", "))
+			}
+			m.ops[op] = true
+		}
+	}
+
+	for _, t := range c.Type {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch {
+		case t == "" || t == ".":
+			return nil, fmt.Errorf("빈 유형입니다")
+		case strings.HasPrefix(t, "."):
+			if m.exts == nil {
+				m.exts = make(map[string]bool)
+			}
+			m.exts[t] = true
+		default:
+			if m.types == nil {
+				m.types = make(map[string]bool)
+			}
+			m.types[t] = true
+		}
+	}
+
+	if m.maxSize > 0 && m.minSize > m.maxSize {
+		return nil, fmt.Errorf("min_size(%d)가 max_size(%d)보다 큽니다", m.minSize, m.maxSize)
+	}
+
+	if len(c.Hash) > 0 {
+		m.hashes = make(map[string]string)
+		for _, digest := range c.Hash {
+			digest = strings.ToLower(strings.TrimSpace(digest))
+			algorithm, err := digestAlgorithm(digest)
+			if err != nil {
+				return nil, err
+			}
+			m.hashes[digest] = algorithm
+		}
+	}
+
+	if len(c.Process) > 0 {
+		m.process = &monitor.PathRule{}
+		for _, pattern := range c.Process {
+			if err := m.process.Include(pattern); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// digestAlgorithm은 16진수 digest의 길이로 해시 알고리즘을 판별합니다.
+func digestAlgorithm(digest string) (string, error) {
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", fmt.Errorf("잘못된 해시: %q", digest)
+	}
+	switch len(digest) {
+	case md5.Size * 2:
+		return monitor.HashMD5, nil
+	case sha1.Size * 2:
+		return monitor.HashSHA1, nil
+	case sha256.Size * 2:
+		return monitor.HashSHA256, nil
+	}
+	return "", fmt.Errorf("해시 길이가 MD5, SHA-1, SHA-256 중 어느 것과도 맞지 않습니다: %q", digest)
+}
+
+// match는 이벤트가 조건을 만족하는지 확인합니다. 해시는 Process가 잠금 밖에서 미리 구한 값으로 확인합니다.
+func (m *matcher) match(f *facts) bool {
+	if !m.matchFields(f.event) {
+		return false
+	}
+	if m.hashes != nil {
+		for digest, algorithm := range m.hashes {
+			if f.digests[algorithm] == digest {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// matchFields는 해시를 제외한 조건을 확인합니다.
+func (m *matcher) matchFields(e monitor.FileEvent) bool {
+	if m.exts != nil || m.types != nil {
+		if !m.exts[strings.ToLower(e.FileType)] && !m.types[e.DetectedType] {
+			return false
+		}
+	}
+	return m.matchUntyped(e)
+}
+
+// matchUntyped는 유형과 해시를 제외한 조건을 확인합니다.
+func (m *matcher) matchUntyped(e monitor.FileEvent) bool {
+	if m.ops != nil && !m.ops[e.Operation] {
+		return false
+	}
+	if m.paths != nil && !m.paths.Match(e.Path) {
+		return false
+	}
+	if m.minSize > 0 || m.maxSize > 0 {
+		if e.Stat == nil || e.Stat.Size < m.minSize || (m.maxSize > 0 && e.Stat.Size > m.maxSize) {
+			return false
+		}
+	}
+	if m.process != nil && (e.Process == nil || e.Process.Exe == "" || !m.process.Match(e.Process.Exe)) {
+		return false
+	}
+	return true
+}
+
+// wantsType은 이벤트에 매직 바이트 유형이 없어서 type 조건을 확인할 수 없고, 유형 외의 조건은 만족하는지 확인합니다.
+func (m *matcher) wantsType(e monitor.FileEvent) bool {
+	if m.types == nil || e.DetectedType != "" || m.exts[strings.ToLower(e.FileType)] {
+		return false
+	}
+	return m.matchUntyped(e)
+}
+
+// facts는 이벤트 하나를 여러 규칙으로 평가하는 동안 사용하는 값입니다.
+type facts struct {
+	event   monitor.FileEvent
+	digests map[string]string // 알고리즘 -> digest (구하지 못했으면 빈 문자열)
+}
+
+// resolveType은 유형 없이 전달된 이벤트의 매직 바이트 유형을 구해 이벤트에 채웁니다.
+// source가 있으면 모니터가 판별한 유형을 사용하고, 없으면 파일을 직접 읽어 판별합니다.
+// 파일을 읽거나 판별을 기다릴 수 있으므로 엔진 잠금 밖에서 호출해야 합니다.
+func (f *facts) resolveType(source TypeSource) {
+	if source == nil {
+		f.event.DetectedType, _ = monitor.SniffFile(f.event.Path)
+		return
+	}
+	if detected, ok := source(f.event); ok {
+		f.event.DetectedType = detected
+	}
+}
+
+// resolveDigests는 hash 조건 확인에 필요한 알고리즘의 해시를 구합니다.
+// source가 있으면 모니터가 계산한 해시를 사용하고, 없으면 파일을 직접 읽어 계산합니다.
+// 파일을 읽거나 해시 계산을 기다릴 수 있으므로 엔진 잠금 밖에서 호출해야 합니다.
+func (f *facts) resolveDigests(algorithms map[string]bool, source HashSource) {
+	f.digests = make(map[string]string, len(algorithms))
+	if source == nil {
+		for algorithm := range algorithms {
+			f.digests[algorithm], _ = hashFile(f.event.Path, algorithm)
+		}
+		return
+	}
+
+	hashes, ok := source(f.event)
+	if !ok {
+		return
+	}
+	for algorithm := range algorithms {
+		switch algorithm {
+		case monitor.HashMD5:
+			f.digests[algorithm] = hashes.MD5
+		case monitor.HashSHA1:
+			f.digests[algorithm] = hashes.SHA1
+		case monitor.HashSHA256:
+			f.digests[algorithm] = hashes.SHA256
+		}
+	}
+}
+
+// hashFile은 파일의 해시를 16진수 소문자로 계산합니다.
+func hashFile(path, algorithm string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxHashSize {
+		return "", fmt.Errorf("해시를 계산하지 않는 파일입니다: %s", path)
+	}
+
+	var h hash.Hash
+	switch algorithm {
+	case monitor.HashMD5:
+		h = md5.New()
+	case monitor.HashSHA1:
+		h = sha1.New()
+	default:
+		h = sha256.New()
+	}
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// groupKey는 규칙의 by 기준에 따른 이벤트의 묶음 키를 반환합니다.
+func groupKey(by string, e monitor.FileEvent) string {
+	switch by {
+	case ByPath:
+		return e.Path
+	case ByDir:
+		return filepath.Dir(e.Path)
+	case ByExt:
+		return strings.ToLower(filepath.Ext(e.Path))
+	case ByProcess:
+		if e.Process == nil {
+			return ""
+		}
+		return e.Process.Exe
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+// Package rules는 monitor 패키지의 파일 이벤트에 선언적 탐지 규칙을 적용합니다.
+//
+// 규칙은 YAML로 작성하며, 경로, 작업, 유형, 크기, 해시, 프로세스 조건과
+// 윈도 안의 개수(count), 순서대로 발생하는 이벤트(sequence)를 지정할 수 있습니다.
+//
+//	rules:
+//	  - id: temp-exe-dropped
+//	    title: 임시 디렉토리의 실행 파일이 곧바로 삭제됨
+//	    severity: high
+//	    sequence:
+//	      - {operation: CREATE, path: '**/Temp/**', type: [.exe, pe]}
+//	      - {operation: REMOVE}
+//	    within: 30s
+//	  - id: script-flood
+//	    severity: medium
+//	    match: {operation: CREATE, type: script}
+//	    count: 20
+//	    within: 1m
+//	    by: dir
+//
+// 사용 예시:
+//
+//	engine := rules.NewEngine()
+//	if err := engine.LoadFile("rules.yaml"); err != nil {
+//		log.Fatal(err)
+//	}
+//	go engine.WatchFile(ctx, 2*time.Second) // 파일이 바뀌면 다시 불러옴
+//	engine.SetHashSource(func(e monitor.FileEvent) (monitor.FileHashes, bool) {
+//		return mon.WaitFileHashes(ctx, e.ID) // hash 조건은 모니터가 계산한 해시로 확인
+//	})
+//	engine.SetTypeSource(func(e monitor.FileEvent) (string, bool) {
+//		return mon.WaitDetectedType(ctx, e.ID) // 이벤트에 없는 유형은 모니터가 파일이 안정된 뒤에 판별한 값으로 확인
+//	})
+//	sub, _ := mon.Subscribe(monitor.SubscribeOptions{Buffer: 1000})
+//	go engine.Run(sub.Events(), func(a rules.Alert) { log.Println(a) })
+package rules
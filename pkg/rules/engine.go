@@ -0,0 +1,498 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+const (
+	// maxPartials는 sequence 규칙에서 묶음 키 하나당 보관하는 진행 중인 일치의 최대 개수입니다.
+	// 넘으면 가장 오래된 것부터 버립니다.
+	maxPartials = 64
+
+	// sweepEvery는 윈도를 벗어난 상태를 정리하는 이벤트 간격입니다.
+	sweepEvery = 1024
+)
+
+// Alert는 규칙과 일치한 결과입니다. Events에는 경고에 기여한 이벤트가 발생 순서대로 담깁니다.
+type Alert struct {
+	RuleID     string
+	Title      string
+	Severity   string
+	Timestamp  time.Time // 마지막 기여 이벤트의 시간
+	Key        string    // by 기준의 묶음 키 (by: none이면 빈 문자열)
+	Events     []monitor.FileEvent
+	Quarantine bool // 규칙에 quarantine: true가 지정됨
+}
+
+// String은 로그에 출력할 경고 요약을 반환합니다.
+func (a Alert) String() string {
+	title := a.Title
+	if title == "" {
+		title = a.RuleID
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s: %s", a.Severity, a.RuleID, title)
+	if a.Key != "" {
+		fmt.Fprintf(&b, " (%s)", a.Key)
+	}
+	fmt.Fprintf(&b, " - 이벤트 %d개", len(a.Events))
+	if len(a.Events) > 0 {
+		last := a.Events[len(a.Events)-1]
+		fmt.Fprintf(&b, ", 마지막: %s %s", last.Operation, last.Path)
+	}
+	return b.String()
+}
+
+// compiledRule은 검증과 컴파일이 끝난 규칙입니다.
+type compiledRule struct {
+	rule     Rule
+	match    *matcher
+	sequence []*matcher
+}
+
+// digestAlgorithms는 이벤트가 해시 외의 조건을 만족하는 hash 조건에 필요한 알고리즘을 algorithms에 더합니다.
+func (c *compiledRule) digestAlgorithms(event monitor.FileEvent, algorithms map[string]bool) {
+	for _, m := range c.matchers() {
+		if m.hashes == nil || !m.matchFields(event) {
+			continue
+		}
+		for _, algorithm := range m.hashes {
+			algorithms[algorithm] = true
+		}
+	}
+}
+
+// wantsType은 이벤트에 유형이 없어서 확인할 수 없는 type 조건이 있는지 확인합니다.
+func (c *compiledRule) wantsType(event monitor.FileEvent) bool {
+	for _, m := range c.matchers() {
+		if m.wantsType(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchers는 규칙의 조건 목록을 반환합니다.
+func (c *compiledRule) matchers() []*matcher {
+	if c.match != nil {
+		return []*matcher{c.match}
+	}
+	return c.sequence
+}
+
+// compileRule은 규칙을 검증하고 기본값을 채워 컴파일합니다.
+func compileRule(r Rule) (*compiledRule, error) {
+	if strings.TrimSpace(r.ID) == "" {
+		return nil, fmt.Errorf("규칙 ID(id)가 비어 있습니다")
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityMedium
+	case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+	default:
+		return nil, fmt.Errorf("알 수 없는 심각도: %q (low, medium, high, critical)", r.Severity)
+	}
+	if r.Count < 0 || r.Within < 0 {
+		return nil, fmt.Errorf("count와 within은 0 이상이어야 합니다")
+	}
+
+	c := &compiledRule{}
+	switch {
+	case r.Match != nil && len(r.Sequence) > 0:
+		return nil, fmt.Errorf("match와 sequence는 함께 지정할 수 없습니다")
+	case r.Match != nil:
+		if (r.Count > 0) != (r.Within > 0) {
+			return nil, fmt.Errorf("count와 within은 함께 지정해야 합니다")
+		}
+		if r.By == "" {
+			r.By = ByNone
+		}
+		m, err := compileCondition(*r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("match: %v", err)
+		}
+		c.match = m
+	case len(r.Sequence) > 0:
+		if len(r.Sequence) < 2 {
+			return nil, fmt.Errorf("sequence에는 조건이 두 개 이상 필요합니다")
+		}
+		if r.Within <= 0 {
+			return nil, fmt.Errorf("sequence에는 within이 필요합니다")
+		}
+		if r.Count > 0 {
+			return nil, fmt.Errorf("sequence에는 count를 지정할 수 없습니다")
+		}
+		if r.By == "" {
+			r.By = ByPath
+		}
+		for i, cond := range r.Sequence {
+			m, err := compileCondition(cond)
+			if err != nil {
+				return nil, fmt.Errorf("sequence[%d]: %v", i, err)
+			}
+			c.sequence = append(c.sequence, m)
+		}
+	default:
+		return nil, fmt.Errorf("match 또는 sequence가 필요합니다")
+	}
+
+	switch r.By {
+	case ByNone, ByPath, ByDir, ByExt, ByProcess:
+	default:
+		return nil, fmt.Errorf("알 수 없는 묶음 기준: %q (none, path, dir, ext, process)", r.By)
+	}
+	c.rule = r
+	return c, nil
+}
+
+// ruleState는 규칙 하나와 그 규칙의 윈도 상태입니다.
+type ruleState struct {
+	def      Rule // 설정된 그대로의 규칙 (다시 불러올 때 변경 여부 비교용)
+	compiled *compiledRule
+	counts   map[string][]monitor.FileEvent   // count 규칙: 묶음 키별 윈도 안의 이벤트
+	partials map[string][][]monitor.FileEvent // sequence 규칙: 묶음 키별 진행 중인 일치
+}
+
+func newRuleState(def Rule, compiled *compiledRule) *ruleState {
+	return &ruleState{
+		def:      def,
+		compiled: compiled,
+		counts:   make(map[string][]monitor.FileEvent),
+		partials: make(map[string][][]monitor.FileEvent),
+	}
+}
+
+// Engine은 파일 이벤트를 규칙으로 평가하여 경고를 만듭니다.
+// 윈도는 이벤트의 Timestamp를 기준으로 계산합니다. 여러 고루틴에서 사용할 수 있습니다.
+type Engine struct {
+	mu        sync.Mutex
+	rules     []*ruleState
+	processed int
+	hashes    HashSource
+	types     TypeSource
+
+	// 규칙 파일 (LoadFile로 불러온 경우)
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// NewEngine은 규칙이 없는 엔진을 생성합니다.
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+// SetRules는 규칙 목록을 교체합니다. 규칙이 하나라도 올바르지 않으면 기존 규칙을 유지하고 오류를 반환합니다.
+// ID와 내용이 같은 규칙은 진행 중인 윈도 상태가 유지되고, 바뀌거나 새로 추가된 규칙은 처음부터 평가합니다.
+// disabled가 true인 규칙은 평가하지 않습니다.
+func (e *Engine) SetRules(rules []Rule) error {
+	var states []*ruleState
+	seen := make(map[string]bool)
+	for _, def := range rules {
+		compiled, err := compileRule(def)
+		if err != nil {
+			return fmt.Errorf("규칙 %s: %v", def.ID, err)
+		}
+		if seen[def.ID] {
+			return fmt.Errorf("중복된 규칙 ID입니다: %s", def.ID)
+		}
+		seen[def.ID] = true
+		if !def.Disabled {
+			states = append(states, newRuleState(def, compiled))
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	previous := make(map[string]*ruleState)
+	for _, rs := range e.rules {
+		previous[rs.def.ID] = rs
+	}
+	for i, rs := range states {
+		if old, ok := previous[rs.def.ID]; ok && reflect.DeepEqual(old.def, rs.def) {
+			states[i] = old
+		}
+	}
+	e.rules = states
+	return nil
+}
+
+// SetHashSource는 hash 조건을 확인할 때 사용할 해시 제공자를 지정합니다.
+// 지정하지 않으면 조건이 필요할 때마다 엔진이 파일을 직접 읽어 해시를 계산합니다.
+func (e *Engine) SetHashSource(source HashSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hashes = source
+}
+
+// SetTypeSource는 유형 없이 전달된 이벤트의 type 조건을 확인할 때 사용할 유형 제공자를 지정합니다.
+// 지정하지 않으면 조건이 필요할 때마다 엔진이 파일을 직접 읽어 유형을 판별합니다.
+func (e *Engine) SetTypeSource(source TypeSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.types = source
+}
+
+// Rules는 현재 평가 중인 규칙 목록을 반환합니다.
+func (e *Engine) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	rules := make([]Rule, len(e.rules))
+	for i, rs := range e.rules {
+		rules[i] = rs.def
+	}
+	return rules
+}
+
+// LoadFile은 규칙 파일을 읽어 규칙을 교체하고, 이후 Reload와 WatchFile에서 사용할 경로로 기억합니다.
+func (e *Engine) LoadFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	rules, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := e.SetRules(rules); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	e.mu.Lock()
+	e.path = path
+	e.modTime = info.ModTime()
+	e.size = info.Size()
+	e.mu.Unlock()
+	log.Printf("탐지 규칙 불러옴: %s (%d개)", path, len(e.Rules()))
+	return nil
+}
+
+// Reload는 LoadFile로 불러온 규칙 파일을 다시 읽습니다. 오류가 있으면 기존 규칙을 유지합니다.
+func (e *Engine) Reload() error {
+	e.mu.Lock()
+	path := e.path
+	e.mu.Unlock()
+	if path == "" {
+		return fmt.Errorf("불러온 규칙 파일이 없습니다")
+	}
+	return e.LoadFile(path)
+}
+
+// WatchFile은 interval마다 규칙 파일의 수정 시간과 크기를 확인하여, 바뀌었으면 다시 불러옵니다.
+// 저장 중인 파일을 읽지 않도록 바뀐 뒤 한 번 더 같은 상태가 확인되었을 때 불러옵니다.
+// 다시 불러오지 못하면 기존 규칙을 유지하고 로그를 남깁니다. ctx가 취소되면 반환합니다.
+func (e *Engine) WatchFile(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var pending os.FileInfo // 바뀐 것으로 확인되어 안정되기를 기다리는 상태
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		e.mu.Lock()
+		path, modTime, size := e.path, e.modTime, e.size
+		e.mu.Unlock()
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+			pending = nil
+			continue
+		}
+		if pending == nil || !info.ModTime().Equal(pending.ModTime()) || info.Size() != pending.Size() {
+			pending = info
+			continue
+		}
+		pending = nil
+
+		if err := e.LoadFile(path); err != nil {
+			log.Printf("탐지 규칙 다시 불러오기 실패, 기존 규칙 유지:\n%v", err)
+			// 같은 내용으로 계속 실패하지 않도록 확인한 시점을 기록
+			e.mu.Lock()
+			e.modTime, e.size = info.ModTime(), info.Size()
+			e.mu.Unlock()
+		}
+	}
+}
+
+// Run은 채널이 닫힐 때까지 이벤트를 평가하고, 경고마다 handle을 호출합니다.
+func (e *Engine) Run(events <-chan monitor.FileEvent, handle func(Alert)) {
+	for event := range events {
+		for _, alert := range e.Process(event) {
+			handle(alert)
+		}
+	}
+}
+
+// Process는 이벤트 하나를 모든 규칙으로 평가하여 이번 이벤트로 완성된 경고를 반환합니다.
+// 대량 변경 경고(monitor.OperationAlert) 이벤트는 평가하지 않습니다.
+// 이벤트에 없는 매직 바이트 유형과 hash 조건에 필요한 해시는 다른 호출을 막지 않도록 잠금 밖에서 먼저 구하며,
+// 구한 유형은 경고에 담기는 이벤트에도 채워집니다.
+func (e *Engine) Process(event monitor.FileEvent) []Alert {
+	if event.Alert != nil {
+		return nil
+	}
+
+	f := &facts{event: event}
+	wantsType := false
+	e.mu.Lock()
+	for _, rs := range e.rules {
+		wantsType = wantsType || rs.compiled.wantsType(event)
+	}
+	typeSource := e.types
+	e.mu.Unlock()
+	if wantsType {
+		f.resolveType(typeSource)
+	}
+
+	algorithms := make(map[string]bool)
+	e.mu.Lock()
+	for _, rs := range e.rules {
+		rs.compiled.digestAlgorithms(f.event, algorithms)
+	}
+	source := e.hashes
+	e.mu.Unlock()
+	if len(algorithms) > 0 {
+		f.resolveDigests(algorithms, source)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var alerts []Alert
+	for _, rs := range e.rules {
+		if alert, ok := rs.process(f); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	e.processed++
+	if e.processed%sweepEvery == 0 {
+		for _, rs := range e.rules {
+			rs.sweep(event.Timestamp)
+		}
+	}
+	return alerts
+}
+
+// process는 이벤트를 규칙 하나로 평가합니다.
+func (rs *ruleState) process(f *facts) (Alert, bool) {
+	r := rs.compiled.rule
+	event := f.event
+
+	// 단일 조건
+	if rs.compiled.match != nil {
+		if !rs.compiled.match.match(f) {
+			return Alert{}, false
+		}
+		key := groupKey(r.By, event)
+		if r.Count == 0 {
+			return rs.alert(key, []monitor.FileEvent{event}), true
+		}
+		window := append(trimEvents(rs.counts[key], event.Timestamp.Add(-r.Within)), event)
+		if len(window) < r.Count {
+			rs.counts[key] = window
+			return Alert{}, false
+		}
+		delete(rs.counts, key)
+		return rs.alert(key, window), true
+	}
+
+	// 순서대로 발생해야 하는 조건
+	key := groupKey(r.By, event)
+	cutoff := event.Timestamp.Add(-r.Within)
+	var kept [][]monitor.FileEvent
+	var completed []monitor.FileEvent
+	for _, partial := range rs.partials[key] {
+		if partial[0].Timestamp.Before(cutoff) {
+			continue
+		}
+		if completed == nil && rs.compiled.sequence[len(partial)].match(f) {
+			next := append(partial[:len(partial):len(partial)], event)
+			if len(next) == len(rs.compiled.sequence) {
+				completed = next
+				continue
+			}
+			kept = append(kept, next)
+			continue
+		}
+		kept = append(kept, partial)
+	}
+	if rs.compiled.sequence[0].match(f) {
+		kept = append(kept, []monitor.FileEvent{event})
+	}
+	if len(kept) > maxPartials {
+		kept = kept[len(kept)-maxPartials:]
+	}
+	if len(kept) == 0 {
+		delete(rs.partials, key)
+	} else {
+		rs.partials[key] = kept
+	}
+	if completed != nil {
+		return rs.alert(key, completed), true
+	}
+	return Alert{}, false
+}
+
+// alert는 기여 이벤트로 경고를 만듭니다.
+func (rs *ruleState) alert(key string, events []monitor.FileEvent) Alert {
+	r := rs.compiled.rule
+	return Alert{
+		RuleID:     r.ID,
+		Title:      r.Title,
+		Severity:   r.Severity,
+		Timestamp:  events[len(events)-1].Timestamp,
+		Key:        key,
+		Events:     events,
+		Quarantine: r.Quarantine,
+	}
+}
+
+// sweep은 now 기준으로 윈도를 벗어난 상태를 버립니다.
+func (rs *ruleState) sweep(now time.Time) {
+	cutoff := now.Add(-rs.compiled.rule.Within)
+	for key, events := range rs.counts {
+		if events = trimEvents(events, cutoff); len(events) == 0 {
+			delete(rs.counts, key)
+		} else {
+			rs.counts[key] = events
+		}
+	}
+	for key, partials := range rs.partials {
+		var kept [][]monitor.FileEvent
+		for _, partial := range partials {
+			if !partial[0].Timestamp.Before(cutoff) {
+				kept = append(kept, partial)
+			}
+		}
+		if len(kept) == 0 {
+			delete(rs.partials, key)
+		} else {
+			rs.partials[key] = kept
+		}
+	}
+}
+
+// trimEvents는 cutoff보다 오래된 이벤트를 앞에서부터 버립니다.
+func trimEvents(events []monitor.FileEvent, cutoff time.Time) []monitor.FileEvent {
+	i := 0
+	for i < len(events) && events[i].Timestamp.Before(cutoff) {
+		i++
+	}
+	return events[i:]
+}
@@ -0,0 +1,358 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// newTestEngine은 YAML 규칙으로 엔진을 만듭니다.
+func newTestEngine(t *testing.T, doc string) *Engine {
+	t.Helper()
+	rules, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	e := NewEngine()
+	if err := e.SetRules(rules); err != nil {
+		t.Fatalf("SetRules failed: %v", err)
+	}
+	return e
+}
+
+func event(op, path string, at time.Time) monitor.FileEvent {
+	return monitor.FileEvent{Operation: op, Path: path, FileType: strings.ToLower(filepath.Ext(path)), Timestamp: at}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"unknown field", "rules:\n  - id: a\n    match: {operation: CREATE}\n    colour: red\n", "colour"},
+		{"missing id", "rules:\n  - match: {operation: CREATE}\n", "ID"},
+		{"bad operation", "rules:\n  - id: a\n    match: {operation: OPEN}\n", "OPEN"},
+		{"bad severity", "rules:\n  - id: a\n    severity: urgent\n    match: {}\n", "urgent"},
+		{"count without within", "rules:\n  - id: a\n    match: {}\n    count: 3\n", "within"},
+		{"short sequence", "rules:\n  - id: a\n    sequence: [{operation: CREATE}]\n    within: 1s\n", "두 개"},
+		{"bad hash", "rules:\n  - id: a\n    match: {hash: abc}\n", "abc"},
+		{"bad size", "rules:\n  - id: a\n    match: {min_size: lots}\n", "lots"},
+		{"duplicate", "rules:\n  - id: a\n    match: {}\n  - id: a\n    match: {}\n", "중복"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSequenceRule(t *testing.T) {
+	e := newTestEngine(t, `
+rules:
+  - id: temp-exe-dropped
+    title: 임시 디렉토리의 실행 파일이 곧바로 삭제됨
+    severity: high
+    sequence:
+      - {operation: CREATE, path: '**/Temp/**', type: .exe}
+      - {operation: REMOVE}
+    within: 30s
+`)
+	base := time.Now()
+	temp := filepath.Join("C:", "Users", "a", "AppData", "Local", "Temp")
+	dropper := filepath.Join(temp, "x.exe")
+	other := filepath.Join(temp, "y.exe")
+
+	steps := []struct {
+		event  monitor.FileEvent
+		alerts int
+	}{
+		{event(monitor.OperationCreate, dropper, base), 0},
+		{event(monitor.OperationCreate, other, base), 0},
+		{event(monitor.OperationCreate, filepath.Join("D:", "x.exe"), base), 0},
+		{event(monitor.OperationRemove, filepath.Join("D:", "x.exe"), base.Add(time.Second)), 0},
+		{event(monitor.OperationRemove, dropper, base.Add(10*time.Second)), 1},
+		// 윈도를 넘긴 삭제는 일치하지 않음
+		{event(monitor.OperationRemove, other, base.Add(time.Minute)), 0},
+	}
+	for i, step := range steps {
+		alerts := e.Process(step.event)
+		if len(alerts) != step.alerts {
+			t.Fatalf("step %d: expected %d alerts, got %v", i, step.alerts, alerts)
+		}
+		if len(alerts) == 1 {
+			a := alerts[0]
+			if a.RuleID != "temp-exe-dropped" || a.Severity != SeverityHigh || a.Key != dropper || len(a.Events) != 2 {
+				t.Errorf("Unexpected alert: %+v", a)
+			}
+			if a.Events[0].Operation != monitor.OperationCreate || a.Events[1].Operation != monitor.OperationRemove {
+				t.Errorf("Unexpected contributing events: %+v", a.Events)
+			}
+		}
+	}
+}
+
+func TestCountRule(t *testing.T) {
+	e := newTestEngine(t, `
+rules:
+  - id: script-flood
+    match: {operation: CREATE, type: [.ps1, .vbs], min_size: 1KB}
+    count: 3
+    within: 1m
+    by: dir
+`)
+	base := time.Now()
+	stat := &monitor.FileStat{Size: 4096}
+	var alerts []Alert
+	for i, name := range []string{"a.ps1", "b.vbs", "small.ps1", "c.txt", "d.ps1", "e.ps1"} {
+		ev := event(monitor.OperationCreate, filepath.Join("scripts", name), base.Add(time.Duration(i)*time.Second))
+		if name != "small.ps1" {
+			ev.Stat = stat
+		}
+		alerts = append(alerts, e.Process(ev)...)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %v", alerts)
+	}
+	a := alerts[0]
+	if a.Severity != SeverityMedium || a.Key != "scripts" || len(a.Events) != 3 || filepath.Base(a.Events[2].Path) != "d.ps1" {
+		t.Errorf("Unexpected alert: %+v", a)
+	}
+}
+
+func TestHashCondition(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tool.bin")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := newTestEngine(t, `
+rules:
+  - id: known-bad
+    severity: critical
+    match:
+      hash:
+        - 5d41402abc4b2a76b9719d911017c592
+        - 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824
+`)
+	if alerts := e.Process(event(monitor.OperationCreate, path, time.Now())); len(alerts) != 1 {
+		t.Errorf("Expected hash match, got %v", alerts)
+	}
+	if alerts := e.Process(event(monitor.OperationCreate, filepath.Join(dir, "missing"), time.Now())); len(alerts) != 0 {
+		t.Errorf("Expected no match for missing file, got %v", alerts)
+	}
+}
+
+func TestQuarantineFlag(t *testing.T) {
+	e := newTestEngine(t, `
+rules:
+  - id: quarantine-create
+    severity: critical
+    quarantine: true
+    match:
+      operation: CREATE
+  - id: alert-only
+    severity: low
+    match:
+      operation: CREATE
+`)
+	alerts := e.Process(event(monitor.OperationCreate, filepath.Join(t.TempDir(), "tool.exe"), time.Now()))
+	if len(alerts) != 2 {
+		t.Fatalf("Expected 2 alerts, got %v", alerts)
+	}
+	for _, alert := range alerts {
+		if want := alert.RuleID == "quarantine-create"; alert.Quarantine != want {
+			t.Errorf("Rule %s: expected Quarantine %v, got %v", alert.RuleID, want, alert.Quarantine)
+		}
+	}
+}
+
+func TestHashSource(t *testing.T) {
+	e := newTestEngine(t, `
+rules:
+  - id: known-bad
+    severity: critical
+    match:
+      operation: CREATE
+      hash: [2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824]
+`)
+	var asked []int64
+	e.SetHashSource(func(ev monitor.FileEvent) (monitor.FileHashes, bool) {
+		asked = append(asked, ev.ID)
+		if ev.ID != 1 {
+			return monitor.FileHashes{}, false
+		}
+		return monitor.FileHashes{EventID: ev.ID, SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}, true
+	})
+
+	// 파일을 직접 읽지 않고 제공된 해시로 확인해야 함 (파일이 없어도 일치)
+	created := event(monitor.OperationCreate, filepath.Join(t.TempDir(), "gone.exe"), time.Now())
+	created.ID = 1
+	if alerts := e.Process(created); len(alerts) != 1 {
+		t.Errorf("Expected hash match from source, got %v", alerts)
+	}
+	created.ID = 2
+	if alerts := e.Process(created); len(alerts) != 0 {
+		t.Errorf("Expected no match without hashes, got %v", alerts)
+	}
+
+	// 해시 외의 조건이 맞지 않는 이벤트는 해시를 요청하지 않음
+	removed := event(monitor.OperationRemove, "a.exe", time.Now())
+	removed.ID = 3
+	e.Process(removed)
+	if len(asked) != 2 {
+		t.Errorf("Expected hashes requested only for CREATE events, got %v", asked)
+	}
+}
+
+func TestTypeSource(t *testing.T) {
+	e := newTestEngine(t, `
+rules:
+  - id: temp-pe-dropped
+    severity: high
+    sequence:
+      - {operation: CREATE, path: '**/Temp/**', type: pe}
+      - {operation: REMOVE}
+    within: 30s
+`)
+	var asked []int64
+	e.SetTypeSource(func(ev monitor.FileEvent) (string, bool) {
+		asked = append(asked, ev.ID)
+		return monitor.ContentTypePE, ev.ID == 1
+	})
+
+	// 유형 없이 전달된 CREATE도 제공된 유형으로 확인해야 함
+	now := time.Now()
+	created := event(monitor.OperationCreate, "/Users/a/Temp/setup.bin", now)
+	created.ID = 1
+	e.Process(created)
+	removed := event(monitor.OperationRemove, "/Users/a/Temp/setup.bin", now.Add(time.Second))
+	removed.ID = 2
+	alerts := e.Process(removed)
+	if len(alerts) != 1 || alerts[0].Events[0].DetectedType != monitor.ContentTypePE {
+		t.Fatalf("Expected sequence alert with the resolved type, got %v", alerts)
+	}
+	if len(asked) != 1 {
+		t.Errorf("Expected the type requested only for the CREATE event, got %v", asked)
+	}
+
+	// 제공자가 유형을 모르면 일치하지 않음
+	other := event(monitor.OperationCreate, "/Users/a/Temp/other.bin", now)
+	other.ID = 3
+	e.Process(other)
+	removed = event(monitor.OperationRemove, "/Users/a/Temp/other.bin", now.Add(time.Second))
+	if alerts := e.Process(removed); len(alerts) != 0 {
+		t.Errorf("Expected no alert without a type, got %v", alerts)
+	}
+}
+
+func TestTypeWithoutSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(path, []byte("MZ\x90\x00 payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := newTestEngine(t, `
+rules:
+  - id: pe-created
+    match: {operation: CREATE, type: pe}
+`)
+	// 제공자가 없으면 파일을 직접 읽어 판별
+	if alerts := e.Process(event(monitor.OperationCreate, path, time.Now())); len(alerts) != 1 {
+		t.Errorf("Expected type match from the file, got %v", alerts)
+	}
+}
+
+func TestHashSourceOutsideLock(t *testing.T) {
+	e := newTestEngine(t, `
+rules:
+  - id: known-bad
+    match: {operation: CREATE, hash: [5d41402abc4b2a76b9719d911017c592]}
+  - id: any-remove
+    match: {operation: REMOVE}
+`)
+	release := make(chan struct{})
+	e.SetHashSource(func(ev monitor.FileEvent) (monitor.FileHashes, bool) {
+		<-release
+		return monitor.FileHashes{MD5: "5d41402abc4b2a76b9719d911017c592"}, true
+	})
+
+	done := make(chan []Alert)
+	go func() { done <- e.Process(event(monitor.OperationCreate, "slow.exe", time.Now())) }()
+
+	// 해시를 기다리는 동안에도 다른 이벤트는 평가되어야 함
+	evaluated := make(chan []Alert)
+	go func() { evaluated <- e.Process(event(monitor.OperationRemove, "b.exe", time.Now())) }()
+	select {
+	case <-evaluated:
+	case <-time.After(time.Second):
+		t.Fatal("Process blocked while another call was waiting for hashes")
+	}
+
+	close(release)
+	if alerts := <-done; len(alerts) != 1 || alerts[0].RuleID != "known-bad" {
+		t.Errorf("Expected hash match after waiting, got %v", alerts)
+	}
+}
+
+func TestWatchFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(doc string, mod time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := time.Now().Add(-time.Hour)
+	seqRule := "rules:\n  - id: seq\n    sequence: [{operation: CREATE}, {operation: REMOVE}]\n    within: 1m\n"
+	write(seqRule, base)
+
+	e := NewEngine()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	now := time.Now()
+	e.Process(event(monitor.OperationCreate, "a.exe", now))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.WatchFile(ctx, 10*time.Millisecond)
+
+	waitRules := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for len(e.Rules()) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d rules after reload, got %v", want, e.Rules())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// 규칙이 추가되어도 바뀌지 않은 규칙의 진행 상태는 유지
+	write(seqRule+"  - id: exec\n    match: {operation: EXEC}\n", base.Add(time.Minute))
+	waitRules(2)
+	if alerts := e.Process(event(monitor.OperationRemove, "a.exe", now.Add(time.Second))); len(alerts) != 1 {
+		t.Errorf("Expected sequence state to survive reload, got %v", alerts)
+	}
+
+	// 잘못된 파일은 기존 규칙 유지
+	write("rules:\n  - id: broken\n", base.Add(2*time.Minute))
+	time.Sleep(100 * time.Millisecond)
+	if rules := e.Rules(); len(rules) != 2 {
+		t.Errorf("Expected previous rules to be kept, got %v", rules)
+	}
+
+	write("rules:\n  - id: exec\n    match: {operation: EXEC}\n", base.Add(3*time.Minute))
+	waitRules(1)
+}
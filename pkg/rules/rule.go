@@ -0,0 +1,186 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yhj0901/windowsIOMonitoring/pkg/monitor"
+)
+
+// Rule.Severity에 사용할 수 있는 심각도입니다.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium" // 지정하지 않은 경우의 기본값
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Rule.By에 사용할 수 있는 이벤트 묶음 기준입니다.
+const (
+	ByNone    = "none"    // 모든 이벤트를 하나로 묶음 (count 규칙의 기본값)
+	ByPath    = "path"    // 같은 파일 경로 (sequence 규칙의 기본값)
+	ByDir     = "dir"     // 같은 디렉토리
+	ByExt     = "ext"     // 같은 확장자
+	ByProcess = "process" // 같은 프로세스 실행 파일 (fanotify 백엔드에서만 제공)
+)
+
+// Rule은 파일 이벤트에 대한 탐지 규칙 하나입니다. 규칙은 세 가지 형태 중 하나입니다.
+//
+//   - match만 지정: 조건에 맞는 이벤트마다 경고
+//   - match와 count, within: within 동안 조건에 맞는 이벤트가 by별로 count개 이상이면 경고
+//   - sequence와 within: 조건에 맞는 이벤트가 by별로 순서대로 모두 발생하면 경고
+//     (첫 이벤트부터 마지막 이벤트까지 within 이내)
+//
+// 예시 (임시 디렉토리에 생성된 뒤 30초 안에 삭제된 실행 파일):
+//
+//	rules:
+//	  - id: temp-exe-dropped
+//	    title: 임시 디렉토리의 실행 파일이 곧바로 삭제됨
+//	    severity: high
+//	    sequence:
+//	      - {operation: CREATE, path: '**/Temp/**', type: pe}
+//	      - {operation: REMOVE}
+//	    within: 30s
+//
+// quarantine: true인 규칙의 경고는 Alert.Quarantine이 설정되어, 기여한 이벤트의 파일을 격리하는 데 사용할 수 있습니다.
+type Rule struct {
+	ID         string        `yaml:"id"`
+	Title      string        `yaml:"title"`
+	Severity   string        `yaml:"severity"`
+	Disabled   bool          `yaml:"disabled"`
+	Match      *Condition    `yaml:"match"`
+	Sequence   []Condition   `yaml:"sequence"`
+	Count      int           `yaml:"count"`
+	Within     time.Duration `yaml:"within"`
+	By         string        `yaml:"by"`
+	Quarantine bool          `yaml:"quarantine"`
+}
+
+// Condition은 이벤트 하나가 만족해야 하는 조건입니다. 지정한 항목을 모두 만족해야 일치하며,
+// 목록으로 지정한 항목은 그중 하나만 일치하면 됩니다.
+type Condition struct {
+	Path      StringList `yaml:"path"`      // 경로 패턴 (doublestar 글롭 또는 "re:" 정규식)
+	NotPath   StringList `yaml:"not_path"`  // 제외할 경로 패턴
+	Operation StringList `yaml:"operation"` // CREATE, REMOVE, RENAME, MOVE, MODIFIED, WRITE, CHMOD, EXEC
+	Type      StringList `yaml:"type"`      // "."으로 시작하면 확장자, 아니면 매직 바이트로 판별한 유형 (예: pe)
+	MinSize   Size       `yaml:"min_size"`  // 이벤트 시점의 파일 크기 하한 (예: 1MB)
+	MaxSize   Size       `yaml:"max_size"`  // 이벤트 시점의 파일 크기 상한
+	Hash      StringList `yaml:"hash"`      // MD5, SHA-1, SHA-256 (길이로 구분)
+	Process   StringList `yaml:"process"`   // 이벤트를 일으킨 프로세스의 실행 파일 경로 패턴
+}
+
+// StringList는 YAML에서 문자열 하나 또는 문자열 목록으로 지정할 수 있는 값입니다.
+type StringList []string
+
+// UnmarshalYAML은 스칼라 값을 항목이 하나인 목록으로 읽습니다.
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
+
+// Size는 바이트 단위 크기입니다. YAML에서는 숫자 또는 단위(KB, MB, GB)를 붙인 문자열로 지정합니다.
+type Size int64
+
+// UnmarshalYAML은 "512", "10KB", "1.5MB" 형식의 크기를 읽습니다. 단위는 1024 배수입니다.
+func (s *Size) UnmarshalYAML(node *yaml.Node) error {
+	size, err := parseSize(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %v", node.Line, err)
+	}
+	*s = size
+	return nil
+}
+
+// parseSize는 단위가 붙은 크기 문자열을 바이트 수로 변환합니다.
+func parseSize(value string) (Size, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			text = strings.TrimSpace(number)
+			multiplier = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("잘못된 크기: %q (예: 512, 10KB, 1.5MB)", value)
+	}
+	return Size(n * float64(multiplier)), nil
+}
+
+// ruleFile은 규칙 파일의 최상위 구조입니다.
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Parse는 YAML 규칙 문서를 읽고 모든 규칙을 검증합니다.
+// 알 수 없는 항목이 있거나 규칙이 올바르지 않으면 오류를 반환합니다.
+func Parse(data []byte) ([]Rule, error) {
+	var file ruleFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var errs []string
+	for i, rule := range file.Rules {
+		if _, err := compileRule(rule); err != nil {
+			errs = append(errs, fmt.Sprintf("rules[%d] (%s): %v", i, rule.ID, err))
+			continue
+		}
+		if seen[rule.ID] {
+			errs = append(errs, fmt.Sprintf("rules[%d]: 중복된 규칙 ID입니다: %s", i, rule.ID))
+		}
+		seen[rule.ID] = true
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return file.Rules, nil
+}
+
+// LoadFile은 규칙 파일을 읽어 Parse합니다.
+func LoadFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return rules, nil
+}
+
+// operations는 Condition.Operation에 사용할 수 있는 작업 이름입니다.
+var operations = []string{
+	monitor.OperationCreate,
+	monitor.OperationRemove,
+	monitor.OperationRename,
+	monitor.OperationMove,
+	monitor.OperationModified,
+	monitor.OperationWrite,
+	monitor.OperationChmod,
+	monitor.OperationExec,
+}
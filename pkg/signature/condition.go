@@ -0,0 +1,450 @@
+package signature
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 조건식 문법 (YARA의 일부):
+//
+//	expr     = and { "or" and }
+//	and      = unary { "and" unary }
+//	unary    = "not" unary | primary
+//	primary  = "(" expr ")" | "true" | "false"
+//	         | $id [ "at" 숫자 ]            문자열이 있음 (at: 해당 위치에서 시작)
+//	         | #id 비교 숫자                 문자열이 나온 횟수
+//	         | "filesize" 비교 크기          파일 크기 (예: 10KB, 2MB)
+//	         | 개수 "of" 집합                개수: all, any, 숫자 / 집합: them, ($a, $b*)
+//	비교     = "==" | "!=" | "<" | "<=" | ">" | ">="
+
+// scanState는 조건식을 평가할 때 사용하는 검사 결과입니다.
+type scanState struct {
+	offsets map[string][]int
+	size    int64
+}
+
+// node는 조건식의 구성 요소입니다.
+type node interface {
+	eval(st *scanState) bool
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(st *scanState) bool { return n.left.eval(st) || n.right.eval(st) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(st *scanState) bool { return n.left.eval(st) && n.right.eval(st) }
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(st *scanState) bool { return !n.operand.eval(st) }
+
+type boolNode bool
+
+func (n boolNode) eval(*scanState) bool { return bool(n) }
+
+// stringNode는 $id 또는 $id at N입니다. at이 음수이면 위치를 확인하지 않습니다.
+type stringNode struct {
+	id string
+	at int64
+}
+
+func (n stringNode) eval(st *scanState) bool {
+	offsets := st.offsets[n.id]
+	if n.at < 0 {
+		return len(offsets) > 0
+	}
+	for _, off := range offsets {
+		if int64(off) == n.at {
+			return true
+		}
+	}
+	return false
+}
+
+// compareNode는 #id 또는 filesize를 숫자와 비교합니다. id가 비어 있으면 filesize입니다.
+type compareNode struct {
+	id    string
+	op    string
+	value int64
+}
+
+func (n compareNode) eval(st *scanState) bool {
+	actual := st.size
+	if n.id != "" {
+		actual = int64(len(st.offsets[n.id]))
+	}
+	return compare(actual, n.op, n.value)
+}
+
+// ofNode는 "all of them", "2 of ($a, $b)" 형식의 조건입니다. need가 0이면 all입니다.
+type ofNode struct {
+	need int
+	ids  []string
+}
+
+func (n ofNode) eval(st *scanState) bool {
+	need := n.need
+	if need == 0 {
+		need = len(n.ids)
+	}
+	found := 0
+	for _, id := range n.ids {
+		if len(st.offsets[id]) > 0 {
+			found++
+			if found >= need {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compare(a int64, op string, b int64) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// conditionParser는 조건식을 읽는 재귀 하향 파서입니다.
+type conditionParser struct {
+	tokens []string
+	pos    int
+	ids    []string // 시그니처에 정의된 문자열 ID (정렬됨)
+	known  map[string]bool
+}
+
+// parseCondition은 조건식을 읽고 정의되지 않은 문자열을 참조하는지 확인합니다.
+func parseCondition(expr string, ids []string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens, ids: ids, known: make(map[string]bool, len(ids))}
+	for _, id := range ids {
+		p.known[id] = true
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("예상하지 못한 %q", p.tokens[p.pos])
+	}
+	return n, nil
+}
+
+// tokenize는 조건식을 토큰으로 나눕니다.
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.ContainsRune("=!<>", rune(c)):
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				tokens = append(tokens, expr[i:i+2])
+				i += 2
+				continue
+			}
+			if c == '=' || c == '!' {
+				return nil, fmt.Errorf("잘못된 연산자 %q", c)
+			}
+			tokens = append(tokens, string(c))
+			i++
+		case c == '$' || c == '#' || isWordByte(c):
+			j := i + 1
+			for j < len(expr) && (isWordByte(expr[j]) || expr[j] == '*') {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("잘못된 문자 %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("조건식이 끝나지 않았습니다")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+func (p *conditionParser) expect(want string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("%q가 필요하지만 %q가 있습니다", want, tok)
+	}
+	return nil
+}
+
+func (p *conditionParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (node, error) {
+	if p.peek() == "not" {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *conditionParser) parsePrimary() (node, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case tok == "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case tok == "true" || tok == "false":
+		return boolNode(tok == "true"), nil
+	case tok == "filesize":
+		op, value, err := p.parseComparison(true)
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: op, value: value}, nil
+	case strings.HasPrefix(tok, "#"):
+		id := "$" + tok[1:]
+		if err := p.checkID(id); err != nil {
+			return nil, err
+		}
+		op, value, err := p.parseComparison(false)
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{id: id, op: op, value: value}, nil
+	case strings.HasPrefix(tok, "$"):
+		if err := p.checkID(tok); err != nil {
+			return nil, err
+		}
+		n := stringNode{id: tok, at: -1}
+		if p.peek() == "at" {
+			p.pos++
+			offset, err := p.parseNumber(false)
+			if err != nil {
+				return nil, err
+			}
+			n.at = offset
+		}
+		return n, nil
+	case tok == "all" || tok == "any" || isNumber(tok):
+		return p.parseOf(tok)
+	}
+	return nil, fmt.Errorf("예상하지 못한 %q", tok)
+}
+
+// parseOf는 "개수 of 집합"에서 개수 다음 부분을 읽습니다.
+func (p *conditionParser) parseOf(quantifier string) (node, error) {
+	n := ofNode{}
+	switch quantifier {
+	case "all":
+	case "any":
+		n.need = 1
+	default:
+		need, err := strconv.Atoi(quantifier)
+		if err != nil || need <= 0 {
+			return nil, fmt.Errorf("잘못된 개수 %q", quantifier)
+		}
+		n.need = need
+	}
+	if err := p.expect("of"); err != nil {
+		return nil, err
+	}
+
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case "them":
+		n.ids = p.ids
+	case "(":
+		seen := make(map[string]bool)
+		for {
+			item, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			matched, err := p.expandSetItem(item)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range matched {
+				if !seen[id] {
+					seen[id] = true
+					n.ids = append(n.ids, id)
+				}
+			}
+			sep, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if sep == ")" {
+				break
+			}
+			if sep != "," {
+				return nil, fmt.Errorf("\",\" 또는 \")\"가 필요하지만 %q가 있습니다", sep)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("them 또는 (...)이 필요하지만 %q가 있습니다", tok)
+	}
+	if n.need > len(n.ids) {
+		return nil, fmt.Errorf("%d of: 문자열이 %d개뿐입니다", n.need, len(n.ids))
+	}
+	return n, nil
+}
+
+// expandSetItem은 집합 항목($a 또는 $a*)을 문자열 ID 목록으로 바꿉니다.
+func (p *conditionParser) expandSetItem(item string) ([]string, error) {
+	if !strings.HasPrefix(item, "$") {
+		return nil, fmt.Errorf("집합에는 $로 시작하는 문자열만 올 수 있습니다: %q", item)
+	}
+	prefix, wildcard := strings.CutSuffix(item, "*")
+	if !wildcard {
+		return []string{item}, p.checkID(item)
+	}
+	var ids []string
+	for _, id := range p.ids {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%s와 일치하는 문자열이 없습니다", item)
+	}
+	return ids, nil
+}
+
+func (p *conditionParser) checkID(id string) error {
+	if strings.Contains(id, "*") || !p.known[id] {
+		return fmt.Errorf("정의되지 않은 문자열 %s", id)
+	}
+	return nil
+}
+
+// parseComparison은 비교 연산자와 숫자를 읽습니다. withUnit이면 KB, MB, GB 단위를 허용합니다.
+func (p *conditionParser) parseComparison(withUnit bool) (string, int64, error) {
+	op, err := p.next()
+	if err != nil {
+		return "", 0, err
+	}
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return "", 0, fmt.Errorf("비교 연산자가 필요하지만 %q가 있습니다", op)
+	}
+	value, err := p.parseNumber(withUnit)
+	return op, value, err
+}
+
+// parseNumber는 10진수 또는 0x로 시작하는 16진수를 읽습니다.
+func (p *conditionParser) parseNumber(withUnit bool) (int64, error) {
+	tok, err := p.next()
+	if err != nil {
+		return 0, err
+	}
+	text := strings.ToUpper(tok)
+	multiplier := int64(1)
+	if withUnit && !strings.HasPrefix(text, "0X") {
+		for _, unit := range []struct {
+			suffix string
+			size   int64
+		}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}} {
+			if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+				text = number
+				multiplier = unit.size
+				break
+			}
+		}
+	}
+	base := 10
+	if hex, ok := strings.CutPrefix(text, "0X"); ok {
+		text, base = hex, 16
+	}
+	n, err := strconv.ParseInt(text, base, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("잘못된 숫자 %q", tok)
+	}
+	return n * multiplier, nil
+}
+
+func isNumber(tok string) bool {
+	return tok != "" && '0' <= tok[0] && tok[0] <= '9'
+}
@@ -0,0 +1,27 @@
+// Package signature는 파일 내용에서 알려진 바이트 패턴을 찾는 YARA 방식의 시그니처 검사기입니다.
+// 순수 Go로 작성되어 cgo나 외부 라이브러리 없이 모든 플랫폼에서 동작합니다.
+//
+// 시그니처는 YAML로 작성하며, 텍스트 문자열(nocase, wide 지원)과 와일드카드와 점프를 쓸 수 있는
+// 16진수 패턴, 그리고 이를 조합하는 조건식(and, or, not, at, #개수, filesize, N of them)을 지정합니다.
+//
+//	signatures:
+//	  - id: eicar-test-file
+//	    description: EICAR 안티바이러스 테스트 파일
+//	    strings:
+//	      $eicar: 'X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*'
+//	  - id: pe-packed-upx
+//	    types: [pe, .exe, .dll]
+//	    strings:
+//	      $mz: {hex: "4D 5A"}
+//	      $upx0: UPX0
+//	      $upx1: UPX1
+//	    condition: $mz at 0 and all of ($upx*)
+//
+// 사용 예시:
+//
+//	set, err := signature.LoadFile("signatures.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	matches, err := set.ScanFile(path, 32<<20, ".exe", "pe")
+package signature
@@ -0,0 +1,287 @@
+package signature
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// maxJump는 16진수 패턴의 점프([n-m])에 지정할 수 있는 최대 바이트 수입니다.
+	maxJump = 4096
+	// maxOffsets는 문자열 하나에 대해 기록하는 최대 일치 위치 수입니다.
+	maxOffsets = 1000
+)
+
+// Pattern은 시그니처에서 찾을 바이트 패턴 하나입니다. Text와 Hex 중 하나만 지정합니다.
+// YAML에서 문자열 하나만 쓰면 Text로 읽습니다.
+//
+// Hex는 공백으로 구분한 16진수 바이트이며, "??"(임의의 바이트), "4?"(상위 니블만 비교),
+// "[2-4]"(2~4바이트 건너뜀), "[8]"(정확히 8바이트 건너뜀)를 사용할 수 있습니다.
+type Pattern struct {
+	Text   string `yaml:"text"`
+	Hex    string `yaml:"hex"`
+	NoCase bool   `yaml:"nocase"` // Text의 ASCII 대소문자 구분 안 함
+	Wide   bool   `yaml:"wide"`   // Text를 UTF-16LE로 인코딩하여 찾음
+}
+
+// segment는 점프 없이 이어지는 바이트 열입니다. mask가 0인 위치는 임의의 바이트입니다.
+type segment struct {
+	value []byte
+	mask  []byte
+	// jumpMin, jumpMax는 다음 segment까지 건너뛸 바이트 수입니다.
+	jumpMin int
+	jumpMax int
+}
+
+// matches는 data[pos:]가 segment와 일치하는지 확인합니다.
+func (s *segment) matches(data []byte, pos int) bool {
+	if pos+len(s.value) > len(data) {
+		return false
+	}
+	for i, v := range s.value {
+		if data[pos+i]&s.mask[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// literalPrefix는 segment 앞부분에서 모든 비트를 비교하는 바이트 열을 반환합니다.
+func (s *segment) literalPrefix() []byte {
+	n := 0
+	for n < len(s.mask) && s.mask[n] == 0xff {
+		n++
+	}
+	return s.value[:n]
+}
+
+// compiledPattern은 검색 가능한 형태로 변환된 Pattern입니다.
+type compiledPattern struct {
+	segments []segment
+	nocase   bool
+}
+
+// compilePattern은 패턴을 검증하고 변환합니다.
+func compilePattern(p Pattern) (*compiledPattern, error) {
+	switch {
+	case p.Text != "" && p.Hex != "":
+		return nil, fmt.Errorf("text와 hex는 함께 지정할 수 없습니다")
+	case p.Text != "":
+		text := []byte(p.Text)
+		if p.NoCase {
+			text = lowerASCII(text)
+		}
+		if p.Wide {
+			text = encodeWide(string(text))
+		}
+		return &compiledPattern{segments: []segment{literal(text)}, nocase: p.NoCase}, nil
+	case p.Hex != "":
+		if p.NoCase || p.Wide {
+			return nil, fmt.Errorf("nocase와 wide는 text에만 사용할 수 있습니다")
+		}
+		segments, err := parseHex(p.Hex)
+		if err != nil {
+			return nil, err
+		}
+		return &compiledPattern{segments: segments}, nil
+	}
+	return nil, fmt.Errorf("text 또는 hex가 필요합니다")
+}
+
+// literal은 모든 바이트를 그대로 비교하는 segment를 만듭니다.
+func literal(value []byte) segment {
+	return segment{value: value, mask: bytes.Repeat([]byte{0xff}, len(value))}
+}
+
+// lowerASCII는 ASCII 대문자만 소문자로 바꾼 복사본을 반환합니다.
+// bytes.ToLower와 달리 UTF-8이 아닌 바이트를 바꾸지 않으므로 길이와 위치가 유지됩니다.
+func lowerASCII(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, c := range data {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// encodeWide는 문자열을 UTF-16LE 바이트 열로 변환합니다.
+func encodeWide(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		out = append(out, byte(u), byte(u>>8))
+	}
+	return out
+}
+
+// parseHex는 "4D 5A ?? [2-4] 50 45" 형식의 16진수 패턴을 segment 목록으로 변환합니다.
+func parseHex(expr string) ([]segment, error) {
+	var segments []segment
+	current := segment{}
+	text := strings.Join(strings.Fields(expr), "")
+	for i := 0; i < len(text); {
+		if text[i] == '[' {
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("닫히지 않은 점프: %q", expr)
+			}
+			lo, hi, err := parseJump(text[i+1 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			if len(current.value) == 0 {
+				return nil, fmt.Errorf("점프는 바이트 사이에만 올 수 있습니다: %q", expr)
+			}
+			current.jumpMin += lo
+			current.jumpMax += hi
+			i += end + 1
+			continue
+		}
+		if i+1 >= len(text) {
+			return nil, fmt.Errorf("바이트는 16진수 두 자리여야 합니다: %q", expr)
+		}
+		value, mask, err := parseHexByte(text[i], text[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("%v: %q", err, expr)
+		}
+		// 점프 뒤의 바이트는 새 segment에서 시작
+		if current.jumpMax > 0 {
+			segments = append(segments, current)
+			current = segment{}
+		}
+		current.value = append(current.value, value)
+		current.mask = append(current.mask, mask)
+		i += 2
+	}
+	if len(current.value) == 0 {
+		return nil, fmt.Errorf("빈 16진수 패턴입니다")
+	}
+	if current.jumpMax > 0 {
+		return nil, fmt.Errorf("패턴은 점프로 끝날 수 없습니다: %q", expr)
+	}
+	segments = append(segments, current)
+	for _, s := range segments {
+		if s.jumpMax > maxJump {
+			return nil, fmt.Errorf("점프는 %d바이트 이하여야 합니다: %q", maxJump, expr)
+		}
+	}
+	return segments, nil
+}
+
+// parseJump는 "2-4" 또는 "8" 형식의 점프 범위를 읽습니다.
+func parseJump(spec string) (int, int, error) {
+	loText, hiText, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		hiText = loText
+	}
+	lo, err1 := strconv.Atoi(loText)
+	hi, err2 := strconv.Atoi(hiText)
+	if err1 != nil || err2 != nil || lo < 0 || hi < lo || hi == 0 {
+		return 0, 0, fmt.Errorf("잘못된 점프: [%s]", spec)
+	}
+	return lo, hi, nil
+}
+
+// parseHexByte는 "4D", "4?", "?D", "??" 형식의 바이트를 값과 마스크로 변환합니다.
+func parseHexByte(hi, lo byte) (value, mask byte, err error) {
+	for _, nibble := range []struct {
+		c     byte
+		shift uint
+	}{{hi, 4}, {lo, 0}} {
+		if nibble.c == '?' {
+			continue
+		}
+		n, err := strconv.ParseUint(string(nibble.c), 16, 8)
+		if err != nil {
+			return 0, 0, fmt.Errorf("잘못된 16진수 바이트 %c%c", hi, lo)
+		}
+		value |= byte(n) << nibble.shift
+		mask |= 0x0f << nibble.shift
+	}
+	return value, mask, nil
+}
+
+// find는 data에서 패턴이 시작하는 위치를 최대 maxOffsets개까지 찾습니다.
+// nocase 패턴에는 소문자로 변환한 데이터를 전달해야 합니다.
+func (p *compiledPattern) find(data []byte) []int {
+	var offsets []int
+	search := newPatternSearch(p, data)
+	first := &p.segments[0]
+	prefix := first.literalPrefix()
+	for pos := 0; pos < len(data) && len(offsets) < maxOffsets; pos++ {
+		// 앞부분이 고정된 패턴은 그 바이트 열이 나오는 위치로 바로 이동
+		if len(prefix) > 0 {
+			i := bytes.Index(data[pos:], prefix)
+			if i < 0 {
+				break
+			}
+			pos += i
+		}
+		if search.matchAt(pos, 0) {
+			offsets = append(offsets, pos)
+		}
+	}
+	return offsets
+}
+
+// patternSearch는 데이터 하나에서 패턴의 시작 위치를 찾는 동안의 상태입니다.
+// 점프 뒤의 segment마다 이미 확인한 위치를 기억하여, 시작 위치가 겹치는 후보들이 같은 위치를 다시 확인하지 않게 합니다.
+// 시작 위치를 작은 것부터 확인하면 각 segment가 확인하는 위치도 작은 것부터 요청되므로,
+// 점프의 폭이나 개수와 관계없이 segment마다 데이터의 각 위치를 한 번씩만 확인합니다.
+type patternSearch struct {
+	p      *compiledPattern
+	data   []byte
+	levels []searchLevel // i번째 항목은 i번째 segment (0번은 사용하지 않음)
+}
+
+// searchLevel은 segment 하나에 대해 나머지 패턴이 일치하는지 확인한 결과입니다.
+// scanned 앞의 위치는 모두 확인했으며, 그중 일치한 위치는 found뿐입니다(없으면 -1).
+type searchLevel struct {
+	scanned int
+	found   int
+}
+
+func newPatternSearch(p *compiledPattern, data []byte) *patternSearch {
+	levels := make([]searchLevel, len(p.segments))
+	for i := range levels {
+		levels[i].found = -1
+	}
+	return &patternSearch{p: p, data: data, levels: levels}
+}
+
+// matchAt은 data[pos:]에서 i번째 segment부터 나머지 패턴이 일치하는지 확인합니다.
+func (s *patternSearch) matchAt(pos, i int) bool {
+	seg := &s.p.segments[i]
+	if !seg.matches(s.data, pos) {
+		return false
+	}
+	if i == len(s.p.segments)-1 {
+		return true
+	}
+	next := pos + len(seg.value)
+	return s.first(i+1, next+seg.jumpMin, next+seg.jumpMax)
+}
+
+// first는 i번째 segment부터 나머지 패턴이 일치하는 위치가 [lo, hi] 안에 있는지 확인합니다.
+// 같은 segment에 대한 호출은 lo가 줄어들지 않는 순서로 이루어져야 합니다.
+func (s *patternSearch) first(i, lo, hi int) bool {
+	level := &s.levels[i]
+	if level.found >= lo {
+		return level.found <= hi
+	}
+	hi = min(hi, len(s.data)-1)
+	for pos := max(lo, level.scanned); pos <= hi; pos++ {
+		level.scanned = pos + 1
+		if s.matchAt(pos, i) {
+			level.found = pos
+			return true
+		}
+	}
+	level.found = -1
+	return false
+}
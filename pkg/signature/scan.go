@@ -0,0 +1,116 @@
+package signature
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// StringMatch는 시그니처 문자열 하나가 발견된 위치입니다.
+// Offset은 첫 번째 위치이며, Count는 발견된 횟수입니다 (최대 1000).
+type StringMatch struct {
+	ID     string `json:"id"`
+	Offset int    `json:"offset"`
+	Count  int    `json:"count"`
+}
+
+// Match는 파일 내용이 조건을 만족한 시그니처입니다.
+type Match struct {
+	Signature   string
+	Description string
+	Strings     []StringMatch // 발견된 문자열 (ID 순)
+	Quarantine  bool          // 시그니처에 quarantine: true가 지정됨
+}
+
+// String은 일치 결과를 한 줄로 표시합니다.
+func (m Match) String() string {
+	parts := make([]string, len(m.Strings))
+	for i, s := range m.Strings {
+		parts[i] = fmt.Sprintf("%s@0x%x", s.ID, s.Offset)
+		if s.Count > 1 {
+			parts[i] += fmt.Sprintf("(%d회)", s.Count)
+		}
+	}
+	text := m.Signature
+	if m.Description != "" {
+		text += " - " + m.Description
+	}
+	if len(parts) > 0 {
+		text += " [" + strings.Join(parts, ", ") + "]"
+	}
+	return text
+}
+
+// Scan은 data를 주어진 유형에 적용되는 시그니처로 검사하여 일치한 시그니처를 반환합니다.
+// types에는 확장자(".exe")와 매직 바이트로 판별한 유형("pe")을 함께 전달할 수 있습니다.
+func (s *Set) Scan(data []byte, types ...string) []Match {
+	return s.scan(data, int64(len(data)), types)
+}
+
+// ScanFile은 파일의 앞부분 최대 maxSize바이트(0이면 전체)를 읽어 검사합니다.
+// 조건식의 filesize는 읽은 크기가 아니라 실제 파일 크기입니다.
+func (s *Set) ScanFile(path string, maxSize int64, types ...string) ([]Match, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("디렉토리입니다")
+	}
+	var r io.Reader = f
+	if maxSize > 0 {
+		r = io.LimitReader(f, maxSize)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.scan(data, info.Size(), types), nil
+}
+
+func (s *Set) scan(data []byte, size int64, types []string) []Match {
+	if s == nil {
+		return nil
+	}
+	var matches []Match
+	var lower []byte // nocase 패턴용 소문자 데이터 (필요할 때 한 번만 만듦)
+	for _, sig := range s.signatures {
+		if !sig.applies(types) {
+			continue
+		}
+		if sig.nocase && lower == nil {
+			lower = lowerASCII(data)
+		}
+
+		st := &scanState{offsets: make(map[string][]int, len(sig.ids)), size: size}
+		for _, id := range sig.ids {
+			p := sig.patterns[id]
+			target := data
+			if p.nocase {
+				target = lower
+			}
+			if offsets := p.find(target); len(offsets) > 0 {
+				st.offsets[id] = offsets
+			}
+		}
+		if !sig.condition.eval(st) {
+			continue
+		}
+
+		m := Match{Signature: sig.ID, Description: sig.Description, Quarantine: sig.Quarantine}
+		for _, id := range sig.ids {
+			if offsets := st.offsets[id]; len(offsets) > 0 {
+				m.Strings = append(m.Strings, StringMatch{ID: id, Offset: offsets[0], Count: len(offsets)})
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
@@ -0,0 +1,212 @@
+package signature
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultCondition은 condition을 지정하지 않은 시그니처의 조건입니다.
+const defaultCondition = "any of them"
+
+// stringIDPattern은 시그니처 문자열 ID 형식입니다 (예: $a, $mz_header).
+var stringIDPattern = regexp.MustCompile(`^\$[A-Za-z0-9_]+$`)
+
+// Signature는 파일 내용에서 찾을 바이트 패턴과 그 조합 조건입니다.
+//
+// 예시 (MZ 헤더와 PE 서명을 가진 파일 중 "mimikatz" 문자열이 포함된 파일):
+//
+//	signatures:
+//	  - id: mimikatz-strings
+//	    description: Mimikatz 문자열이 포함된 실행 파일
+//	    types: [pe]
+//	    strings:
+//	      $mz: {hex: "4D 5A"}
+//	      $name: {text: mimikatz, nocase: true, wide: true}
+//	      $cmd: sekurlsa::logonpasswords
+//	    condition: $mz at 0 and ($name or $cmd)
+type Signature struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	// Types를 지정하면 해당 유형의 파일만 검사합니다. "."으로 시작하면 확장자,
+	// 아니면 매직 바이트로 판별한 유형(예: pe, elf, script)입니다.
+	Types     []string           `yaml:"types"`
+	Strings   map[string]Pattern `yaml:"strings"`
+	Condition string             `yaml:"condition"` // 지정하지 않으면 "any of them"
+	// Quarantine이면 일치한 파일을 격리하도록 Match.Quarantine을 설정합니다.
+	Quarantine bool `yaml:"quarantine"`
+}
+
+// UnmarshalYAML은 스칼라 값을 Text 패턴으로 읽습니다.
+func (p *Pattern) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = Pattern{Text: node.Value}
+		return nil
+	}
+	type plain Pattern
+	var v plain
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	*p = Pattern(v)
+	return nil
+}
+
+// compiledSignature는 검사 가능한 형태로 변환된 Signature입니다.
+type compiledSignature struct {
+	Signature
+	ids       []string // 정렬된 문자열 ID
+	patterns  map[string]*compiledPattern
+	condition node
+	types     map[string]bool
+	nocase    bool // nocase 패턴 포함 여부
+}
+
+// compileSignature는 시그니처를 검증하고 변환합니다.
+func compileSignature(sig Signature) (*compiledSignature, error) {
+	if sig.ID == "" {
+		return nil, fmt.Errorf("시그니처 ID가 필요합니다")
+	}
+	if len(sig.Strings) == 0 {
+		return nil, fmt.Errorf("strings에 패턴이 하나 이상 필요합니다")
+	}
+	c := &compiledSignature{Signature: sig, patterns: make(map[string]*compiledPattern, len(sig.Strings))}
+	for id, p := range sig.Strings {
+		if !stringIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("문자열 ID는 $로 시작하는 영문자, 숫자, 밑줄이어야 합니다: %q", id)
+		}
+		compiled, err := compilePattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", id, err)
+		}
+		c.ids = append(c.ids, id)
+		c.patterns[id] = compiled
+		c.nocase = c.nocase || compiled.nocase
+	}
+	sort.Strings(c.ids)
+
+	expr := sig.Condition
+	if strings.TrimSpace(expr) == "" {
+		expr = defaultCondition
+	}
+	cond, err := parseCondition(expr, c.ids)
+	if err != nil {
+		return nil, fmt.Errorf("condition: %v", err)
+	}
+	c.condition = cond
+
+	if len(sig.Types) > 0 {
+		c.types = make(map[string]bool, len(sig.Types))
+		for _, t := range sig.Types {
+			c.types[strings.ToLower(strings.TrimSpace(t))] = true
+		}
+	}
+	return c, nil
+}
+
+// applies는 주어진 유형(확장자 또는 판별된 유형)의 파일을 이 시그니처로 검사해야 하는지 확인합니다.
+func (c *compiledSignature) applies(types []string) bool {
+	if c.types == nil {
+		return true
+	}
+	for _, t := range types {
+		if t != "" && c.types[strings.ToLower(t)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Set은 검증된 시그니처 목록입니다. 여러 고루틴에서 동시에 사용할 수 있습니다.
+type Set struct {
+	signatures []*compiledSignature
+}
+
+// signatureFile은 시그니처 파일의 최상위 구조입니다.
+type signatureFile struct {
+	Signatures []Signature `yaml:"signatures"`
+}
+
+// Parse는 YAML 시그니처 문서를 읽고 모든 시그니처를 검증합니다.
+// 알 수 없는 항목이 있거나 시그니처가 올바르지 않으면 오류를 반환합니다.
+func Parse(data []byte) (*Set, error) {
+	var file signatureFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	set := &Set{}
+	seen := make(map[string]bool)
+	var errs []string
+	for i, sig := range file.Signatures {
+		compiled, err := compileSignature(sig)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("signatures[%d] (%s): %v", i, sig.ID, err))
+			continue
+		}
+		if seen[sig.ID] {
+			errs = append(errs, fmt.Sprintf("signatures[%d]: 중복된 시그니처 ID입니다: %s", i, sig.ID))
+		}
+		seen[sig.ID] = true
+		set.signatures = append(set.signatures, compiled)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return set, nil
+}
+
+// LoadFile은 시그니처 파일을 읽어 Parse합니다.
+func LoadFile(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return set, nil
+}
+
+// Len은 시그니처 수를 반환합니다.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.signatures)
+}
+
+// Signatures는 시그니처 목록을 파일에 적힌 순서대로 반환합니다.
+func (s *Set) Signatures() []Signature {
+	if s == nil {
+		return nil
+	}
+	result := make([]Signature, len(s.signatures))
+	for i, c := range s.signatures {
+		result[i] = c.Signature
+	}
+	return result
+}
+
+// Applies는 주어진 유형의 파일에 적용되는 시그니처가 있는지 확인합니다.
+// types에는 확장자(".exe")와 매직 바이트로 판별한 유형("pe")을 함께 전달할 수 있습니다.
+func (s *Set) Applies(types ...string) bool {
+	if s == nil {
+		return false
+	}
+	for _, c := range s.signatures {
+		if c.applies(types) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package signature
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, doc string) *Set {
+	t.Helper()
+	set, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return set
+}
+
+// matchedIDs는 일치한 시그니처 ID 목록을 반환합니다.
+func matchedIDs(matches []Match) []string {
+	var ids []string
+	for _, m := range matches {
+		ids = append(ids, m.Signature)
+	}
+	return ids
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"unknown field", "signatures:\n  - id: a\n    strings: {$a: x}\n    level: 3\n", "level"},
+		{"missing id", "signatures:\n  - strings: {$a: x}\n", "ID"},
+		{"no strings", "signatures:\n  - id: a\n    condition: filesize > 0\n", "strings"},
+		{"bad string id", "signatures:\n  - id: a\n    strings: {a: x}\n", "$"},
+		{"text and hex", "signatures:\n  - id: a\n    strings: {$a: {text: x, hex: '41'}}\n", "함께"},
+		{"bad hex", "signatures:\n  - id: a\n    strings: {$a: {hex: '4G'}}\n", "4G"},
+		{"odd hex", "signatures:\n  - id: a\n    strings: {$a: {hex: '41 4'}}\n", "두 자리"},
+		{"trailing jump", "signatures:\n  - id: a\n    strings: {$a: {hex: '41 [2]'}}\n", "점프"},
+		{"undefined string", "signatures:\n  - id: a\n    strings: {$a: x}\n    condition: $a and $b\n", "$b"},
+		{"bad condition", "signatures:\n  - id: a\n    strings: {$a: x}\n    condition: $a and\n", "끝나지"},
+		{"too many of", "signatures:\n  - id: a\n    strings: {$a: x}\n    condition: 2 of them\n", "2 of"},
+		{"duplicate", "signatures:\n  - id: a\n    strings: {$a: x}\n  - id: a\n    strings: {$a: y}\n", "중복"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	set := mustParse(t, `
+signatures:
+  - id: text
+    strings: {$a: "Hello"}
+  - id: nocase
+    strings: {$a: {text: "hello", nocase: true}}
+  - id: wide
+    strings: {$a: {text: "Hi", wide: true}}
+  - id: hex-wildcard
+    strings: {$a: {hex: "4D 5A ?? 00 5?"}}
+  - id: hex-jump
+    strings: {$a: {hex: "DE AD [1-3] BE EF"}}
+`)
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{"say Hello", []string{"text", "nocase"}},
+		{"say HELLO", []string{"nocase"}},
+		{"H\x00i\x00", []string{"wide"}},
+		{"MZ\x90\x00\x51", []string{"hex-wildcard"}},
+		{"MZ\x90\x01\x51", nil},
+		{"MZ\x90\x00\x61", nil},
+		{"\xde\xad\x01\x02\xbe\xef", []string{"hex-jump"}},
+		{"\xde\xad\xbe\xef", nil},
+		{"\xde\xad\x01\x02\x03\x04\xbe\xef", nil},
+		// UTF-8이 아닌 바이트가 앞에 있어도 nocase 위치가 어긋나지 않음
+		{"\xff\xfeHELLO", []string{"nocase"}},
+	}
+	for _, tt := range tests {
+		if got := matchedIDs(set.Scan([]byte(tt.data))); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Scan(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestJumpOffsets(t *testing.T) {
+	p, err := compilePattern(Pattern{Hex: "41 [1-3] 42 [0-2] 43"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 시작 위치가 겹치는 일치와 짧은 점프로는 닿지 않는 후보를 모두 확인
+	data := []byte("AAxB.CAxxxxBC")
+	if got, want := p.find(data), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("find(%q) = %v, want %v", data, got, want)
+	}
+}
+
+func TestJumpWorstCase(t *testing.T) {
+	p, err := compilePattern(Pattern{Hex: "41 [0-4096] 41 [0-4096] 42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 점프마다 모든 폭을 다시 시도하면 위치마다 4096²번을 확인하게 되는 입력
+	data := bytes.Repeat([]byte("A"), 1<<20)
+	done := make(chan []int, 1)
+	go func() { done <- p.find(data) }()
+	select {
+	case offsets := <-done:
+		if len(offsets) != 0 {
+			t.Errorf("Expected no match, got %d offsets", len(offsets))
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("find did not finish in time")
+	}
+
+	// 끝에 B가 있으면 닿을 수 있는 시작 위치가 모두 일치
+	data[len(data)-1] = 'B'
+	if offsets := p.find(data); len(offsets) != maxOffsets || offsets[0] != len(data)-8195 {
+		t.Errorf("Expected %d offsets starting at %d, got %d starting at %v", maxOffsets, len(data)-8195, len(offsets), offsets[:1])
+	}
+}
+
+func TestConditions(t *testing.T) {
+	set := mustParse(t, `
+signatures:
+  - id: header
+    strings: {$mz: {hex: "4D 5A"}, $pe: "PE"}
+    condition: $mz at 0 and $pe
+  - id: counted
+    strings: {$x: "x"}
+    condition: "#x >= 3 and filesize < 1KB"
+  - id: two-of
+    strings: {$a1: "aa", $a2: "bb", $b: "cc"}
+    condition: 2 of ($a*) and not $b
+`)
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{"MZ..PE", []string{"header"}},
+		{".MZ.PE", nil},
+		{"xx", nil},
+		{"xxx", []string{"counted"}},
+		{"aa bb", []string{"two-of"}},
+		{"aa bb cc", nil},
+		{"aa cc", nil},
+	}
+	for _, tt := range tests {
+		if got := matchedIDs(set.Scan([]byte(tt.data))); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Scan(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestScanFileTypes(t *testing.T) {
+	set := mustParse(t, `
+signatures:
+  - id: pe-only
+    types: [pe, .DLL]
+    strings: {$a: "evil"}
+  - id: any-file
+    description: 모든 파일
+    strings: {$a: "evil"}
+`)
+	if !set.Applies(".txt") || set.Len() != 2 {
+		t.Errorf("Expected untyped signature to apply to every file")
+	}
+
+	path := filepath.Join(t.TempDir(), "sample.bin")
+	data := append([]byte("MZ"), make([]byte, 100)...)
+	data = append(data, "evil evil"...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := set.ScanFile(path, 0, ".bin", "pe")
+	if err != nil {
+		t.Fatalf("ScanFile failed: %v", err)
+	}
+	if got := matchedIDs(matches); !reflect.DeepEqual(got, []string{"pe-only", "any-file"}) {
+		t.Fatalf("Unexpected matches: %v", got)
+	}
+	want := []StringMatch{{ID: "$a", Offset: 102, Count: 2}}
+	if !reflect.DeepEqual(matches[0].Strings, want) {
+		t.Errorf("Unexpected string matches: %+v", matches[0].Strings)
+	}
+
+	if got := matchedIDs(set.Scan(data, ".txt")); !reflect.DeepEqual(got, []string{"any-file"}) {
+		t.Errorf("Expected type filter to skip pe-only, got %v", got)
+	}
+
+	// 검사 크기 제한보다 뒤에 있는 문자열은 찾지 않음
+	if matches, err := set.ScanFile(path, 64, "pe"); err != nil || len(matches) != 0 {
+		t.Errorf("Expected no match within size limit, got %v, %v", matches, err)
+	}
+}
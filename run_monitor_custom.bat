@@ -4,14 +4,19 @@ echo Copyright © 2023
 echo.
 
 REM 사용자 정의 설정으로 실행
+REM 장치, 필터, 간격 등은 iomonitor.yaml에서 설정합니다 (iomonitor.example.yaml 참고)
 echo 사용자 정의 설정으로 실행 중...
-echo 모니터링 간격: 10초
-echo 모니터링 대상: C:\ 및 D:\
-echo 파일 필터: .exe, .dll, .sys
+echo 설정 파일: iomonitor.yaml
 echo 종료하려면 Ctrl+C를 누르세요.
 echo.
 
-REM 실행 파일 실행 (사용자 정의 옵션)
-iomonitor.exe -interval 10s -device "C:\,D:\" -filters ".exe,.dll,.sys" -db "monitor.db"
+if not exist iomonitor.yaml copy iomonitor.example.yaml iomonitor.yaml >nul
 
-pause 
\ No newline at end of file
+REM 설정 파일 검증 후 실행
+iomonitor.exe config validate iomonitor.yaml
+if errorlevel 1 goto end
+
+iomonitor.exe -config iomonitor.yaml
+
+:end
+pause